@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/jonmartinstorm/reposnusern/internal/bqwriter"
+	"github.com/jonmartinstorm/reposnusern/internal/catalog"
 	"github.com/jonmartinstorm/reposnusern/internal/config"
 	"github.com/jonmartinstorm/reposnusern/internal/dbwriter"
 	"github.com/jonmartinstorm/reposnusern/internal/fetcher"
@@ -30,6 +31,16 @@ func main() {
 		slog.Info("Inkluderer arkiverte repositories")
 	}
 
+	if cfg.ImageCatalogPath != "" {
+		cat, err := catalog.Load(cfg.ImageCatalogPath)
+		if err != nil {
+			slog.Error("Kunne ikke laste base image-katalog", "error", err)
+			os.Exit(1)
+		}
+		catalog.SetDefault(cat)
+		slog.Info("Bruker base image-katalog fra fil", "path", cfg.ImageCatalogPath)
+	}
+
 	slog.Info("Starter reposnusern...", "org", cfg.Org)
 
 	var writer runner.DBWriter
@@ -0,0 +1,15 @@
+-- name: InsertOrUpdateBaseImageDigest :exec
+INSERT INTO base_image_digests (
+  repo_id, hentet_dato, reference, registry,
+  pinned_digest, current_digest, image_created, digest_differs, error
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8, $9
+)
+ON CONFLICT (repo_id, hentet_dato, reference) DO UPDATE SET
+  registry = EXCLUDED.registry,
+  pinned_digest = EXCLUDED.pinned_digest,
+  current_digest = EXCLUDED.current_digest,
+  image_created = EXCLUDED.image_created,
+  digest_differs = EXCLUDED.digest_differs,
+  error = EXCLUDED.error;
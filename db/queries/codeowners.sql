@@ -0,0 +1,40 @@
+-- name: InsertOrUpdateCodeownersRule :exec
+INSERT INTO codeowners_rules (
+  repo_id, hentet_dato, path, line,
+  pattern, owners, unknown_owners, error
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8
+)
+ON CONFLICT (repo_id, hentet_dato, path, line) DO UPDATE SET
+  pattern = EXCLUDED.pattern,
+  owners = EXCLUDED.owners,
+  unknown_owners = EXCLUDED.unknown_owners,
+  error = EXCLUDED.error;
+
+-- name: InsertOrUpdateCodeownersPath :exec
+INSERT INTO codeowners_paths (
+  repo_id, hentet_dato, path, is_dir,
+  owners, rule_line
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6
+)
+ON CONFLICT (repo_id, hentet_dato, path) DO UPDATE SET
+  is_dir = EXCLUDED.is_dir,
+  owners = EXCLUDED.owners,
+  rule_line = EXCLUDED.rule_line;
+
+-- name: InsertOrUpdateCodeownersCoverage :exec
+INSERT INTO codeowners_coverage (
+  repo_id, hentet_dato, codeowners_path, rule_count,
+  invalid_rule_count, owned_percent
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6
+)
+ON CONFLICT (repo_id, hentet_dato) DO UPDATE SET
+  codeowners_path = EXCLUDED.codeowners_path,
+  rule_count = EXCLUDED.rule_count,
+  invalid_rule_count = EXCLUDED.invalid_rule_count,
+  owned_percent = EXCLUDED.owned_percent;
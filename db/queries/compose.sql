@@ -0,0 +1,36 @@
+-- name: InsertOrUpdateComposeService :exec
+INSERT INTO compose_services (
+  repo_id, hentet_dato, path, service,
+  image, image_tag, raw_image, registry, is_digest_pinned, digest,
+  build_context, dockerfile, ports, privileged, network_mode, env_files
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8, $9, $10,
+  $11, $12, $13, $14, $15, $16
+)
+ON CONFLICT (repo_id, hentet_dato, path, service) DO UPDATE SET
+  image = EXCLUDED.image,
+  image_tag = EXCLUDED.image_tag,
+  raw_image = EXCLUDED.raw_image,
+  registry = EXCLUDED.registry,
+  is_digest_pinned = EXCLUDED.is_digest_pinned,
+  digest = EXCLUDED.digest,
+  build_context = EXCLUDED.build_context,
+  dockerfile = EXCLUDED.dockerfile,
+  ports = EXCLUDED.ports,
+  privileged = EXCLUDED.privileged,
+  network_mode = EXCLUDED.network_mode,
+  env_files = EXCLUDED.env_files;
+
+-- name: InsertOrUpdateComposeVolume :exec
+INSERT INTO compose_volumes (
+  repo_id, hentet_dato, path, service,
+  mount_type, source, target, read_only
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8
+)
+ON CONFLICT (repo_id, hentet_dato, path, service, target) DO UPDATE SET
+  mount_type = EXCLUDED.mount_type,
+  source = EXCLUDED.source,
+  read_only = EXCLUDED.read_only;
@@ -0,0 +1,30 @@
+-- name: InsertOrUpdateDeployManifest :exec
+INSERT INTO deploy_manifests (
+  repo_id, hentet_dato, path, kind, name,
+  namespace, images, replicas_min, replicas_max,
+  cpu_request, cpu_limit, memory_request, memory_limit,
+  ingress_hosts, inbound_access, outbound_access,
+  liveness_probe, readiness_probe, run_as_non_root, read_only_root_filesystem
+) VALUES (
+  $1, $2, $3, $4, $5,
+  $6, $7, $8, $9,
+  $10, $11, $12, $13,
+  $14, $15, $16,
+  $17, $18, $19, $20
+)
+ON CONFLICT (repo_id, hentet_dato, path, kind, name) DO UPDATE SET
+  namespace = EXCLUDED.namespace,
+  images = EXCLUDED.images,
+  replicas_min = EXCLUDED.replicas_min,
+  replicas_max = EXCLUDED.replicas_max,
+  cpu_request = EXCLUDED.cpu_request,
+  cpu_limit = EXCLUDED.cpu_limit,
+  memory_request = EXCLUDED.memory_request,
+  memory_limit = EXCLUDED.memory_limit,
+  ingress_hosts = EXCLUDED.ingress_hosts,
+  inbound_access = EXCLUDED.inbound_access,
+  outbound_access = EXCLUDED.outbound_access,
+  liveness_probe = EXCLUDED.liveness_probe,
+  readiness_probe = EXCLUDED.readiness_probe,
+  run_as_non_root = EXCLUDED.run_as_non_root,
+  read_only_root_filesystem = EXCLUDED.read_only_root_filesystem;
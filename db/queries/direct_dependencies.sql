@@ -0,0 +1,10 @@
+-- name: InsertOrUpdateDirectDependency :exec
+INSERT INTO direct_dependencies (
+  repo_id, hentet_dato, ecosystem, name,
+  version_constraint, scope, kind, source_path
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8
+)
+ON CONFLICT (repo_id, hentet_dato, source_path, ecosystem, name, scope, kind) DO UPDATE SET
+  version_constraint = EXCLUDED.version_constraint;
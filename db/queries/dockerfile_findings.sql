@@ -0,0 +1,12 @@
+-- name: InsertOrUpdateDockerfileFinding :exec
+INSERT INTO dockerfile_findings (
+  repo_id, hentet_dato, path, rule_id,
+  severity, description, start_line, end_line
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8
+)
+ON CONFLICT (repo_id, hentet_dato, path, rule_id, start_line) DO UPDATE SET
+  severity = EXCLUDED.severity,
+  description = EXCLUDED.description,
+  end_line = EXCLUDED.end_line;
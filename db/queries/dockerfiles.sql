@@ -1,27 +1,32 @@
 -- name: InsertOrUpdateDockerfile :one
 INSERT INTO dockerfiles (
   repo_id, hentet_dato, full_name, path, content,
-  base_image, base_tag, uses_latest_tag,
+  base_image, base_tag, raw_image, registry, is_digest_pinned, uses_latest_tag,
   has_user_instruction, has_copy_sensitive, has_package_installs,
   uses_multistage, has_healthcheck, uses_add_instruction,
   has_label_metadata, has_expose, has_entrypoint_or_cmd,
   installs_curl_or_wget, installs_build_tools, has_apt_get_clean,
-  world_writable, has_secrets_in_env_or_arg
+  world_writable, has_secrets_in_env_or_arg,
+  final_stage_index, final_runs_as_root, final_installs_build_tools
 )
 VALUES (
   $1, $2, $3, $4, $5,
-  $6, $7, $8,
-  $9, $10, $11,
+  $6, $7, $8, $9, $10, $11,
   $12, $13, $14,
   $15, $16, $17,
   $18, $19, $20,
-  $21, $22
+  $21, $22, $23,
+  $24, $25,
+  $26, $27, $28
 )
 ON CONFLICT (repo_id, hentet_dato, path) DO UPDATE SET
   full_name = EXCLUDED.full_name,
   content = EXCLUDED.content,
   base_image = EXCLUDED.base_image,
   base_tag = EXCLUDED.base_tag,
+  raw_image = EXCLUDED.raw_image,
+  registry = EXCLUDED.registry,
+  is_digest_pinned = EXCLUDED.is_digest_pinned,
   uses_latest_tag = EXCLUDED.uses_latest_tag,
   has_user_instruction = EXCLUDED.has_user_instruction,
   has_copy_sensitive = EXCLUDED.has_copy_sensitive,
@@ -36,5 +41,47 @@ ON CONFLICT (repo_id, hentet_dato, path) DO UPDATE SET
   installs_build_tools = EXCLUDED.installs_build_tools,
   has_apt_get_clean = EXCLUDED.has_apt_get_clean,
   world_writable = EXCLUDED.world_writable,
-  has_secrets_in_env_or_arg = EXCLUDED.has_secrets_in_env_or_arg
+  has_secrets_in_env_or_arg = EXCLUDED.has_secrets_in_env_or_arg,
+  final_stage_index = EXCLUDED.final_stage_index,
+  final_runs_as_root = EXCLUDED.final_runs_as_root,
+  final_installs_build_tools = EXCLUDED.final_installs_build_tools
 RETURNING id;
+
+-- name: InsertOrUpdateDockerfileStage :exec
+INSERT INTO dockerfile_stages (
+  repo_id, hentet_dato, path, stage_index,
+  base_image, base_tag, raw_image, registry, is_digest_pinned,
+  alias, base_stage, user_name, runs_as_root,
+  has_package_installs, installs_build_tools, installs_curl_or_wget,
+  copy_from, exposed_ports, entrypoint, cmd, is_final,
+  image_family, image_version, eol_date, eol_status
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8, $9,
+  $10, $11, $12, $13,
+  $14, $15, $16,
+  $17, $18, $19, $20, $21,
+  $22, $23, $24, $25
+)
+ON CONFLICT (repo_id, hentet_dato, path, stage_index) DO UPDATE SET
+  base_image = EXCLUDED.base_image,
+  base_tag = EXCLUDED.base_tag,
+  raw_image = EXCLUDED.raw_image,
+  registry = EXCLUDED.registry,
+  is_digest_pinned = EXCLUDED.is_digest_pinned,
+  alias = EXCLUDED.alias,
+  base_stage = EXCLUDED.base_stage,
+  user_name = EXCLUDED.user_name,
+  runs_as_root = EXCLUDED.runs_as_root,
+  has_package_installs = EXCLUDED.has_package_installs,
+  installs_build_tools = EXCLUDED.installs_build_tools,
+  installs_curl_or_wget = EXCLUDED.installs_curl_or_wget,
+  copy_from = EXCLUDED.copy_from,
+  exposed_ports = EXCLUDED.exposed_ports,
+  entrypoint = EXCLUDED.entrypoint,
+  cmd = EXCLUDED.cmd,
+  is_final = EXCLUDED.is_final,
+  image_family = EXCLUDED.image_family,
+  image_version = EXCLUDED.image_version,
+  eol_date = EXCLUDED.eol_date,
+  eol_status = EXCLUDED.eol_status;
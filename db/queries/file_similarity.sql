@@ -0,0 +1,30 @@
+-- name: InsertOrUpdateFileFingerprint :exec
+INSERT INTO file_fingerprints (
+  repo_id, hentet_dato, path, file_type, content_hash, simhash, line_count
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (repo_id, hentet_dato, path) DO UPDATE SET
+  file_type = EXCLUDED.file_type,
+  content_hash = EXCLUDED.content_hash,
+  simhash = EXCLUDED.simhash,
+  line_count = EXCLUDED.line_count;
+
+-- name: InsertOrUpdateFileLineage :exec
+INSERT INTO file_lineage (
+  repo_id, hentet_dato, path, file_type, content_hash, cluster_id,
+  cluster_size, cluster_repos, variant_count, is_canonical, simhash_distance, divergence
+) VALUES (
+  $1, $2, $3, $4, $5, $6,
+  $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (repo_id, hentet_dato, path) DO UPDATE SET
+  file_type = EXCLUDED.file_type,
+  content_hash = EXCLUDED.content_hash,
+  cluster_id = EXCLUDED.cluster_id,
+  cluster_size = EXCLUDED.cluster_size,
+  cluster_repos = EXCLUDED.cluster_repos,
+  variant_count = EXCLUDED.variant_count,
+  is_canonical = EXCLUDED.is_canonical,
+  simhash_distance = EXCLUDED.simhash_distance,
+  divergence = EXCLUDED.divergence;
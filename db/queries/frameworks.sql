@@ -0,0 +1,13 @@
+-- name: InsertOrUpdateRepoFramework :exec
+INSERT INTO repo_frameworks (
+  repo_id, hentet_dato, framework, kind,
+  version, confidence, evidence
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7
+)
+ON CONFLICT (repo_id, hentet_dato, framework) DO UPDATE SET
+  kind = EXCLUDED.kind,
+  version = EXCLUDED.version,
+  confidence = EXCLUDED.confidence,
+  evidence = EXCLUDED.evidence;
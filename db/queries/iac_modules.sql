@@ -0,0 +1,14 @@
+-- name: InsertOrUpdateIaCModule :exec
+INSERT INTO iac_modules (
+  repo_id, hentet_dato, path, tool, kind, name,
+  source, source_type, version, pinned
+) VALUES (
+  $1, $2, $3, $4, $5, $6,
+  $7, $8, $9, $10
+)
+ON CONFLICT (repo_id, hentet_dato, path, kind, name) DO UPDATE SET
+  tool = EXCLUDED.tool,
+  source = EXCLUDED.source,
+  source_type = EXCLUDED.source_type,
+  version = EXCLUDED.version,
+  pinned = EXCLUDED.pinned;
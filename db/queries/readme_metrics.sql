@@ -0,0 +1,29 @@
+-- name: InsertOrUpdateReadmeMetrics :exec
+INSERT INTO readme_metrics (
+  repo_id, hentet_dato, length, word_count,
+  heading_count, headings, has_installation, has_usage, has_contact, has_ownership,
+  badge_count, badges, internal_links, external_links, code_blocks,
+  language, is_template, template_markers
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8, $9, $10,
+  $11, $12, $13, $14, $15,
+  $16, $17, $18
+)
+ON CONFLICT (repo_id, hentet_dato) DO UPDATE SET
+  length = EXCLUDED.length,
+  word_count = EXCLUDED.word_count,
+  heading_count = EXCLUDED.heading_count,
+  headings = EXCLUDED.headings,
+  has_installation = EXCLUDED.has_installation,
+  has_usage = EXCLUDED.has_usage,
+  has_contact = EXCLUDED.has_contact,
+  has_ownership = EXCLUDED.has_ownership,
+  badge_count = EXCLUDED.badge_count,
+  badges = EXCLUDED.badges,
+  internal_links = EXCLUDED.internal_links,
+  external_links = EXCLUDED.external_links,
+  code_blocks = EXCLUDED.code_blocks,
+  language = EXCLUDED.language,
+  is_template = EXCLUDED.is_template,
+  template_markers = EXCLUDED.template_markers;
@@ -1,8 +1,10 @@
 -- name: InsertOrUpdateRepoLanguage :exec
 INSERT INTO repo_languages (
-  repo_id, hentet_dato, language, bytes
+  repo_id, hentet_dato, language, bytes, share, language_type
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6
 )
 ON CONFLICT (repo_id, hentet_dato, language) DO UPDATE SET
-  bytes = EXCLUDED.bytes;
+  bytes = EXCLUDED.bytes,
+  share = EXCLUDED.share,
+  language_type = EXCLUDED.language_type;
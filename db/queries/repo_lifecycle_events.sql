@@ -0,0 +1,25 @@
+-- name: InsertOrUpdateLifecycleEvent :exec
+INSERT INTO repo_lifecycle_events (
+  repo_id, hentet_dato, event_type, old_full_name, new_full_name
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (repo_id, hentet_dato, event_type) DO UPDATE SET
+  old_full_name = EXCLUDED.old_full_name,
+  new_full_name = EXCLUDED.new_full_name;
+
+-- name: InsertOrUpdateSkippedArchivedRepo :exec
+INSERT INTO skipped_archived_repos (
+  repo_id, hentet_dato, full_name
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (repo_id, hentet_dato) DO UPDATE SET
+  full_name = EXCLUDED.full_name;
+
+-- name: ListSkippedArchivedReposInPreviousSnapshot :many
+SELECT repo_id, full_name
+FROM skipped_archived_repos
+WHERE hentet_dato = (
+  SELECT MAX(hentet_dato) FROM repos WHERE hentet_dato < $1
+);
@@ -0,0 +1,10 @@
+-- name: InsertOrUpdateRuntimeVersion :exec
+INSERT INTO runtime_versions (
+  repo_id, hentet_dato, runtime, version,
+  raw_value, source_path, setting
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7
+)
+ON CONFLICT (repo_id, hentet_dato, runtime, source_path, setting, raw_value) DO UPDATE SET
+  version = EXCLUDED.version;
@@ -1,9 +1,10 @@
 -- name: InsertOrUpdateGithubSBOM :exec
 INSERT INTO sbom_github_packages (
-  repo_id, hentet_dato, name, version, license, purl
+  repo_id, hentet_dato, name, version, license, purl, source
 ) VALUES (
-  $1, $2, $3, $4, $5, $6
+  $1, $2, $3, $4, $5, $6, $7
 )
 ON CONFLICT (repo_id, hentet_dato, name, version) DO UPDATE SET
   license = EXCLUDED.license,
-  purl = EXCLUDED.purl;
\ No newline at end of file
+  purl = EXCLUDED.purl,
+  source = EXCLUDED.source;
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- name: InsertOrUpdateSecretFinding :exec
+INSERT INTO secret_findings (
+  repo_id, hentet_dato, path, rule_id,
+  description, line, secret_hash
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7
+)
+ON CONFLICT (repo_id, hentet_dato, path, rule_id, line, secret_hash) DO UPDATE SET
+  description = EXCLUDED.description;
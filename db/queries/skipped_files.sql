@@ -0,0 +1,9 @@
+-- name: InsertOrUpdateSkippedFile :exec
+INSERT INTO skipped_files (
+  repo_id, hentet_dato, path, reason, byte_size
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (repo_id, hentet_dato, path) DO UPDATE SET
+  reason = EXCLUDED.reason,
+  byte_size = EXCLUDED.byte_size;
@@ -0,0 +1,27 @@
+-- name: InsertOrUpdateUpdateBotConfig :exec
+INSERT INTO update_bot_configs (
+  repo_id, hentet_dato, tool, path,
+  ecosystem, directory, schedule, groups,
+  auto_merge
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8,
+  $9
+)
+ON CONFLICT (repo_id, hentet_dato, tool, path, ecosystem, directory) DO UPDATE SET
+  schedule = EXCLUDED.schedule,
+  groups = EXCLUDED.groups,
+  auto_merge = EXCLUDED.auto_merge;
+
+-- name: InsertOrUpdateEcosystemCoverage :exec
+INSERT INTO ecosystem_coverage (
+  repo_id, hentet_dato, ecosystem, manifests,
+  covered_by, is_covered
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6
+)
+ON CONFLICT (repo_id, hentet_dato, ecosystem) DO UPDATE SET
+  manifests = EXCLUDED.manifests,
+  covered_by = EXCLUDED.covered_by,
+  is_covered = EXCLUDED.is_covered;
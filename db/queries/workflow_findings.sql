@@ -0,0 +1,11 @@
+-- name: InsertOrUpdateWorkflowFinding :exec
+INSERT INTO workflow_findings (
+  repo_id, hentet_dato, path, rule_id,
+  severity, description, job_id, line
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8
+)
+ON CONFLICT (repo_id, hentet_dato, path, rule_id, job_id, line) DO UPDATE SET
+  severity = EXCLUDED.severity,
+  description = EXCLUDED.description;
@@ -0,0 +1,49 @@
+-- name: InsertOrUpdateWorkflow :exec
+INSERT INTO workflows (
+  repo_id, hentet_dato, path, name,
+  triggers, permissions, secrets
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7
+)
+ON CONFLICT (repo_id, hentet_dato, path) DO UPDATE SET
+  name = EXCLUDED.name,
+  triggers = EXCLUDED.triggers,
+  permissions = EXCLUDED.permissions,
+  secrets = EXCLUDED.secrets;
+
+-- name: InsertOrUpdateWorkflowJob :exec
+INSERT INTO workflow_jobs (
+  repo_id, hentet_dato, path, job_id,
+  name, line, runs_on, permissions,
+  uses, secrets_inherit
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8,
+  $9, $10
+)
+ON CONFLICT (repo_id, hentet_dato, path, job_id) DO UPDATE SET
+  name = EXCLUDED.name,
+  line = EXCLUDED.line,
+  runs_on = EXCLUDED.runs_on,
+  permissions = EXCLUDED.permissions,
+  uses = EXCLUDED.uses,
+  secrets_inherit = EXCLUDED.secrets_inherit;
+
+-- name: InsertOrUpdateWorkflowAction :exec
+INSERT INTO workflow_actions (
+  repo_id, hentet_dato, path, job_id,
+  step_index, uses, kind, action,
+  ref, ref_type, line
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8,
+  $9, $10, $11
+)
+ON CONFLICT (repo_id, hentet_dato, path, job_id, step_index) DO UPDATE SET
+  uses = EXCLUDED.uses,
+  kind = EXCLUDED.kind,
+  action = EXCLUDED.action,
+  ref = EXCLUDED.ref,
+  ref_type = EXCLUDED.ref_type,
+  line = EXCLUDED.line;
@@ -28,9 +28,24 @@ CREATE TABLE IF NOT EXISTS repos (
     has_dependabot BOOLEAN NOT NULL DEFAULT FALSE,
     has_codeql BOOLEAN NOT NULL DEFAULT FALSE,
 
+    -- opphav: upstream for forks og mal for template-repos
+    parent_full_name TEXT,
+    parent_in_org BOOLEAN,
+    template_full_name TEXT,
+    template_in_org BOOLEAN,
+    fork_ahead_by INTEGER,
+    fork_behind_by INTEGER,
+
     PRIMARY KEY (id, hentet_dato)
 );
 
+ALTER TABLE repos ADD COLUMN IF NOT EXISTS parent_full_name TEXT;
+ALTER TABLE repos ADD COLUMN IF NOT EXISTS parent_in_org BOOLEAN;
+ALTER TABLE repos ADD COLUMN IF NOT EXISTS template_full_name TEXT;
+ALTER TABLE repos ADD COLUMN IF NOT EXISTS template_in_org BOOLEAN;
+ALTER TABLE repos ADD COLUMN IF NOT EXISTS fork_ahead_by INTEGER;
+ALTER TABLE repos ADD COLUMN IF NOT EXISTS fork_behind_by INTEGER;
+
 CREATE TABLE IF NOT EXISTS dockerfiles (
     id SERIAL PRIMARY KEY,
     repo_id BIGINT NOT NULL,
@@ -42,6 +57,9 @@ CREATE TABLE IF NOT EXISTS dockerfiles (
     -- Features
     base_image TEXT,
     base_tag TEXT,
+    raw_image TEXT, -- FROM-uttrykket når base_image er 'unresolved'
+    registry TEXT,
+    is_digest_pinned BOOLEAN,
     uses_latest_tag BOOLEAN,
     has_user_instruction BOOLEAN,
     has_copy_sensitive BOOLEAN,
@@ -58,9 +76,87 @@ CREATE TABLE IF NOT EXISTS dockerfiles (
     world_writable BOOLEAN,
     has_secrets_in_env_or_arg BOOLEAN,
 
+    -- Siste stage, imaget som faktisk kjøres
+    final_stage_index INTEGER,
+    final_runs_as_root BOOLEAN,
+    final_installs_build_tools BOOLEAN,
+
     UNIQUE (repo_id, hentet_dato, path)
 );
 
+ALTER TABLE dockerfiles ADD COLUMN IF NOT EXISTS registry TEXT;
+ALTER TABLE dockerfiles ADD COLUMN IF NOT EXISTS is_digest_pinned BOOLEAN;
+ALTER TABLE dockerfiles ADD COLUMN IF NOT EXISTS raw_image TEXT;
+ALTER TABLE dockerfiles ADD COLUMN IF NOT EXISTS final_stage_index INTEGER;
+ALTER TABLE dockerfiles ADD COLUMN IF NOT EXISTS final_runs_as_root BOOLEAN;
+ALTER TABLE dockerfiles ADD COLUMN IF NOT EXISTS final_installs_build_tools BOOLEAN;
+
+CREATE TABLE IF NOT EXISTS dockerfile_stages (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,
+
+    stage_index INTEGER NOT NULL,
+    base_image TEXT NOT NULL,
+    base_tag TEXT NOT NULL,
+    raw_image TEXT,
+    registry TEXT NOT NULL,
+    is_digest_pinned BOOLEAN NOT NULL,
+    alias TEXT,
+    base_stage TEXT, -- alias for tidligere stage når FROM bygger videre på den
+
+    user_name TEXT,
+    runs_as_root BOOLEAN, -- NULL når base image bestemmer brukeren og ikke finnes i katalogen
+    has_package_installs BOOLEAN NOT NULL,
+    installs_build_tools BOOLEAN NOT NULL,
+    installs_curl_or_wget BOOLEAN NOT NULL,
+    copy_from TEXT NOT NULL, -- kommaseparert
+    exposed_ports TEXT NOT NULL, -- kommaseparert
+    entrypoint TEXT,
+    cmd TEXT,
+    is_final BOOLEAN NOT NULL,
+
+    -- Klassifisering fra base image-katalogen
+    image_family TEXT,
+    image_version TEXT,
+    eol_date DATE,
+    eol_status TEXT NOT NULL DEFAULT 'unknown', -- supported, near_eol, eol, unknown
+
+    UNIQUE (repo_id, hentet_dato, path, stage_index)
+);
+
+CREATE TABLE IF NOT EXISTS dockerfile_findings (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,
+
+    rule_id TEXT NOT NULL,
+    severity TEXT NOT NULL, -- info, warning, error
+    description TEXT NOT NULL,
+    start_line INTEGER NOT NULL,
+    end_line INTEGER NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato, path, rule_id, start_line)
+);
+
+CREATE TABLE IF NOT EXISTS base_image_digests (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    reference TEXT NOT NULL, -- image:tag@digest slik det står i FROM
+    registry TEXT NOT NULL,
+    pinned_digest TEXT,
+    current_digest TEXT,
+    image_created TIMESTAMP,
+    digest_differs BOOLEAN NOT NULL,
+    error TEXT,
+
+    UNIQUE (repo_id, hentet_dato, reference)
+);
+
 CREATE TABLE IF NOT EXISTS repo_languages (
     id SERIAL PRIMARY KEY,
     repo_id BIGINT NOT NULL,
@@ -68,10 +164,15 @@ CREATE TABLE IF NOT EXISTS repo_languages (
 
     language TEXT NOT NULL,
     bytes BIGINT NOT NULL,
+    share REAL NOT NULL DEFAULT 0, -- andel av repoets totale bytes (0–1)
+    language_type TEXT NOT NULL DEFAULT 'programming', -- programming, markup, data eller config
 
     UNIQUE (repo_id, hentet_dato, language)
 );
 
+ALTER TABLE repo_languages ADD COLUMN IF NOT EXISTS share REAL NOT NULL DEFAULT 0;
+ALTER TABLE repo_languages ADD COLUMN IF NOT EXISTS language_type TEXT NOT NULL DEFAULT 'programming';
+
 CREATE TABLE IF NOT EXISTS ci_configs (
     id SERIAL PRIMARY KEY,
     repo_id BIGINT NOT NULL,
@@ -83,6 +184,342 @@ CREATE TABLE IF NOT EXISTS ci_configs (
     UNIQUE (repo_id, hentet_dato, path)
 );
 
+-- GitHub Actions-workflows fra ci_configs, parset ut i egne tabeller
+CREATE TABLE IF NOT EXISTS workflows (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,
+
+    name TEXT,
+    triggers TEXT NOT NULL,     -- kommaseparert, f.eks. push,pull_request
+    permissions TEXT,           -- NULL når workflowen ikke setter permissions
+    secrets TEXT NOT NULL,      -- kommaseparerte navn på refererte secrets
+
+    UNIQUE (repo_id, hentet_dato, path)
+);
+
+CREATE TABLE IF NOT EXISTS workflow_jobs (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,
+    job_id TEXT NOT NULL,
+
+    name TEXT,
+    line INTEGER NOT NULL,
+    runs_on TEXT NOT NULL,      -- kommaseparerte labels
+    permissions TEXT,
+    uses TEXT,                  -- gjenbrukbar workflow jobben kaller
+    secrets_inherit BOOLEAN NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato, path, job_id)
+);
+
+CREATE TABLE IF NOT EXISTS workflow_actions (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,
+    job_id TEXT NOT NULL,
+    step_index INTEGER NOT NULL, -- -1 for gjenbrukbar workflow på jobbnivå
+
+    uses TEXT NOT NULL,          -- slik det står i filen
+    kind TEXT NOT NULL,          -- action, reusable, docker, local
+    action TEXT NOT NULL,        -- owner/repo[/path], f.eks. actions/checkout
+    ref TEXT,
+    ref_type TEXT,               -- sha, tag, branch
+    line INTEGER NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato, path, job_id, step_index)
+);
+
+CREATE TABLE IF NOT EXISTS workflow_findings (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,
+
+    rule_id TEXT NOT NULL,
+    severity TEXT NOT NULL, -- info, warning, error
+    description TEXT NOT NULL,
+    job_id TEXT NOT NULL,   -- tom for funn på workflow-nivå
+    line INTEGER NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato, path, rule_id, job_id, line)
+);
+
+-- Dependabot- og Renovate-konfig, én rad per økosystem og katalog
+CREATE TABLE IF NOT EXISTS update_bot_configs (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    tool TEXT NOT NULL,         -- dependabot eller renovate
+    path TEXT NOT NULL,
+    ecosystem TEXT NOT NULL,    -- Dependabots navn, * for alle
+    directory TEXT NOT NULL,
+    schedule TEXT,
+    groups TEXT NOT NULL,       -- kommaseparert
+    auto_merge BOOLEAN NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato, tool, path, ecosystem, directory)
+);
+
+-- Økosystemer funnet på toppnivå i repoet, og hvilke oppdateringsboter som dekker dem
+CREATE TABLE IF NOT EXISTS ecosystem_coverage (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    ecosystem TEXT NOT NULL,
+    manifests TEXT NOT NULL,    -- kommaseparert
+    covered_by TEXT NOT NULL,   -- kommaseparert, tom når ingen bot dekker økosystemet
+    is_covered BOOLEAN NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato, ecosystem)
+);
+
+-- CODEOWNERS: regler, eierskap per sti og dekning per repo
+CREATE TABLE IF NOT EXISTS codeowners_rules (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,         -- stien til CODEOWNERS-filen
+    line INTEGER NOT NULL,
+
+    pattern TEXT NOT NULL,
+    owners TEXT NOT NULL,       -- kommaseparert
+    unknown_owners TEXT,        -- eiere som ikke finnes i org, NULL når det ikke er sjekket
+    error TEXT,                 -- satt for linjer GitHub hopper over
+
+    UNIQUE (repo_id, hentet_dato, path, line)
+);
+
+CREATE TABLE IF NOT EXISTS codeowners_paths (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,         -- mappe på toppnivå eller hentet fil
+
+    is_dir BOOLEAN NOT NULL,
+    owners TEXT NOT NULL,       -- tom når stien ikke har eier
+    rule_line INTEGER,
+
+    UNIQUE (repo_id, hentet_dato, path)
+);
+
+CREATE TABLE IF NOT EXISTS codeowners_coverage (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    codeowners_path TEXT,       -- NULL når repoet ikke har CODEOWNERS
+    rule_count INTEGER NOT NULL,
+    invalid_rule_count INTEGER NOT NULL,
+    owned_percent REAL NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato)
+);
+
+CREATE TABLE IF NOT EXISTS repo_frameworks (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    framework TEXT NOT NULL,    -- f.eks. "Spring Boot", "Next.js" eller "JVM"
+    kind TEXT NOT NULL,         -- framework eller runtime
+    version TEXT,
+    confidence REAL NOT NULL,   -- 0–1
+    evidence TEXT,              -- signalene som ga treff, separert med "; "
+
+    UNIQUE (repo_id, hentet_dato, framework)
+);
+
+CREATE TABLE IF NOT EXISTS runtime_versions (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    runtime TEXT NOT NULL,      -- go, node, java eller python
+    version TEXT,               -- normalisert, f.eks. "1.22", "20", "17" eller "3.11"
+    raw_value TEXT NOT NULL,    -- slik det står i filen, f.eks. ">=18.0.0"
+    source_path TEXT NOT NULL,  -- filen versjonen er satt i
+    setting TEXT NOT NULL,      -- f.eks. "toolchain", "engines.node", "actions/setup-node" eller "FROM"
+
+    UNIQUE (repo_id, hentet_dato, runtime, source_path, setting, raw_value)
+);
+
+CREATE TABLE IF NOT EXISTS direct_dependencies (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    ecosystem TEXT NOT NULL,    -- purl-type: golang, npm, maven eller pypi
+    name TEXT NOT NULL,         -- samme form som i sbom_github_packages
+    version_constraint TEXT,    -- slik det står i manifestet, f.eks. "^18.2.0"
+    scope TEXT NOT NULL,        -- runtime, dev eller test
+    kind TEXT NOT NULL,         -- direct, parent, managed eller plugin
+    source_path TEXT NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato, source_path, ecosystem, name, scope, kind)
+);
+
+CREATE TABLE IF NOT EXISTS secret_findings (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,
+
+    rule_id TEXT NOT NULL,
+    description TEXT NOT NULL,
+    line INTEGER NOT NULL,
+    secret_hash TEXT NOT NULL,  -- SHA-256 av hemmeligheten; selve verdien lagres aldri
+
+    UNIQUE (repo_id, hentet_dato, path, rule_id, line, secret_hash)
+);
+
+CREATE TABLE IF NOT EXISTS deploy_manifests (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,
+
+    kind TEXT NOT NULL,         -- nais-application, nais-naisjob, deployment, statefulset, daemonset, job, cronjob, ingress, helm-values
+    name TEXT NOT NULL,
+    namespace TEXT,
+    images TEXT,                -- kommaseparert
+    replicas_min INTEGER,
+    replicas_max INTEGER,
+    cpu_request TEXT,
+    cpu_limit TEXT,
+    memory_request TEXT,
+    memory_limit TEXT,
+    ingress_hosts TEXT,         -- kommaseparert
+    inbound_access TEXT,        -- kommaseparert, [cluster/][namespace/]app
+    outbound_access TEXT,       -- kommaseparert, apper og eksterne verter
+    liveness_probe TEXT,        -- HTTP-sti eller probetype
+    readiness_probe TEXT,
+    run_as_non_root BOOLEAN,
+    read_only_root_filesystem BOOLEAN,
+
+    UNIQUE (repo_id, hentet_dato, path, kind, name)
+);
+
+CREATE TABLE IF NOT EXISTS iac_modules (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,
+
+    tool TEXT NOT NULL,         -- terraform, pulumi, bicep
+    kind TEXT NOT NULL,         -- required_version, provider, locked_provider, module, backend, project
+    name TEXT NOT NULL,
+    source TEXT,
+    source_type TEXT,           -- for moduler: local, registry, git, hg, http, s3, gcs, template-spec
+    version TEXT,               -- versjonskrav, låst versjon eller git-ref
+    pinned BOOLEAN,             -- NULL for alt annet enn moduler med ekstern kilde
+
+    UNIQUE (repo_id, hentet_dato, path, kind, name)
+);
+
+CREATE TABLE IF NOT EXISTS compose_services (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,
+
+    service TEXT NOT NULL,
+    image TEXT,                 -- tolket som FROM i Dockerfiles; NULL når tjenesten bare bygges
+    image_tag TEXT,
+    raw_image TEXT,             -- image-uttrykket når det ikke kunne løses opp
+    registry TEXT,
+    is_digest_pinned BOOLEAN NOT NULL,
+    digest TEXT,
+    build_context TEXT,
+    dockerfile TEXT,
+    ports TEXT,                 -- kommaseparert, kort form
+    privileged BOOLEAN NOT NULL,
+    network_mode TEXT,          -- f.eks. host
+    env_files TEXT,             -- kommaseparert
+
+    UNIQUE (repo_id, hentet_dato, path, service)
+);
+
+CREATE TABLE IF NOT EXISTS compose_volumes (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    path TEXT NOT NULL,
+
+    service TEXT NOT NULL,
+    mount_type TEXT NOT NULL,   -- bind, volume, tmpfs, secret
+    source TEXT,                -- sti, volumnavn eller secret-fil
+    target TEXT NOT NULL,
+    read_only BOOLEAN NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato, path, service, target)
+);
+
+CREATE TABLE IF NOT EXISTS readme_metrics (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    length INTEGER NOT NULL,            -- antall tegn
+    word_count INTEGER NOT NULL,        -- ord utenfor kodeblokker
+    heading_count INTEGER NOT NULL,
+    headings TEXT,                      -- semikolonseparert
+    has_installation BOOLEAN NOT NULL,
+    has_usage BOOLEAN NOT NULL,
+    has_contact BOOLEAN NOT NULL,
+    has_ownership BOOLEAN NOT NULL,
+    badge_count INTEGER NOT NULL,
+    badges TEXT,                        -- kommaseparert, f.eks. github-actions,codecov
+    internal_links INTEGER NOT NULL,
+    external_links INTEGER NOT NULL,
+    code_blocks INTEGER NOT NULL,
+    language TEXT NOT NULL,             -- no, en, mixed, unknown
+    is_template BOOLEAN NOT NULL,
+    template_markers TEXT,              -- kommaseparert
+
+    UNIQUE (repo_id, hentet_dato)
+);
+
+CREATE TABLE IF NOT EXISTS file_fingerprints (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    path TEXT NOT NULL,
+    file_type TEXT NOT NULL,            -- dockerfile, workflow
+    content_hash TEXT NOT NULL,         -- sha256 av normalisert innhold
+    simhash TEXT NOT NULL,              -- 64 bits som hex
+    line_count INTEGER NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato, path)
+);
+
+-- Filer som deler slekt med andre filer i samme snapshot, regnet ut etter at alle repos er hentet
+CREATE TABLE IF NOT EXISTS file_lineage (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    path TEXT NOT NULL,
+    file_type TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    cluster_id TEXT NOT NULL,           -- content_hash for klyngens vanligste variant
+    cluster_size INTEGER NOT NULL,      -- antall filer
+    cluster_repos INTEGER NOT NULL,
+    variant_count INTEGER NOT NULL,
+    is_canonical BOOLEAN NOT NULL,
+    simhash_distance INTEGER NOT NULL,  -- bits fra vanligste variant
+    divergence REAL NOT NULL,           -- 0 = lik vanligste variant, 1 = ingen felles linjer
+
+    UNIQUE (repo_id, hentet_dato, path)
+);
+
 CREATE TABLE IF NOT EXISTS sbom_github_packages (
     id SERIAL PRIMARY KEY,
     repo_id BIGINT NOT NULL,
@@ -92,6 +529,84 @@ CREATE TABLE IF NOT EXISTS sbom_github_packages (
     version TEXT,
     license TEXT,
     purl TEXT,
+    source TEXT NOT NULL DEFAULT 'github', -- 'github' (dependency graph) eller 'local' (fra låsefiler)
 
     UNIQUE (repo_id, hentet_dato, name, version)
 );
+
+-- Eksisterende databaser får nye kolonner her, siden CREATE TABLE IF NOT EXISTS ikke endrer tabellen
+ALTER TABLE sbom_github_packages ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'github';
+
+CREATE TABLE IF NOT EXISTS skipped_files (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    path TEXT NOT NULL,
+    reason TEXT NOT NULL, -- binary, truncated, too_large, fetch_failed
+    byte_size BIGINT NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato, path)
+);
+
+CREATE TABLE IF NOT EXISTS repo_lifecycle_events (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+
+    -- created, renamed, transferred, archived, unarchived, deleted_or_inaccessible
+    event_type TEXT NOT NULL,
+    old_full_name TEXT,
+    new_full_name TEXT,
+
+    UNIQUE (repo_id, hentet_dato, event_type)
+);
+
+-- Arkiverte repos som ble hoppet over (REPOSNUSERARCHIVE=false). Bare nok til at
+-- neste kjøring ser at repoet fantes og var arkivert, og kan melde at det er gjenåpnet.
+CREATE TABLE IF NOT EXISTS skipped_archived_repos (
+    id SERIAL PRIMARY KEY,
+    repo_id BIGINT NOT NULL,
+    hentet_dato DATE NOT NULL,
+    full_name TEXT NOT NULL,
+
+    UNIQUE (repo_id, hentet_dato)
+);
+
+-- Forks i siste snapshot, sortert etter hvor langt de ligger bak parent sin default branch
+CREATE OR REPLACE VIEW fork_lag_report AS
+SELECT
+    r.id AS repo_id,
+    r.hentet_dato,
+    r.full_name,
+    r.parent_full_name,
+    r.parent_in_org,
+    r.fork_ahead_by,
+    r.fork_behind_by,
+    r.pushed_at
+FROM repos r
+WHERE r.is_fork
+  AND r.parent_full_name IS NOT NULL
+  AND r.hentet_dato = (SELECT MAX(hentet_dato) FROM repos)
+ORDER BY r.fork_behind_by DESC NULLS LAST;
+
+-- Base images som er forbi eller nær EOL i siste snapshot, per repo
+CREATE OR REPLACE VIEW base_image_eol_report AS
+SELECT
+    s.repo_id,
+    s.hentet_dato,
+    r.full_name,
+    s.path,
+    s.stage_index,
+    s.is_final,
+    s.base_image,
+    s.base_tag,
+    s.image_family,
+    s.image_version,
+    s.eol_date,
+    s.eol_status
+FROM dockerfile_stages s
+JOIN repos r ON r.id = s.repo_id AND r.hentet_dato = s.hentet_dato
+WHERE s.eol_status IN ('eol', 'near_eol')
+  AND s.hentet_dato = (SELECT MAX(hentet_dato) FROM dockerfile_stages)
+ORDER BY s.eol_date, r.full_name, s.path, s.stage_index;
@@ -3,6 +3,7 @@ module github.com/jonmartinstorm/reposnusern
 go 1.24.3
 
 require (
+	cloud.google.com/go v0.121.0
 	cloud.google.com/go/bigquery v1.69.0
 	github.com/lib/pq v1.10.9
 	github.com/onsi/ginkgo/v2 v2.23.4
@@ -11,10 +12,10 @@ require (
 	github.com/testcontainers/testcontainers-go v0.37.0
 	golang.org/x/sync v0.14.0
 	google.golang.org/api v0.232.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	cloud.google.com/go v0.121.0 // indirect
 	cloud.google.com/go/auth v0.16.1 // indirect
 	cloud.google.com/go/auth/oauth2adapt v0.2.8 // indirect
 	cloud.google.com/go/compute/metadata v0.6.0 // indirect
@@ -96,5 +97,4 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250603155806-513f23925822 // indirect
 	google.golang.org/grpc v1.72.2 // indirect
 	google.golang.org/protobuf v1.36.6 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -3,14 +3,22 @@ package bqwriter
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"github.com/jonmartinstorm/reposnusern/internal/catalog"
 	"github.com/jonmartinstorm/reposnusern/internal/config"
+	"github.com/jonmartinstorm/reposnusern/internal/lifecycle"
+	"github.com/jonmartinstorm/reposnusern/internal/linguist"
 	"github.com/jonmartinstorm/reposnusern/internal/models"
 	"github.com/jonmartinstorm/reposnusern/internal/parser"
+	"github.com/jonmartinstorm/reposnusern/internal/similarity"
+	"github.com/jonmartinstorm/reposnusern/internal/workflow"
 	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
@@ -29,16 +37,41 @@ func NewBigQueryWriter(ctx context.Context, cfg *config.Config) (*BigQueryWriter
 
 	// Sørg for at hver tabell finnes
 	tables := map[string]any{
-		"repos":               BGRepoEntry{},
-		"repo_languages":      BGRepoLanguage{},
-		"dockerfile_features": BGDockerfileFeatures{},
-		"dockerfile_stages":   BGDockerStageMeta{},
-		"ci_config":           BGCIConfig{},
-		"sbom_packages":       BGSBOMPackages{},
+		"repos":                  BGRepoEntry{},
+		"repo_languages":         BGRepoLanguage{},
+		"dockerfile_features":    BGDockerfileFeatures{},
+		"dockerfile_stages":      BGDockerStageMeta{},
+		"dockerfile_findings":    BGDockerfileFinding{},
+		"base_image_digests":     BGBaseImageDigest{},
+		"ci_config":              BGCIConfig{},
+		"workflows":              BGWorkflow{},
+		"workflow_jobs":          BGWorkflowJob{},
+		"workflow_actions":       BGWorkflowAction{},
+		"workflow_findings":      BGWorkflowFinding{},
+		"update_bot_configs":     BGUpdateBotConfig{},
+		"ecosystem_coverage":     BGEcosystemCoverage{},
+		"codeowners_rules":       BGCodeownersRule{},
+		"codeowners_paths":       BGCodeownersPath{},
+		"codeowners_coverage":    BGCodeownersCoverage{},
+		"repo_frameworks":        BGRepoFramework{},
+		"runtime_versions":       BGRuntimeVersion{},
+		"direct_dependencies":    BGDirectDependency{},
+		"deploy_manifests":       BGDeployManifest{},
+		"iac_modules":            BGIaCModule{},
+		"compose_services":       BGComposeService{},
+		"compose_volumes":        BGComposeVolume{},
+		"readme_metrics":         BGReadmeMetrics{},
+		"file_fingerprints":      BGFileFingerprint{},
+		"secret_findings":        BGSecretFinding{},
+		"sbom_packages":          BGSBOMPackages{},
+		"skipped_files":          BGSkippedFile{},
+		"repo_lifecycle_events":  BGLifecycleEvent{},
+		"skipped_archived_repos": BGSkippedArchivedRepo{},
+		"file_lineage":           BGFileLineage{},
 	}
 
 	for tableName, schemaExample := range tables {
-		if err := ensureTableExists(ctx, client, cfg.BQDataset, tableName, schemaExample); err != nil {
+		if err := EnsureTableExists(ctx, client, cfg.BQDataset, tableName, schemaExample); err != nil {
 			return nil, fmt.Errorf("kunne ikke sikre tabell %s: %w", tableName, err)
 		}
 	}
@@ -53,8 +86,24 @@ func (w *BigQueryWriter) ImportRepo(ctx context.Context, entry models.RepoEntry,
 	repo := ConvertToBG(entry, snapshot)
 	langs := ConvertLanguages(entry, snapshot)
 	dockerfileFeatures, dockerfileStages := ConvertDockerfileFeatures(entry, snapshot)
+	dockerfileFindings := ConvertDockerfileFindings(entry, snapshot)
+	baseImages := ConvertBaseImageDigests(entry, snapshot)
 	ciconfig := ConvertCI(entry, snapshot)
+	workflows, workflowJobs, workflowActions := ConvertWorkflows(entry, snapshot)
+	workflowFindings := ConvertWorkflowFindings(entry, snapshot)
+	updateBots, ecosystemCoverage := ConvertUpdateBots(entry, snapshot)
+	codeownersRules, codeownersPaths, codeownersCoverage := ConvertCodeowners(entry, snapshot)
+	frameworks := ConvertFrameworks(entry, snapshot)
+	runtimeVersions := ConvertRuntimeVersions(entry, snapshot)
+	directDependencies := ConvertDirectDependencies(entry, snapshot)
+	deployManifests := ConvertDeployManifests(entry, snapshot)
+	iacModules := ConvertIaCModules(entry, snapshot)
+	composeServices, composeVolumes := ConvertCompose(entry, snapshot)
+	readmeMetrics := ConvertReadmeMetrics(entry, snapshot)
+	fileFingerprints := ConvertFileFingerprints(entry, snapshot)
+	secretFindings := ConvertSecretFindings(entry, snapshot)
 	sbom := ConvertSBOMPackages(entry, snapshot)
+	skipped := ConvertSkippedFiles(entry, snapshot)
 
 	if err := insert(ctx, w.Client, w.Dataset, "repos", []BGRepoEntry{repo}); err != nil {
 		return fmt.Errorf("repos insert failed: %w", err)
@@ -68,16 +117,146 @@ func (w *BigQueryWriter) ImportRepo(ctx context.Context, entry models.RepoEntry,
 	if err := insert(ctx, w.Client, w.Dataset, "dockerfile_stages", dockerfileStages); err != nil {
 		return fmt.Errorf("dockerfile_stagesinsert failed: %w", err)
 	}
+	if err := insert(ctx, w.Client, w.Dataset, "dockerfile_findings", dockerfileFindings); err != nil {
+		return fmt.Errorf("dockerfile_findings insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "base_image_digests", baseImages); err != nil {
+		return fmt.Errorf("base_image_digests insert failed: %w", err)
+	}
 	if err := insert(ctx, w.Client, w.Dataset, "ci_config", ciconfig); err != nil {
 		return fmt.Errorf("ci_config insert failed: %w", err)
 	}
+	if err := insert(ctx, w.Client, w.Dataset, "workflows", workflows); err != nil {
+		return fmt.Errorf("workflows insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "workflow_jobs", workflowJobs); err != nil {
+		return fmt.Errorf("workflow_jobs insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "workflow_actions", workflowActions); err != nil {
+		return fmt.Errorf("workflow_actions insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "workflow_findings", workflowFindings); err != nil {
+		return fmt.Errorf("workflow_findings insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "update_bot_configs", updateBots); err != nil {
+		return fmt.Errorf("update_bot_configs insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "ecosystem_coverage", ecosystemCoverage); err != nil {
+		return fmt.Errorf("ecosystem_coverage insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "codeowners_rules", codeownersRules); err != nil {
+		return fmt.Errorf("codeowners_rules insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "codeowners_paths", codeownersPaths); err != nil {
+		return fmt.Errorf("codeowners_paths insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "codeowners_coverage", []BGCodeownersCoverage{codeownersCoverage}); err != nil {
+		return fmt.Errorf("codeowners_coverage insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "repo_frameworks", frameworks); err != nil {
+		return fmt.Errorf("repo_frameworks insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "runtime_versions", runtimeVersions); err != nil {
+		return fmt.Errorf("runtime_versions insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "direct_dependencies", directDependencies); err != nil {
+		return fmt.Errorf("direct_dependencies insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "deploy_manifests", deployManifests); err != nil {
+		return fmt.Errorf("deploy_manifests insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "iac_modules", iacModules); err != nil {
+		return fmt.Errorf("iac_modules insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "compose_services", composeServices); err != nil {
+		return fmt.Errorf("compose_services insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "compose_volumes", composeVolumes); err != nil {
+		return fmt.Errorf("compose_volumes insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "readme_metrics", readmeMetrics); err != nil {
+		return fmt.Errorf("readme_metrics insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "file_fingerprints", fileFingerprints); err != nil {
+		return fmt.Errorf("file_fingerprints insert failed: %w", err)
+	}
+	if err := insert(ctx, w.Client, w.Dataset, "secret_findings", secretFindings); err != nil {
+		return fmt.Errorf("secret_findings insert failed: %w", err)
+	}
 	if err := insert(ctx, w.Client, w.Dataset, "sbom_packages", sbom); err != nil {
 		return fmt.Errorf("sbom insert failed: %w", err)
 	}
+	if err := insert(ctx, w.Client, w.Dataset, "skipped_files", skipped); err != nil {
+		return fmt.Errorf("skipped_files insert failed: %w", err)
+	}
 
 	return nil
 }
 
+// PreviousRepoStates henter repoene i siste snapshot-dato før snapshotTime, også arkiverte repos som ble hoppet over.
+func (w *BigQueryWriter) PreviousRepoStates(ctx context.Context, snapshotTime time.Time) ([]lifecycle.RepoState, error) {
+	repos := fmt.Sprintf("`%s.repos`", w.Dataset)
+	skipped := fmt.Sprintf("`%s.skipped_archived_repos`", w.Dataset)
+	q := w.Client.Query(fmt.Sprintf(`
+		WITH previous AS (
+			SELECT MAX(DATE(when_collected)) AS dato FROM %s WHERE DATE(when_collected) < DATE(@snapshot)
+		)
+		SELECT repo_id, full_name, archived
+		FROM %s
+		WHERE DATE(when_collected) = (SELECT dato FROM previous)
+		UNION DISTINCT
+		SELECT repo_id, full_name, TRUE AS archived
+		FROM %s
+		WHERE DATE(when_collected) = (SELECT dato FROM previous)`, repos, repos, skipped))
+	q.Parameters = []bigquery.QueryParameter{{Name: "snapshot", Value: snapshotTime}}
+
+	it, err := q.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("hent forrige snapshot: %w", err)
+	}
+
+	var states []lifecycle.RepoState
+	for {
+		var row struct {
+			RepoID   int64  `bigquery:"repo_id"`
+			FullName string `bigquery:"full_name"`
+			Archived bool   `bigquery:"archived"`
+		}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("les forrige snapshot: %w", err)
+		}
+		states = append(states, lifecycle.RepoState{ID: row.RepoID, FullName: row.FullName, Archived: row.Archived})
+	}
+	return states, nil
+}
+
+func (w *BigQueryWriter) ImportLifecycleEvents(ctx context.Context, events []lifecycle.Event, snapshot time.Time) error {
+	if err := insert(ctx, w.Client, w.Dataset, "repo_lifecycle_events", ConvertLifecycleEvents(events, snapshot)); err != nil {
+		return fmt.Errorf("repo_lifecycle_events insert failed: %w", err)
+	}
+	return nil
+}
+
+// ImportSkippedArchivedRepos lagrer arkiverte repos som ikke ble hentet, så neste kjøring kan se om de er gjenåpnet.
+func (w *BigQueryWriter) ImportSkippedArchivedRepos(ctx context.Context, repos []lifecycle.RepoState, snapshot time.Time) error {
+	if err := insert(ctx, w.Client, w.Dataset, "skipped_archived_repos", ConvertSkippedArchivedRepos(repos, snapshot)); err != nil {
+		return fmt.Errorf("skipped_archived_repos insert failed: %w", err)
+	}
+	return nil
+}
+
+// ImportFileLineage lagrer hvilke Dockerfiles og workflows som deler slekt på tvers av repoene.
+func (w *BigQueryWriter) ImportFileLineage(ctx context.Context, members []similarity.Member, snapshot time.Time) error {
+	if err := insert(ctx, w.Client, w.Dataset, "file_lineage", ConvertFileLineage(members, snapshot)); err != nil {
+		return fmt.Errorf("file_lineage insert failed: %w", err)
+	}
+	return nil
+}
+
 func insert[T any](ctx context.Context, client *bigquery.Client, dataset, table string, rows []T) error {
 	if len(rows) == 0 {
 		return nil
@@ -114,6 +293,13 @@ type BGRepoEntry struct {
 	HasSecurityMD bool      `bigquery:"has_security_md"`
 	HasDependabot bool      `bigquery:"has_dependabot"`
 	HasCodeQL     bool      `bigquery:"has_codeql"`
+
+	ParentFullName   string             `bigquery:"parent_full_name"`
+	ParentInOrg      bool               `bigquery:"parent_in_org"`
+	TemplateFullName string             `bigquery:"template_full_name"`
+	TemplateInOrg    bool               `bigquery:"template_in_org"`
+	ForkAheadBy      bigquery.NullInt64 `bigquery:"fork_ahead_by"`
+	ForkBehindBy     bigquery.NullInt64 `bigquery:"fork_behind_by"`
 }
 
 type BGRepoLanguage struct {
@@ -121,6 +307,8 @@ type BGRepoLanguage struct {
 	WhenCollected time.Time `bigquery:"when_collected"`
 	Language      string    `bigquery:"language"`
 	Bytes         int64     `bigquery:"bytes"`
+	Share         float64   `bigquery:"share"`
+	LanguageType  string    `bigquery:"language_type"`
 }
 
 type BGDockerfileFeatures struct {
@@ -129,6 +317,9 @@ type BGDockerfileFeatures struct {
 	FileType             string    `bigquery:"file_type"`
 	Content              string    `bigquery:"content"`
 	Path                 string    `bigquery:"path"`
+	RawImage             string    `bigquery:"raw_image"`
+	Registry             string    `bigquery:"registry"`
+	IsDigestPinned       bool      `bigquery:"is_digest_pinned"`
 	UsesLatestTag        bool      `bigquery:"uses_latest_tag"`
 	HasUserInstruction   bool      `bigquery:"has_user_instruction"`
 	HasCopySensitive     bool      `bigquery:"has_copy_sensitive"`
@@ -144,15 +335,63 @@ type BGDockerfileFeatures struct {
 	HasAptGetClean       bool      `bigquery:"has_apt_get_clean"`
 	WorldWritable        bool      `bigquery:"world_writable"`
 	HasSecretsInEnvOrArg bool      `bigquery:"has_secrets_in_env_or_arg"`
+
+	FinalStageIndex         int               `bigquery:"final_stage_index"`
+	FinalRunsAsRoot         bigquery.NullBool `bigquery:"final_runs_as_root"`
+	FinalInstallsBuildTools bool              `bigquery:"final_installs_build_tools"`
 }
 
 type BGDockerStageMeta struct {
+	RepoID         int64     `bigquery:"repo_id"`
+	WhenCollected  time.Time `bigquery:"when_collected"`
+	Path           string    `bigquery:"path"`
+	StageIndex     int       `bigquery:"stage_index"`
+	BaseImage      string    `bigquery:"base_image"`
+	BaseTag        string    `bigquery:"base_tag"`
+	RawImage       string    `bigquery:"raw_image"`
+	Registry       string    `bigquery:"registry"`
+	IsDigestPinned bool      `bigquery:"is_digest_pinned"`
+	Alias          string    `bigquery:"alias"`
+	BaseStage      string    `bigquery:"base_stage"`
+
+	UserName           string            `bigquery:"user_name"`
+	RunsAsRoot         bigquery.NullBool `bigquery:"runs_as_root"`
+	HasPackageInstalls bool              `bigquery:"has_package_installs"`
+	InstallsBuildTools bool              `bigquery:"installs_build_tools"`
+	InstallsCurlOrWget bool              `bigquery:"installs_curl_or_wget"`
+	CopyFrom           []string          `bigquery:"copy_from"`
+	ExposedPorts       []string          `bigquery:"exposed_ports"`
+	Entrypoint         string            `bigquery:"entrypoint"`
+	Cmd                string            `bigquery:"cmd"`
+	IsFinal            bool              `bigquery:"is_final"`
+
+	ImageFamily  string            `bigquery:"image_family"`
+	ImageVersion string            `bigquery:"image_version"`
+	EOLDate      bigquery.NullDate `bigquery:"eol_date"`
+	EOLStatus    string            `bigquery:"eol_status"`
+}
+
+type BGDockerfileFinding struct {
 	RepoID        int64     `bigquery:"repo_id"`
 	WhenCollected time.Time `bigquery:"when_collected"`
 	Path          string    `bigquery:"path"`
-	StageIndex    int       `bigquery:"stage_index"`
-	BaseImage     string    `bigquery:"base_image"`
-	BaseTag       string    `bigquery:"base_tag"`
+	RuleID        string    `bigquery:"rule_id"`
+	Severity      string    `bigquery:"severity"`
+	Description   string    `bigquery:"description"`
+	StartLine     int       `bigquery:"start_line"`
+	EndLine       int       `bigquery:"end_line"`
+}
+
+type BGBaseImageDigest struct {
+	RepoID        int64                  `bigquery:"repo_id"`
+	WhenCollected time.Time              `bigquery:"when_collected"`
+	Reference     string                 `bigquery:"reference"`
+	Registry      string                 `bigquery:"registry"`
+	PinnedDigest  string                 `bigquery:"pinned_digest"`
+	CurrentDigest string                 `bigquery:"current_digest"`
+	ImageCreated  bigquery.NullTimestamp `bigquery:"image_created"`
+	DigestDiffers bool                   `bigquery:"digest_differs"`
+	Error         string                 `bigquery:"error"`
 }
 
 type BGCIConfig struct {
@@ -162,6 +401,241 @@ type BGCIConfig struct {
 	Content       string    `bigquery:"content"`
 }
 
+type BGWorkflow struct {
+	RepoID        int64               `bigquery:"repo_id"`
+	WhenCollected time.Time           `bigquery:"when_collected"`
+	Path          string              `bigquery:"path"`
+	Name          string              `bigquery:"name"`
+	Triggers      []string            `bigquery:"triggers"`
+	Permissions   bigquery.NullString `bigquery:"permissions"`
+	Secrets       []string            `bigquery:"secrets"`
+}
+
+type BGWorkflowJob struct {
+	RepoID         int64               `bigquery:"repo_id"`
+	WhenCollected  time.Time           `bigquery:"when_collected"`
+	Path           string              `bigquery:"path"`
+	JobID          string              `bigquery:"job_id"`
+	Name           string              `bigquery:"name"`
+	Line           int                 `bigquery:"line"`
+	RunsOn         []string            `bigquery:"runs_on"`
+	Permissions    bigquery.NullString `bigquery:"permissions"`
+	Uses           string              `bigquery:"uses"`
+	SecretsInherit bool                `bigquery:"secrets_inherit"`
+}
+
+type BGWorkflowAction struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	Path          string    `bigquery:"path"`
+	JobID         string    `bigquery:"job_id"`
+	StepIndex     int       `bigquery:"step_index"`
+	Uses          string    `bigquery:"uses"`
+	Kind          string    `bigquery:"kind"`
+	Action        string    `bigquery:"action"`
+	Ref           string    `bigquery:"ref"`
+	RefType       string    `bigquery:"ref_type"`
+	Line          int       `bigquery:"line"`
+}
+
+type BGWorkflowFinding struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	Path          string    `bigquery:"path"`
+	RuleID        string    `bigquery:"rule_id"`
+	Severity      string    `bigquery:"severity"`
+	Description   string    `bigquery:"description"`
+	JobID         string    `bigquery:"job_id"`
+	Line          int       `bigquery:"line"`
+}
+
+type BGUpdateBotConfig struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	Tool          string    `bigquery:"tool"`
+	Path          string    `bigquery:"path"`
+	Ecosystem     string    `bigquery:"ecosystem"`
+	Directory     string    `bigquery:"directory"`
+	Schedule      string    `bigquery:"schedule"`
+	Groups        []string  `bigquery:"groups"`
+	AutoMerge     bool      `bigquery:"auto_merge"`
+}
+
+type BGEcosystemCoverage struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	Ecosystem     string    `bigquery:"ecosystem"`
+	Manifests     []string  `bigquery:"manifests"`
+	CoveredBy     []string  `bigquery:"covered_by"`
+	IsCovered     bool      `bigquery:"is_covered"`
+}
+
+type BGCodeownersRule struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	Path          string    `bigquery:"path"`
+	Line          int       `bigquery:"line"`
+	Pattern       string    `bigquery:"pattern"`
+	Owners        []string  `bigquery:"owners"`
+	UnknownOwners []string  `bigquery:"unknown_owners"`
+	OwnersChecked bool      `bigquery:"owners_checked"`
+	Error         string    `bigquery:"error"`
+}
+
+type BGCodeownersPath struct {
+	RepoID        int64              `bigquery:"repo_id"`
+	WhenCollected time.Time          `bigquery:"when_collected"`
+	Path          string             `bigquery:"path"`
+	IsDir         bool               `bigquery:"is_dir"`
+	Owners        []string           `bigquery:"owners"`
+	RuleLine      bigquery.NullInt64 `bigquery:"rule_line"`
+}
+
+type BGCodeownersCoverage struct {
+	RepoID           int64     `bigquery:"repo_id"`
+	WhenCollected    time.Time `bigquery:"when_collected"`
+	CodeownersPath   string    `bigquery:"codeowners_path"`
+	RuleCount        int       `bigquery:"rule_count"`
+	InvalidRuleCount int       `bigquery:"invalid_rule_count"`
+	OwnedPercent     float64   `bigquery:"owned_percent"`
+}
+
+type BGRepoFramework struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	Framework     string    `bigquery:"framework"`
+	Kind          string    `bigquery:"kind"`
+	Version       string    `bigquery:"version"`
+	Confidence    float64   `bigquery:"confidence"`
+	Evidence      []string  `bigquery:"evidence"`
+}
+
+type BGRuntimeVersion struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	Runtime       string    `bigquery:"runtime"`
+	Version       string    `bigquery:"version"`
+	RawValue      string    `bigquery:"raw_value"`
+	SourcePath    string    `bigquery:"source_path"`
+	Setting       string    `bigquery:"setting"`
+}
+
+type BGDirectDependency struct {
+	RepoID            int64     `bigquery:"repo_id"`
+	WhenCollected     time.Time `bigquery:"when_collected"`
+	Ecosystem         string    `bigquery:"ecosystem"`
+	Name              string    `bigquery:"name"`
+	VersionConstraint string    `bigquery:"version_constraint"`
+	Scope             string    `bigquery:"scope"`
+	Kind              string    `bigquery:"kind"`
+	SourcePath        string    `bigquery:"source_path"`
+}
+
+type BGDeployManifest struct {
+	RepoID                 int64              `bigquery:"repo_id"`
+	WhenCollected          time.Time          `bigquery:"when_collected"`
+	Path                   string             `bigquery:"path"`
+	Kind                   string             `bigquery:"kind"`
+	Name                   string             `bigquery:"name"`
+	Namespace              string             `bigquery:"namespace"`
+	Images                 []string           `bigquery:"images"`
+	ReplicasMin            bigquery.NullInt64 `bigquery:"replicas_min"`
+	ReplicasMax            bigquery.NullInt64 `bigquery:"replicas_max"`
+	CPURequest             string             `bigquery:"cpu_request"`
+	CPULimit               string             `bigquery:"cpu_limit"`
+	MemoryRequest          string             `bigquery:"memory_request"`
+	MemoryLimit            string             `bigquery:"memory_limit"`
+	IngressHosts           []string           `bigquery:"ingress_hosts"`
+	InboundAccess          []string           `bigquery:"inbound_access"`
+	OutboundAccess         []string           `bigquery:"outbound_access"`
+	LivenessProbe          string             `bigquery:"liveness_probe"`
+	ReadinessProbe         string             `bigquery:"readiness_probe"`
+	RunAsNonRoot           bigquery.NullBool  `bigquery:"run_as_non_root"`
+	ReadOnlyRootFilesystem bigquery.NullBool  `bigquery:"read_only_root_filesystem"`
+}
+
+type BGIaCModule struct {
+	RepoID        int64             `bigquery:"repo_id"`
+	WhenCollected time.Time         `bigquery:"when_collected"`
+	Path          string            `bigquery:"path"`
+	Tool          string            `bigquery:"tool"`
+	Kind          string            `bigquery:"kind"`
+	Name          string            `bigquery:"name"`
+	Source        string            `bigquery:"source"`
+	SourceType    string            `bigquery:"source_type"`
+	Version       string            `bigquery:"version"`
+	Pinned        bigquery.NullBool `bigquery:"pinned"`
+}
+
+type BGComposeService struct {
+	RepoID         int64     `bigquery:"repo_id"`
+	WhenCollected  time.Time `bigquery:"when_collected"`
+	Path           string    `bigquery:"path"`
+	Service        string    `bigquery:"service"`
+	Image          string    `bigquery:"image"`
+	ImageTag       string    `bigquery:"image_tag"`
+	RawImage       string    `bigquery:"raw_image"`
+	Registry       string    `bigquery:"registry"`
+	IsDigestPinned bool      `bigquery:"is_digest_pinned"`
+	Digest         string    `bigquery:"digest"`
+	BuildContext   string    `bigquery:"build_context"`
+	Dockerfile     string    `bigquery:"dockerfile"`
+	Ports          []string  `bigquery:"ports"`
+	Privileged     bool      `bigquery:"privileged"`
+	NetworkMode    string    `bigquery:"network_mode"`
+	EnvFiles       []string  `bigquery:"env_files"`
+}
+
+type BGComposeVolume struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	Path          string    `bigquery:"path"`
+	Service       string    `bigquery:"service"`
+	MountType     string    `bigquery:"mount_type"`
+	Source        string    `bigquery:"source"`
+	Target        string    `bigquery:"target"`
+	ReadOnly      bool      `bigquery:"read_only"`
+}
+
+type BGReadmeMetrics struct {
+	RepoID          int64     `bigquery:"repo_id"`
+	WhenCollected   time.Time `bigquery:"when_collected"`
+	Length          int       `bigquery:"length"`
+	WordCount       int       `bigquery:"word_count"`
+	Headings        []string  `bigquery:"headings"`
+	HasInstallation bool      `bigquery:"has_installation"`
+	HasUsage        bool      `bigquery:"has_usage"`
+	HasContact      bool      `bigquery:"has_contact"`
+	HasOwnership    bool      `bigquery:"has_ownership"`
+	Badges          []string  `bigquery:"badges"`
+	InternalLinks   int       `bigquery:"internal_links"`
+	ExternalLinks   int       `bigquery:"external_links"`
+	CodeBlocks      int       `bigquery:"code_blocks"`
+	Language        string    `bigquery:"language"`
+	IsTemplate      bool      `bigquery:"is_template"`
+	TemplateMarkers []string  `bigquery:"template_markers"`
+}
+
+type BGFileFingerprint struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	Path          string    `bigquery:"path"`
+	FileType      string    `bigquery:"file_type"`
+	ContentHash   string    `bigquery:"content_hash"`
+	SimHash       string    `bigquery:"simhash"`
+	LineCount     int       `bigquery:"line_count"`
+}
+
+type BGSecretFinding struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	Path          string    `bigquery:"path"`
+	RuleID        string    `bigquery:"rule_id"`
+	Description   string    `bigquery:"description"`
+	Line          int       `bigquery:"line"`
+	SecretHash    string    `bigquery:"secret_hash"`
+}
+
 type BGSBOMPackages struct {
 	RepoID        int64     `bigquery:"repo_id"`
 	WhenCollected time.Time `bigquery:"when_collected"`
@@ -169,6 +643,44 @@ type BGSBOMPackages struct {
 	Version       string    `bigquery:"version"`
 	License       string    `bigquery:"license"`
 	PURL          string    `bigquery:"purl"`
+	Source        string    `bigquery:"source"`
+}
+
+type BGSkippedFile struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	Path          string    `bigquery:"path"`
+	Reason        string    `bigquery:"reason"`
+	ByteSize      int64     `bigquery:"byte_size"`
+}
+
+type BGLifecycleEvent struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	EventType     string    `bigquery:"event_type"`
+	OldFullName   string    `bigquery:"old_full_name"`
+	NewFullName   string    `bigquery:"new_full_name"`
+}
+
+type BGSkippedArchivedRepo struct {
+	RepoID        int64     `bigquery:"repo_id"`
+	WhenCollected time.Time `bigquery:"when_collected"`
+	FullName      string    `bigquery:"full_name"`
+}
+
+type BGFileLineage struct {
+	RepoID          int64     `bigquery:"repo_id"`
+	WhenCollected   time.Time `bigquery:"when_collected"`
+	Path            string    `bigquery:"path"`
+	FileType        string    `bigquery:"file_type"`
+	ContentHash     string    `bigquery:"content_hash"`
+	ClusterID       string    `bigquery:"cluster_id"`
+	ClusterSize     int       `bigquery:"cluster_size"`
+	ClusterRepos    int       `bigquery:"cluster_repos"`
+	VariantCount    int       `bigquery:"variant_count"`
+	IsCanonical     bool      `bigquery:"is_canonical"`
+	SimhashDistance int       `bigquery:"simhash_distance"`
+	Divergence      float64   `bigquery:"divergence"`
 }
 
 // ==== Mapping-funksjoner ====
@@ -201,17 +713,27 @@ func ConvertToBG(entry models.RepoEntry, snapshot time.Time) BGRepoEntry {
 		HasSecurityMD: r.Security["has_security_md"],
 		HasDependabot: r.Security["has_dependabot"],
 		HasCodeQL:     r.Security["has_codeql"],
+
+		ParentFullName:   r.Lineage.ParentFullName,
+		ParentInOrg:      r.Lineage.ParentInOrg,
+		TemplateFullName: r.Lineage.TemplateFullName,
+		TemplateInOrg:    r.Lineage.TemplateInOrg,
+		ForkAheadBy:      nullInt64(r.Lineage.AheadBy),
+		ForkBehindBy:     nullInt64(r.Lineage.BehindBy),
 	}
 }
 
 func ConvertLanguages(entry models.RepoEntry, snapshot time.Time) []BGRepoLanguage {
 	var result []BGRepoLanguage
+	shares := linguist.Shares(entry.Languages)
 	for lang, size := range entry.Languages {
 		result = append(result, BGRepoLanguage{
 			RepoID:        entry.Repo.ID,
 			WhenCollected: snapshot,
 			Language:      lang,
 			Bytes:         int64(size),
+			Share:         shares[lang],
+			LanguageType:  linguist.Classify(lang),
 		})
 	}
 	return result
@@ -227,6 +749,7 @@ func ConvertDockerfileFeatures(entry models.RepoEntry, snapshot time.Time) ([]BG
 		}
 		for _, f := range list {
 			features, stages := parser.ParseDockerfile(f.Content)
+			catalog.Default().ResolveRunsAsRoot(&features, stages)
 
 			dff = append(dff, BGDockerfileFeatures{
 				RepoID:               entry.Repo.ID,
@@ -234,6 +757,9 @@ func ConvertDockerfileFeatures(entry models.RepoEntry, snapshot time.Time) ([]BG
 				FileType:             typ,
 				Path:                 f.Path,
 				Content:              f.Content,
+				RawImage:             features.RawImage,
+				Registry:             features.Registry,
+				IsDigestPinned:       features.IsDigestPinned,
 				UsesLatestTag:        features.UsesLatestTag,
 				HasUserInstruction:   features.HasUserInstruction,
 				HasCopySensitive:     features.HasCopySensitive,
@@ -249,16 +775,43 @@ func ConvertDockerfileFeatures(entry models.RepoEntry, snapshot time.Time) ([]BG
 				HasAptGetClean:       features.HasAptGetClean,
 				WorldWritable:        features.WorldWritable,
 				HasSecretsInEnvOrArg: features.HasSecretsInEnvOrArg,
+
+				FinalStageIndex:         features.FinalStageIndex,
+				FinalRunsAsRoot:         nullBool(features.FinalRunsAsRoot),
+				FinalInstallsBuildTools: features.FinalInstallsBuildTools,
 			})
 
-			for _, stage := range stages {
+			classes := catalog.Default().ClassifyStages(stages, snapshot)
+			for i, stage := range stages {
+				class := classes[i]
 				dsm = append(dsm, BGDockerStageMeta{
-					RepoID:        entry.Repo.ID,
-					WhenCollected: snapshot,
-					Path:          f.Path,
-					StageIndex:    stage.StageIndex,
-					BaseImage:     stage.BaseImage,
-					BaseTag:       stage.BaseTag,
+					RepoID:         entry.Repo.ID,
+					WhenCollected:  snapshot,
+					Path:           f.Path,
+					StageIndex:     stage.StageIndex,
+					BaseImage:      stage.BaseImage,
+					BaseTag:        stage.BaseTag,
+					RawImage:       stage.RawImage,
+					Registry:       stage.Registry,
+					IsDigestPinned: stage.IsDigestPinned,
+					Alias:          stage.Alias,
+					BaseStage:      stage.BaseStage,
+
+					UserName:           stage.User,
+					RunsAsRoot:         nullBool(stage.RunsAsRoot),
+					HasPackageInstalls: stage.HasPackageInstalls,
+					InstallsBuildTools: stage.InstallsBuildTools,
+					InstallsCurlOrWget: stage.InstallsCurlOrWget,
+					CopyFrom:           stage.CopyFrom,
+					ExposedPorts:       stage.ExposedPorts,
+					Entrypoint:         stage.Entrypoint,
+					Cmd:                stage.Cmd,
+					IsFinal:            stage.IsFinal,
+
+					ImageFamily:  class.Family,
+					ImageVersion: class.Version,
+					EOLDate:      nullDate(class.EOLDate),
+					EOLStatus:    class.EOLStatus,
 				})
 			}
 		}
@@ -267,6 +820,44 @@ func ConvertDockerfileFeatures(entry models.RepoEntry, snapshot time.Time) ([]BG
 	return dff, dsm
 }
 
+func ConvertDockerfileFindings(entry models.RepoEntry, snapshot time.Time) []BGDockerfileFinding {
+	var result []BGDockerfileFinding
+	for _, f := range entry.DockerfileFindings {
+		result = append(result, BGDockerfileFinding{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Path:          f.Path,
+			RuleID:        f.RuleID,
+			Severity:      f.Severity,
+			Description:   f.Description,
+			StartLine:     f.StartLine,
+			EndLine:       f.EndLine,
+		})
+	}
+	return result
+}
+
+func ConvertBaseImageDigests(entry models.RepoEntry, snapshot time.Time) []BGBaseImageDigest {
+	var result []BGBaseImageDigest
+	for _, img := range entry.BaseImages {
+		row := BGBaseImageDigest{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Reference:     img.Reference,
+			Registry:      img.Registry,
+			PinnedDigest:  img.PinnedDigest,
+			CurrentDigest: img.CurrentDigest,
+			DigestDiffers: img.DigestDiffers,
+			Error:         img.Error,
+		}
+		if img.ImageCreated != nil {
+			row.ImageCreated = bigquery.NullTimestamp{Timestamp: *img.ImageCreated, Valid: true}
+		}
+		result = append(result, row)
+	}
+	return result
+}
+
 func ConvertCI(entry models.RepoEntry, snapshot time.Time) []BGCIConfig {
 	var result []BGCIConfig
 	for _, f := range entry.CIConfig {
@@ -280,6 +871,350 @@ func ConvertCI(entry models.RepoEntry, snapshot time.Time) []BGCIConfig {
 	return result
 }
 
+func ConvertWorkflows(entry models.RepoEntry, snapshot time.Time) ([]BGWorkflow, []BGWorkflowJob, []BGWorkflowAction) {
+	var workflows []BGWorkflow
+	var jobs []BGWorkflowJob
+	var actions []BGWorkflowAction
+
+	for _, f := range entry.CIConfig {
+		wf, err := workflow.Parse(f.Content)
+		if err != nil {
+			slog.Warn("Kunne ikke parse workflow", "repo", entry.Repo.FullName, "fil", f.Path, "error", err)
+			continue
+		}
+
+		workflows = append(workflows, BGWorkflow{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Path:          f.Path,
+			Name:          wf.Name,
+			Triggers:      wf.Triggers,
+			Permissions:   nullPermissions(wf.Permissions),
+			Secrets:       wf.Secrets,
+		})
+
+		for _, job := range wf.Jobs {
+			row := BGWorkflowJob{
+				RepoID:         entry.Repo.ID,
+				WhenCollected:  snapshot,
+				Path:           f.Path,
+				JobID:          job.ID,
+				Name:           job.Name,
+				Line:           job.Line,
+				RunsOn:         job.RunsOn,
+				Permissions:    nullPermissions(job.Permissions),
+				SecretsInherit: job.SecretsInherit,
+			}
+			if job.Uses != nil {
+				row.Uses = job.Uses.Raw
+			}
+			jobs = append(jobs, row)
+		}
+
+		for _, u := range wf.Usages() {
+			actions = append(actions, BGWorkflowAction{
+				RepoID:        entry.Repo.ID,
+				WhenCollected: snapshot,
+				Path:          f.Path,
+				JobID:         u.Job,
+				StepIndex:     u.StepIndex,
+				Uses:          u.Raw,
+				Kind:          u.Kind,
+				Action:        u.Action(),
+				Ref:           u.Ref,
+				RefType:       u.RefType,
+				Line:          u.Line,
+			})
+		}
+	}
+
+	return workflows, jobs, actions
+}
+
+func ConvertWorkflowFindings(entry models.RepoEntry, snapshot time.Time) []BGWorkflowFinding {
+	var result []BGWorkflowFinding
+	for _, f := range entry.WorkflowFindings {
+		result = append(result, BGWorkflowFinding{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Path:          f.Path,
+			RuleID:        f.RuleID,
+			Severity:      f.Severity,
+			Description:   f.Description,
+			JobID:         f.Job,
+			Line:          f.Line,
+		})
+	}
+	return result
+}
+
+func ConvertUpdateBots(entry models.RepoEntry, snapshot time.Time) ([]BGUpdateBotConfig, []BGEcosystemCoverage) {
+	var configs []BGUpdateBotConfig
+	for _, c := range entry.UpdateBots {
+		configs = append(configs, BGUpdateBotConfig{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Tool:          c.Tool,
+			Path:          c.Path,
+			Ecosystem:     c.Ecosystem,
+			Directory:     c.Directory,
+			Schedule:      c.Schedule,
+			Groups:        c.Groups,
+			AutoMerge:     c.AutoMerge,
+		})
+	}
+
+	var coverage []BGEcosystemCoverage
+	for _, c := range entry.EcosystemCoverage {
+		coverage = append(coverage, BGEcosystemCoverage{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Ecosystem:     c.Ecosystem,
+			Manifests:     c.Manifests,
+			CoveredBy:     c.CoveredBy,
+			IsCovered:     len(c.CoveredBy) > 0,
+		})
+	}
+	return configs, coverage
+}
+
+func ConvertCodeowners(entry models.RepoEntry, snapshot time.Time) ([]BGCodeownersRule, []BGCodeownersPath, BGCodeownersCoverage) {
+	report := entry.Codeowners
+	coverage := BGCodeownersCoverage{
+		RepoID:         entry.Repo.ID,
+		WhenCollected:  snapshot,
+		CodeownersPath: report.File,
+		RuleCount:      len(report.Rules),
+		OwnedPercent:   report.OwnedPercent,
+	}
+
+	var rules []BGCodeownersRule
+	for _, r := range report.Rules {
+		if r.Error != "" {
+			coverage.InvalidRuleCount++
+		}
+		rules = append(rules, BGCodeownersRule{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Path:          report.File,
+			Line:          r.Line,
+			Pattern:       r.Pattern,
+			Owners:        r.Owners,
+			UnknownOwners: r.UnknownOwners,
+			OwnersChecked: r.UnknownOwners != nil,
+			Error:         r.Error,
+		})
+	}
+
+	var paths []BGCodeownersPath
+	for _, p := range report.Paths {
+		row := BGCodeownersPath{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Path:          p.Path,
+			IsDir:         p.IsDir,
+			Owners:        p.Owners,
+		}
+		if p.RuleLine > 0 {
+			row.RuleLine = bigquery.NullInt64{Int64: int64(p.RuleLine), Valid: true}
+		}
+		paths = append(paths, row)
+	}
+	return rules, paths, coverage
+}
+
+func ConvertFrameworks(entry models.RepoEntry, snapshot time.Time) []BGRepoFramework {
+	var result []BGRepoFramework
+	for _, d := range entry.Frameworks {
+		result = append(result, BGRepoFramework{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Framework:     d.Name,
+			Kind:          d.Kind,
+			Version:       d.Version,
+			Confidence:    d.Confidence,
+			Evidence:      d.Evidence,
+		})
+	}
+	return result
+}
+
+func ConvertRuntimeVersions(entry models.RepoEntry, snapshot time.Time) []BGRuntimeVersion {
+	var result []BGRuntimeVersion
+	for _, v := range entry.RuntimeVersions {
+		result = append(result, BGRuntimeVersion{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Runtime:       v.Runtime,
+			Version:       v.Version,
+			RawValue:      v.Raw,
+			SourcePath:    v.Source,
+			Setting:       v.Setting,
+		})
+	}
+	return result
+}
+
+func ConvertDirectDependencies(entry models.RepoEntry, snapshot time.Time) []BGDirectDependency {
+	var result []BGDirectDependency
+	for _, d := range entry.Dependencies {
+		result = append(result, BGDirectDependency{
+			RepoID:            entry.Repo.ID,
+			WhenCollected:     snapshot,
+			Ecosystem:         d.Ecosystem,
+			Name:              d.Name,
+			VersionConstraint: d.Constraint,
+			Scope:             d.Scope,
+			Kind:              d.Kind,
+			SourcePath:        d.Source,
+		})
+	}
+	return result
+}
+
+func ConvertDeployManifests(entry models.RepoEntry, snapshot time.Time) []BGDeployManifest {
+	var result []BGDeployManifest
+	for _, m := range entry.DeployManifests {
+		result = append(result, BGDeployManifest{
+			RepoID:                 entry.Repo.ID,
+			WhenCollected:          snapshot,
+			Path:                   m.Path,
+			Kind:                   m.Kind,
+			Name:                   m.Name,
+			Namespace:              m.Namespace,
+			Images:                 m.Images,
+			ReplicasMin:            nullInt64(m.ReplicasMin),
+			ReplicasMax:            nullInt64(m.ReplicasMax),
+			CPURequest:             m.CPURequest,
+			CPULimit:               m.CPULimit,
+			MemoryRequest:          m.MemoryRequest,
+			MemoryLimit:            m.MemoryLimit,
+			IngressHosts:           m.IngressHosts,
+			InboundAccess:          m.InboundAccess,
+			OutboundAccess:         m.OutboundAccess,
+			LivenessProbe:          m.LivenessProbe,
+			ReadinessProbe:         m.ReadinessProbe,
+			RunAsNonRoot:           nullBool(m.RunAsNonRoot),
+			ReadOnlyRootFilesystem: nullBool(m.ReadOnlyRootFilesystem),
+		})
+	}
+	return result
+}
+
+func ConvertIaCModules(entry models.RepoEntry, snapshot time.Time) []BGIaCModule {
+	var result []BGIaCModule
+	for _, m := range entry.IaCModules {
+		result = append(result, BGIaCModule{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Path:          m.Path,
+			Tool:          m.Tool,
+			Kind:          m.Kind,
+			Name:          m.Name,
+			Source:        m.Source,
+			SourceType:    m.SourceType,
+			Version:       m.Version,
+			Pinned:        nullBool(m.Pinned),
+		})
+	}
+	return result
+}
+
+func ConvertCompose(entry models.RepoEntry, snapshot time.Time) ([]BGComposeService, []BGComposeVolume) {
+	var services []BGComposeService
+	var volumes []BGComposeVolume
+	for _, s := range entry.ComposeServices {
+		services = append(services, BGComposeService{
+			RepoID:         entry.Repo.ID,
+			WhenCollected:  snapshot,
+			Path:           s.Path,
+			Service:        s.Service,
+			Image:          s.Image,
+			ImageTag:       s.Tag,
+			RawImage:       s.RawImage,
+			Registry:       s.Registry,
+			IsDigestPinned: s.IsDigestPinned,
+			Digest:         s.Digest,
+			BuildContext:   s.BuildContext,
+			Dockerfile:     s.Dockerfile,
+			Ports:          s.Ports,
+			Privileged:     s.Privileged,
+			NetworkMode:    s.NetworkMode,
+			EnvFiles:       s.EnvFiles,
+		})
+		for _, v := range s.Volumes {
+			volumes = append(volumes, BGComposeVolume{
+				RepoID:        entry.Repo.ID,
+				WhenCollected: snapshot,
+				Path:          s.Path,
+				Service:       s.Service,
+				MountType:     v.Type,
+				Source:        v.Source,
+				Target:        v.Target,
+				ReadOnly:      v.ReadOnly,
+			})
+		}
+	}
+	return services, volumes
+}
+
+func ConvertReadmeMetrics(entry models.RepoEntry, snapshot time.Time) []BGReadmeMetrics {
+	m := entry.ReadmeMetrics
+	if m == nil {
+		return nil
+	}
+	return []BGReadmeMetrics{{
+		RepoID:          entry.Repo.ID,
+		WhenCollected:   snapshot,
+		Length:          m.Length,
+		WordCount:       m.WordCount,
+		Headings:        m.Headings,
+		HasInstallation: m.HasInstallation,
+		HasUsage:        m.HasUsage,
+		HasContact:      m.HasContact,
+		HasOwnership:    m.HasOwnership,
+		Badges:          m.Badges,
+		InternalLinks:   m.InternalLinks,
+		ExternalLinks:   m.ExternalLinks,
+		CodeBlocks:      m.CodeBlocks,
+		Language:        m.Language,
+		IsTemplate:      len(m.TemplateMarkers) > 0,
+		TemplateMarkers: m.TemplateMarkers,
+	}}
+}
+
+func ConvertFileFingerprints(entry models.RepoEntry, snapshot time.Time) []BGFileFingerprint {
+	var result []BGFileFingerprint
+	for _, f := range entry.FileFingerprints {
+		result = append(result, BGFileFingerprint{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Path:          f.Path,
+			FileType:      f.FileType,
+			ContentHash:   f.ContentHash,
+			SimHash:       f.SimHash,
+			LineCount:     f.LineCount,
+		})
+	}
+	return result
+}
+
+func ConvertSecretFindings(entry models.RepoEntry, snapshot time.Time) []BGSecretFinding {
+	var result []BGSecretFinding
+	for _, f := range entry.SecretFindings {
+		result = append(result, BGSecretFinding{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Path:          f.Path,
+			RuleID:        f.RuleID,
+			Description:   f.Description,
+			Line:          f.Line,
+			SecretHash:    f.Hash,
+		})
+	}
+	return result
+}
+
 func ConvertSBOMPackages(entry models.RepoEntry, snapshot time.Time) []BGSBOMPackages {
 	raw := entry.SBOM
 	var result []BGSBOMPackages
@@ -305,9 +1240,71 @@ func ConvertSBOMPackages(entry models.RepoEntry, snapshot time.Time) []BGSBOMPac
 			Version:       safeString(pkg["versionInfo"]),
 			License:       safeString(pkg["licenseConcluded"]),
 			PURL:          extractPURL(pkg),
+			Source:        entry.SBOMSource,
+		})
+	}
+
+	return result
+}
+
+func ConvertSkippedFiles(entry models.RepoEntry, snapshot time.Time) []BGSkippedFile {
+	var result []BGSkippedFile
+	for _, f := range entry.SkippedFiles {
+		result = append(result, BGSkippedFile{
+			RepoID:        entry.Repo.ID,
+			WhenCollected: snapshot,
+			Path:          f.Path,
+			Reason:        f.Reason,
+			ByteSize:      f.ByteSize,
 		})
 	}
+	return result
+}
 
+func ConvertLifecycleEvents(events []lifecycle.Event, snapshot time.Time) []BGLifecycleEvent {
+	var result []BGLifecycleEvent
+	for _, e := range events {
+		result = append(result, BGLifecycleEvent{
+			RepoID:        e.RepoID,
+			WhenCollected: snapshot,
+			EventType:     e.Type,
+			OldFullName:   e.OldFullName,
+			NewFullName:   e.NewFullName,
+		})
+	}
+	return result
+}
+
+func ConvertSkippedArchivedRepos(repos []lifecycle.RepoState, snapshot time.Time) []BGSkippedArchivedRepo {
+	var result []BGSkippedArchivedRepo
+	for _, r := range repos {
+		result = append(result, BGSkippedArchivedRepo{
+			RepoID:        r.ID,
+			WhenCollected: snapshot,
+			FullName:      r.FullName,
+		})
+	}
+	return result
+}
+
+func ConvertFileLineage(members []similarity.Member, snapshot time.Time) []BGFileLineage {
+	var result []BGFileLineage
+	for _, m := range members {
+		result = append(result, BGFileLineage{
+			RepoID:          m.RepoID,
+			WhenCollected:   snapshot,
+			Path:            m.Path,
+			FileType:        m.FileType,
+			ContentHash:     m.ContentHash,
+			ClusterID:       m.ClusterID,
+			ClusterSize:     m.ClusterSize,
+			ClusterRepos:    m.ClusterRepos,
+			VariantCount:    m.Variants,
+			IsCanonical:     m.IsCanonical,
+			SimhashDistance: m.Distance,
+			Divergence:      m.Divergence,
+		})
+	}
 	return result
 }
 
@@ -339,30 +1336,117 @@ func extractPURL(pkg map[string]interface{}) string {
 	return ""
 }
 
-func parseTime(value string) time.Time {
-	t, _ := time.Parse(time.RFC3339, value)
-	return t
+func nullDate(v *time.Time) bigquery.NullDate {
+	if v == nil {
+		return bigquery.NullDate{}
+	}
+	return bigquery.NullDate{Date: civil.DateOf(*v), Valid: true}
 }
 
-func ensureTableExists(ctx context.Context, client *bigquery.Client, dataset, table string, exampleStruct any) error {
-	tbl := client.Dataset(dataset).Table(table)
-	_, err := tbl.Metadata(ctx)
-	if err == nil {
-		return nil // tabellen finnes
+func nullPermissions(v *string) bigquery.NullString {
+	if v == nil {
+		return bigquery.NullString{}
 	}
+	return bigquery.NullString{StringVal: *v, Valid: true}
+}
 
-	if gErr, ok := err.(*googleapi.Error); !ok || gErr.Code != 404 {
-		return fmt.Errorf("feil ved henting av tabell-metadata: %w", err)
+func nullBool(v *bool) bigquery.NullBool {
+	if v == nil {
+		return bigquery.NullBool{}
 	}
+	return bigquery.NullBool{Bool: *v, Valid: true}
+}
 
+func nullInt64(v *int) bigquery.NullInt64 {
+	if v == nil {
+		return bigquery.NullInt64{}
+	}
+	return bigquery.NullInt64{Int64: int64(*v), Valid: true}
+}
+
+func parseTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
+
+// EnsureTableExists oppretter tabellen fra exampleStruct. Finnes tabellen fra en tidligere
+// versjon, legges manglende felt til og felt som har blitt valgfrie løsnes. Nye felt legges
+// til som NULLABLE, siden BigQuery ikke tillater nye REQUIRED-kolonner i en tabell med data.
+func EnsureTableExists(ctx context.Context, client *bigquery.Client, dataset, table string, exampleStruct any) error {
 	schema, err := bigquery.InferSchema(exampleStruct)
 	if err != nil {
 		return fmt.Errorf("klarte ikke å generere schema for %s: %w", table, err)
 	}
 
-	if err := tbl.Create(ctx, &bigquery.TableMetadata{Schema: schema}); err != nil {
-		return fmt.Errorf("klarte ikke å opprette tabell %s: %w", table, err)
+	tbl := client.Dataset(dataset).Table(table)
+	meta, err := tbl.Metadata(ctx)
+	if err != nil {
+		if gErr, ok := err.(*googleapi.Error); !ok || gErr.Code != 404 {
+			return fmt.Errorf("feil ved henting av tabell-metadata: %w", err)
+		}
+		if err := tbl.Create(ctx, &bigquery.TableMetadata{Schema: schema}); err != nil {
+			return fmt.Errorf("klarte ikke å opprette tabell %s: %w", table, err)
+		}
+		return nil
 	}
 
+	merged, changed := mergeSchema(meta.Schema, schema)
+	if !changed {
+		return nil
+	}
+	slog.Info("Legger til nye kolonner i BigQuery-tabell", "tabell", table)
+	if _, err := tbl.Update(ctx, bigquery.TableMetadataToUpdate{Schema: merged}, meta.ETag); err != nil {
+		return fmt.Errorf("klarte ikke å oppdatere schema for %s: %w", table, err)
+	}
 	return nil
 }
+
+// mergeSchema legger feltene i wanted som mangler i existing til på slutten, også i nøstede records,
+// og løsner REQUIRED-felt som er valgfrie i wanted.
+func mergeSchema(existing, wanted bigquery.Schema) (bigquery.Schema, bool) {
+	byName := make(map[string]*bigquery.FieldSchema, len(existing))
+	merged := make(bigquery.Schema, 0, len(existing))
+	for _, f := range existing {
+		copied := *f
+		byName[f.Name] = &copied
+		merged = append(merged, &copied)
+	}
+
+	changed := false
+	for _, f := range wanted {
+		current, ok := byName[f.Name]
+		if !ok {
+			added := *f
+			relax(&added)
+			merged = append(merged, &added)
+			changed = true
+			continue
+		}
+		// Felt som har blitt valgfrie må løsnes, ellers avvises rader uten verdi
+		if current.Required && !f.Required {
+			current.Required = false
+			changed = true
+		}
+		if current.Type == bigquery.RecordFieldType && f.Type == bigquery.RecordFieldType {
+			if nested, nestedChanged := mergeSchema(current.Schema, f.Schema); nestedChanged {
+				current.Schema = nested
+				changed = true
+			}
+		}
+	}
+	return merged, changed
+}
+
+func relax(f *bigquery.FieldSchema) {
+	f.Required = false
+	if f.Schema == nil {
+		return
+	}
+	nested := make(bigquery.Schema, 0, len(f.Schema))
+	for _, child := range f.Schema {
+		copied := *child
+		relax(&copied)
+		nested = append(nested, &copied)
+	}
+	f.Schema = nested
+}
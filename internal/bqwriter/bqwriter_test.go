@@ -1,15 +1,28 @@
 package bqwriter_test
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
 	"time"
 
+	"cloud.google.com/go/bigquery"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"google.golang.org/api/option"
 
 	"github.com/jonmartinstorm/reposnusern/internal/bqwriter"
 	"github.com/jonmartinstorm/reposnusern/internal/models"
 )
 
+func TestBQWriter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "BigQuery-writer")
+}
+
 var _ = Describe("Mapping-funksjoner", func() {
 	snapshot := time.Date(2025, 6, 17, 12, 0, 0, 0, time.UTC)
 
@@ -77,6 +90,7 @@ var _ = Describe("Mapping-funksjoner", func() {
 				},
 			},
 		},
+		SBOMSource: "github",
 	}
 
 	It("konverterer til BGRepoEntry riktig", func() {
@@ -94,9 +108,27 @@ var _ = Describe("Mapping-funksjoner", func() {
 	})
 
 	It("konverterer dockerfile-features riktig", func() {
-		features, _ := bqwriter.ConvertDockerfileFeatures(entry, snapshot)
+		features, stages := bqwriter.ConvertDockerfileFeatures(entry, snapshot)
 		Expect(features).To(HaveLen(1))
 		Expect(features[0].Content).To(ContainSubstring("FROM alpine"))
+		Expect(features[0].Registry).To(Equal("docker.io"))
+		Expect(stages).To(HaveLen(1))
+		Expect(stages[0].IsDigestPinned).To(BeFalse())
+		Expect(stages[0].IsFinal).To(BeTrue())
+		Expect(stages[0].ImageFamily).To(Equal("alpine"))
+		Expect(stages[0].EOLStatus).To(Equal("unknown"))
+	})
+
+	It("konverterer dockerfile-funn riktig", func() {
+		withFindings := entry
+		withFindings.DockerfileFindings = []models.DockerfileFinding{
+			{Path: "Dockerfile", RuleID: "DF001", Severity: "warning", Description: "latest", StartLine: 1, EndLine: 1},
+		}
+		findings := bqwriter.ConvertDockerfileFindings(withFindings, snapshot)
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].RepoID).To(Equal(int64(42)))
+		Expect(findings[0].RuleID).To(Equal("DF001"))
+		Expect(findings[0].StartLine).To(Equal(1))
 	})
 
 	It("konverterer CI-configs riktig", func() {
@@ -105,10 +137,99 @@ var _ = Describe("Mapping-funksjoner", func() {
 		Expect(ci[0].Path).To(Equal(".github/workflows/ci.yml"))
 	})
 
+	It("parser workflows til egne tabeller", func() {
+		withWorkflow := entry
+		withWorkflow.CIConfig = []models.FileEntry{{
+			Path:    ".github/workflows/ci.yml",
+			Content: "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v2\n",
+		}}
+		workflows, jobs, actions := bqwriter.ConvertWorkflows(withWorkflow, snapshot)
+		Expect(workflows).To(HaveLen(1))
+		Expect(workflows[0].Triggers).To(Equal([]string{"push"}))
+		Expect(workflows[0].Permissions.Valid).To(BeFalse())
+		Expect(jobs).To(HaveLen(1))
+		Expect(jobs[0].RunsOn).To(Equal([]string{"ubuntu-latest"}))
+		Expect(actions).To(HaveLen(1))
+		Expect(actions[0].Action).To(Equal("actions/checkout"))
+		Expect(actions[0].Ref).To(Equal("v2"))
+		Expect(actions[0].RefType).To(Equal("tag"))
+	})
+
 	It("konverterer SBOM-pakker riktig", func() {
 		pkgs := bqwriter.ConvertSBOMPackages(entry, snapshot)
 		Expect(pkgs).To(HaveLen(1))
 		Expect(pkgs[0].Name).To(Equal("pkg"))
 		Expect(pkgs[0].PURL).To(Equal("pkg:golang/pkg@1.0"))
+		Expect(pkgs[0].Source).To(Equal("github"))
+	})
+})
+
+var _ = Describe("EnsureTableExists", func() {
+	type row struct {
+		RepoID int64  `bigquery:"repo_id"`
+		Name   string `bigquery:"name"`
+		Source string `bigquery:"source"`
+	}
+
+	// fakeBigQuery svarer på tabelloppslag med et eksisterende schema og tar vare på schema-oppdateringen
+	fakeBigQuery := func(existing string, patched *map[string]any) *bigquery.Client {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.Method {
+			case http.MethodGet:
+				_, _ = io.WriteString(w, existing)
+			case http.MethodPatch:
+				body, _ := io.ReadAll(r.Body)
+				Expect(json.Unmarshal(body, patched)).To(Succeed())
+				_, _ = w.Write(body)
+			default:
+				w.WriteHeader(http.StatusNotImplemented)
+			}
+		}))
+		DeferCleanup(srv.Close)
+
+		client, err := bigquery.NewClient(context.Background(), "prosjekt",
+			option.WithEndpoint(srv.URL), option.WithoutAuthentication(), option.WithHTTPClient(srv.Client()))
+		Expect(err).NotTo(HaveOccurred())
+		return client
+	}
+
+	It("legger til manglende kolonner i en eksisterende tabell som NULLABLE", func() {
+		var patched map[string]any
+		client := fakeBigQuery(`{"etag":"e1","tableReference":{"projectId":"prosjekt","datasetId":"ds","tableId":"sbom_packages"},
+			"schema":{"fields":[{"name":"repo_id","type":"INTEGER","mode":"REQUIRED"},{"name":"name","type":"STRING","mode":"REQUIRED"}]}}`, &patched)
+
+		Expect(bqwriter.EnsureTableExists(context.Background(), client, "ds", "sbom_packages", row{})).To(Succeed())
+
+		fields := patched["schema"].(map[string]any)["fields"].([]any)
+		Expect(fields).To(HaveLen(3))
+		added := fields[2].(map[string]any)
+		Expect(added["name"]).To(Equal("source"))
+		Expect(added["mode"]).NotTo(Equal("REQUIRED"))
+	})
+
+	It("løsner REQUIRED-kolonner som har blitt valgfrie", func() {
+		type stage struct {
+			RepoID     int64             `bigquery:"repo_id"`
+			RunsAsRoot bigquery.NullBool `bigquery:"runs_as_root"`
+		}
+		var patched map[string]any
+		client := fakeBigQuery(`{"etag":"e1","tableReference":{"projectId":"prosjekt","datasetId":"ds","tableId":"dockerfile_stages"},
+			"schema":{"fields":[{"name":"repo_id","type":"INTEGER","mode":"REQUIRED"},{"name":"runs_as_root","type":"BOOLEAN","mode":"REQUIRED"}]}}`, &patched)
+
+		Expect(bqwriter.EnsureTableExists(context.Background(), client, "ds", "dockerfile_stages", stage{})).To(Succeed())
+
+		fields := patched["schema"].(map[string]any)["fields"].([]any)
+		Expect(fields[0].(map[string]any)["mode"]).To(Equal("REQUIRED"))
+		Expect(fields[1].(map[string]any)["mode"]).NotTo(Equal("REQUIRED"))
+	})
+
+	It("lar tabellen være når schemaet allerede er komplett", func() {
+		var patched map[string]any
+		client := fakeBigQuery(`{"etag":"e1","tableReference":{"projectId":"prosjekt","datasetId":"ds","tableId":"sbom_packages"},
+			"schema":{"fields":[{"name":"repo_id","type":"INTEGER"},{"name":"name","type":"STRING"},{"name":"source","type":"STRING"}]}}`, &patched)
+
+		Expect(bqwriter.EnsureTableExists(context.Background(), client, "ds", "sbom_packages", row{})).To(Succeed())
+		Expect(patched).To(BeNil())
 	})
 })
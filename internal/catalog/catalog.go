@@ -0,0 +1,268 @@
+package catalog
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+)
+
+// EOL-status for et base image på snapshot-datoen
+const (
+	StatusSupported = "supported"
+	StatusNearEOL   = "near_eol"
+	StatusEOL       = "eol"
+	StatusUnknown   = "unknown"
+)
+
+// Standardbruker for et image når Dockerfilen ikke har USER
+const (
+	UserRoot    = "root"
+	UserNonroot = "nonroot"
+)
+
+// NearEOLWindow er hvor lenge før EOL et image regnes som "near_eol".
+const NearEOLWindow = 180 * 24 * time.Hour
+
+//go:embed catalog.yaml
+var embeddedCatalog []byte
+
+type Catalog struct {
+	families []family
+}
+
+type family struct {
+	Name          string
+	Images        []string
+	Versions      []version
+	User          string
+	NonrootTag    *regexp.Regexp
+	NonrootImages []string
+}
+
+type version struct {
+	Tag        *regexp.Regexp
+	Repository *regexp.Regexp
+	Version    string
+	EOL        time.Time
+}
+
+type fileFormat struct {
+	Families []struct {
+		Family        string   `yaml:"family"`
+		Images        []string `yaml:"images"`
+		User          string   `yaml:"user"`
+		NonrootTag    string   `yaml:"nonroot_tag"`
+		NonrootImages []string `yaml:"nonroot_images"`
+		Versions      []struct {
+			Tag        string `yaml:"tag"`
+			Repository string `yaml:"repository"`
+			Version    string `yaml:"version"`
+			EOL        string `yaml:"eol"`
+		} `yaml:"versions"`
+	} `yaml:"families"`
+}
+
+// Classification er resultatet av å slå opp et image i katalogen.
+type Classification struct {
+	Family    string
+	Version   string
+	EOLDate   *time.Time
+	EOLStatus string
+	// RunsAsRoot er om imaget kjører som root uten USER, nil når katalogen ikke vet
+	RunsAsRoot *bool
+}
+
+var (
+	defaultMu      sync.RWMutex
+	defaultCatalog = mustParse(embeddedCatalog)
+)
+
+// Default returnerer katalogen writerne bruker: den innebygde,
+// eller den som er satt med SetDefault.
+func Default() *Catalog {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultCatalog
+}
+
+// SetDefault bytter katalogen Default returnerer, typisk med en override-fil fra Load.
+func SetDefault(cat *Catalog) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultCatalog = cat
+}
+
+func mustParse(data []byte) *Catalog {
+	cat, err := Parse(data)
+	if err != nil {
+		panic("catalog: ugyldig innebygd katalog: " + err.Error())
+	}
+	return cat
+}
+
+// Load leser en override-fil og legger familiene der foran de innebygde,
+// slik at filen vinner ved overlapp.
+func Load(filePath string) (*Catalog, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("kunne ikke lese image-katalog: %w", err)
+	}
+	override, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("ugyldig image-katalog %s: %w", filePath, err)
+	}
+	embedded := mustParse(embeddedCatalog)
+	return &Catalog{families: append(override.families, embedded.families...)}, nil
+}
+
+// Parse leser en katalog i YAML-format.
+func Parse(data []byte) (*Catalog, error) {
+	var raw fileFormat
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	cat := &Catalog{}
+	for _, f := range raw.Families {
+		fam := family{Name: f.Family, Images: f.Images, User: f.User, NonrootImages: f.NonrootImages}
+		switch f.User {
+		case "", UserRoot, UserNonroot:
+		default:
+			return nil, fmt.Errorf("%s: ukjent bruker %q, må være %s eller %s", f.Family, f.User, UserRoot, UserNonroot)
+		}
+		if f.NonrootTag != "" {
+			var err error
+			if fam.NonrootTag, err = regexp.Compile(f.NonrootTag); err != nil {
+				return nil, fmt.Errorf("%s: ugyldig nonroot_tag-mønster: %w", f.Family, err)
+			}
+		}
+		for _, v := range f.Versions {
+			parsed := version{Version: v.Version}
+			var err error
+			if v.Tag != "" {
+				if parsed.Tag, err = regexp.Compile(v.Tag); err != nil {
+					return nil, fmt.Errorf("%s %s: ugyldig tag-mønster: %w", f.Family, v.Version, err)
+				}
+			}
+			if v.Repository != "" {
+				if parsed.Repository, err = regexp.Compile(v.Repository); err != nil {
+					return nil, fmt.Errorf("%s %s: ugyldig repository-mønster: %w", f.Family, v.Version, err)
+				}
+			}
+			if v.EOL != "" {
+				if parsed.EOL, err = time.Parse("2006-01-02", v.EOL); err != nil {
+					return nil, fmt.Errorf("%s %s: ugyldig eol-dato: %w", f.Family, v.Version, err)
+				}
+			}
+			fam.Versions = append(fam.Versions, parsed)
+		}
+		cat.families = append(cat.families, fam)
+	}
+	return cat, nil
+}
+
+// Classify finner familie, versjon og EOL-status for et image på en gitt dato.
+func (c *Catalog) Classify(ref parser.ImageRef, at time.Time) Classification {
+	name := ref.FullName()
+	for _, fam := range c.families {
+		if !matchesAny(fam.Images, name) {
+			continue
+		}
+		result := Classification{Family: fam.Name, EOLStatus: StatusUnknown, RunsAsRoot: fam.runsAsRoot(ref)}
+		for _, v := range fam.Versions {
+			if v.Tag != nil && !v.Tag.MatchString(ref.Tag) {
+				continue
+			}
+			if v.Repository != nil && !v.Repository.MatchString(ref.Repository) {
+				continue
+			}
+			result.Version = v.Version
+			if !v.EOL.IsZero() {
+				eol := v.EOL
+				result.EOLDate = &eol
+				result.EOLStatus = eolStatus(eol, at)
+			}
+			break
+		}
+		return result
+	}
+	return Classification{EOLStatus: StatusUnknown}
+}
+
+// ClassifyStages klassifiserer hver stage. Stages som bygger på en tidligere stage
+// får klassifiseringen til imaget den stagen bygger på.
+func (c *Catalog) ClassifyStages(stages []parser.DockerStageMeta, at time.Time) []Classification {
+	result := make([]Classification, len(stages))
+	byAlias := map[string]int{}
+	for i, s := range stages {
+		if idx, ok := byAlias[s.BaseStage]; ok && s.BaseStage != "" {
+			result[i] = result[idx]
+		} else if ref, err := parser.ParseImageRef(s.BaseImage); err == nil && s.BaseImage != parser.UnresolvedImage {
+			ref.Tag = s.BaseTag
+			result[i] = c.Classify(ref, at)
+		} else {
+			result[i] = Classification{EOLStatus: StatusUnknown}
+		}
+		if s.Alias != "" {
+			byAlias[s.Alias] = i
+		}
+	}
+	return result
+}
+
+// ResolveRunsAsRoot fyller inn RunsAsRoot for stages uten USER når katalogen kjenner
+// standardbrukeren til base image, og oppdaterer FinalRunsAsRoot tilsvarende.
+func (c *Catalog) ResolveRunsAsRoot(features *parser.DockerfileFeatures, stages []parser.DockerStageMeta) {
+	classes := c.ClassifyStages(stages, time.Time{})
+	for i := range stages {
+		if stages[i].RunsAsRoot == nil {
+			stages[i].RunsAsRoot = classes[i].RunsAsRoot
+		}
+		if stages[i].IsFinal {
+			features.FinalRunsAsRoot = stages[i].RunsAsRoot
+		}
+	}
+}
+
+func (f family) runsAsRoot(ref parser.ImageRef) *bool {
+	var root bool
+	switch {
+	case f.NonrootTag != nil && f.NonrootTag.MatchString(ref.Tag), matchesAny(f.NonrootImages, ref.FullName()):
+		root = false
+	case f.User == UserRoot:
+		root = true
+	case f.User == UserNonroot:
+		root = false
+	default:
+		return nil
+	}
+	return &root
+}
+
+func eolStatus(eol, at time.Time) string {
+	switch {
+	case !at.Before(eol):
+		return StatusEOL
+	case eol.Sub(at) <= NearEOLWindow:
+		return StatusNearEOL
+	default:
+		return StatusSupported
+	}
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
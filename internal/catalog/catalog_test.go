@@ -0,0 +1,117 @@
+package catalog_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/catalog"
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+)
+
+func TestCatalog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Base image-katalog")
+}
+
+func ref(s string) parser.ImageRef {
+	r, err := parser.ParseImageRef(s)
+	Expect(err).NotTo(HaveOccurred())
+	return r
+}
+
+var _ = Describe("Classify", func() {
+	at := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	DescribeTable("klassifiserer kjente images",
+		func(image, family, version, status string) {
+			result := catalog.Default().Classify(ref(image), at)
+			Expect(result.Family).To(Equal(family))
+			Expect(result.Version).To(Equal(version))
+			Expect(result.EOLStatus).To(Equal(status))
+		},
+		Entry("debian 11 etter LTS", "debian:bullseye-slim", "debian", "11", catalog.StatusEOL),
+		Entry("python 3.10 nær EOL", "python:3.10-slim", "python", "3.10", catalog.StatusNearEOL),
+		Entry("temurin 21", "eclipse-temurin:21-jre-alpine", "temurin", "21", catalog.StatusSupported),
+		Entry("node 18 via kodenavn", "node:hydrogen-alpine", "node", "18", catalog.StatusEOL),
+		Entry("distroless med versjon i navnet", "gcr.io/distroless/java21-debian12:nonroot", "distroless", "debian12", catalog.StatusSupported),
+		Entry("chainguard uten EOL", "cgr.dev/chainguard/static:latest", "chainguard", "", catalog.StatusUnknown),
+		Entry("kjent familie med ukjent tag", "node:lts", "node", "", catalog.StatusUnknown),
+		Entry("ukjent image", "ghcr.io/navikt/baseimages/temurin:21", "", "", catalog.StatusUnknown),
+	)
+
+	It("gir EOL-dato", func() {
+		result := catalog.Default().Classify(ref("ubuntu:20.04"), at)
+		Expect(result.EOLDate).NotTo(BeNil())
+		Expect(result.EOLDate.Format("2006-01-02")).To(Equal("2025-05-31"))
+	})
+})
+
+var _ = Describe("Load", func() {
+	It("lar override-filen vinne over den innebygde katalogen", func() {
+		dir := GinkgoT().TempDir()
+		file := filepath.Join(dir, "catalog.yaml")
+		Expect(os.WriteFile(file, []byte(`families:
+  - family: nav-temurin
+    images: ["ghcr.io/navikt/baseimages/*"]
+    versions:
+      - {tag: '^21', version: "21", eol: 2029-12-31}
+  - family: internal-python
+    images: ["docker.io/library/python"]
+`), 0o600)).To(Succeed())
+
+		cat, err := catalog.Load(file)
+		Expect(err).NotTo(HaveOccurred())
+
+		at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		Expect(cat.Classify(ref("ghcr.io/navikt/baseimages/temurin:21"), at).Family).To(Equal("nav-temurin"))
+		Expect(cat.Classify(ref("python:3.12"), at).Family).To(Equal("internal-python"))
+		Expect(cat.Classify(ref("debian:12"), at).Family).To(Equal("debian"))
+	})
+
+	It("feiler på ugyldig mønster", func() {
+		_, err := catalog.Parse([]byte(`families: [{family: x, images: ["a"], versions: [{tag: "(", version: "1"}]}]`))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ClassifyStages", func() {
+	It("arver klassifisering fra stagen en stage bygger på", func() {
+		_, stages := parser.ParseDockerfile("FROM node:18 AS base\nFROM golang:1.25 AS build\nFROM base")
+		result := catalog.Default().ClassifyStages(stages, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC))
+		Expect(result).To(HaveLen(3))
+		Expect(result[1].Family).To(Equal("golang"))
+		Expect(result[2].Family).To(Equal("node"))
+		Expect(result[2].EOLStatus).To(Equal(catalog.StatusEOL))
+	})
+})
+
+var _ = Describe("ResolveRunsAsRoot", func() {
+	resolve := func(content string) *bool {
+		features, stages := parser.ParseDockerfile(content)
+		catalog.Default().ResolveRunsAsRoot(&features, stages)
+		return features.FinalRunsAsRoot
+	}
+
+	It("bruker standardbrukeren til kjente images når USER mangler", func() {
+		Expect(resolve("FROM golang:1.22 AS build\nFROM gcr.io/distroless/static:nonroot\nCOPY --from=build /app /app")).To(HaveValue(BeFalse()))
+		Expect(resolve("FROM gcr.io/distroless/static-debian12")).To(HaveValue(BeTrue()))
+		Expect(resolve("FROM cgr.dev/chainguard/static")).To(HaveValue(BeFalse()))
+		Expect(resolve("FROM nginxinc/nginx-unprivileged:1.27")).To(HaveValue(BeFalse()))
+		Expect(resolve("FROM node:22 AS base\nFROM base")).To(HaveValue(BeTrue()))
+	})
+
+	It("lar USER i Dockerfilen vinne og ukjente images være ukjent", func() {
+		Expect(resolve("FROM node:22\nUSER node")).To(HaveValue(BeFalse()))
+		Expect(resolve("FROM ghcr.io/navikt/baseimages/temurin:21")).To(BeNil())
+	})
+
+	It("feiler på ukjent bruker", func() {
+		_, err := catalog.Parse([]byte(`families: [{family: x, images: ["a"], user: admin}]`))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,156 @@
+package codeowners
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Stedene GitHub leter etter CODEOWNERS, i den rekkefølgen de brukes
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+var (
+	userPattern  = regexp.MustCompile(`^@[A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?$`)
+	teamPattern  = regexp.MustCompile(`^@([A-Za-z0-9][A-Za-z0-9-]*)/([A-Za-z0-9_.-]+)$`)
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// Rule er én linje i CODEOWNERS. Linjer med Error brukes ikke, slik GitHub også hopper over dem.
+type Rule struct {
+	Line    int
+	Pattern string
+	Owners  []string
+	Error   string
+
+	matcher *regexp.Regexp
+}
+
+// Parse leser CODEOWNERS. Kommentarer og tomme linjer hoppes over.
+func Parse(content string) []Rule {
+	var rules []Rule
+	for i, line := range strings.Split(content, "\n") {
+		line = stripComment(line)
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		rule := Rule{Line: i + 1, Pattern: fields[0], Owners: fields[1:]}
+		rule.Error = validate(rule)
+		if rule.Error == "" {
+			rule.matcher = compile(rule.Pattern)
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func validate(rule Rule) string {
+	switch {
+	case strings.HasPrefix(rule.Pattern, "!"):
+		return "negerte mønstre støttes ikke"
+	case strings.ContainsAny(rule.Pattern, "[]"):
+		return "tegnklasser støttes ikke"
+	}
+	for _, owner := range rule.Owners {
+		if !userPattern.MatchString(owner) && !teamPattern.MatchString(owner) && !emailPattern.MatchString(owner) {
+			return fmt.Sprintf("ugyldig eier %q", owner)
+		}
+	}
+	return ""
+}
+
+// stripComment fjerner alt etter en # som ikke er escapet.
+func stripComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '#' && (i == 0 || line[i-1] != '\\') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// compile gjør et CODEOWNERS-mønster (gitignore-syntaks) om til et regulært uttrykk.
+// Mønstre med / foran eller i midten er forankret i roten, ellers matcher de på alle nivåer.
+// Et mønster som matcher en mappe, matcher også alt under den, bortsett fra mønstre som slutter på /*.
+func compile(pattern string) *regexp.Regexp {
+	p := strings.ReplaceAll(pattern, `\#`, "#")
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(p[i])))
+		}
+	}
+	switch {
+	case dirOnly:
+		b.WriteString("/.*$")
+	case strings.HasSuffix(p, "/*") && !strings.HasSuffix(p, "**"):
+		// docs/* eier filene rett under docs, men ikke undermapper
+		b.WriteString("$")
+	default:
+		b.WriteString("(?:/.*)?$")
+	}
+	return regexp.MustCompile(b.String())
+}
+
+// Match finner regelen som gjelder for en sti. Siste treff vinner, som hos GitHub.
+// Mapper sjekkes som om de inneholdt en fil, så både "apps/" og "apps/*" eier mappen apps.
+func Match(rules []Rule, path string, isDir bool) *Rule {
+	path = strings.TrimPrefix(path, "/")
+	if isDir {
+		path = strings.TrimSuffix(path, "/") + "/\x00"
+	}
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].matcher != nil && rules[i].matcher.MatchString(path) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// Directory er teamene og brukerne i organisasjonen, nøklet på navn i små bokstaver.
+// Teams er slugs uten org, f.eks. "plattform".
+type Directory struct {
+	Org   string
+	Teams map[string]bool
+	Users map[string]bool
+}
+
+// UnknownOwners returnerer eierne som ikke er team eller brukere i organisasjonen.
+// E-postadresser kan ikke sjekkes og regnes som gyldige.
+func (d *Directory) UnknownOwners(owners []string) []string {
+	var unknown []string
+	for _, owner := range owners {
+		if m := teamPattern.FindStringSubmatch(owner); m != nil {
+			if !strings.EqualFold(m[1], d.Org) || !d.Teams[strings.ToLower(m[2])] {
+				unknown = append(unknown, owner)
+			}
+			continue
+		}
+		if userPattern.MatchString(owner) && !d.Users[strings.ToLower(strings.TrimPrefix(owner, "@"))] {
+			unknown = append(unknown, owner)
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,82 @@
+package codeowners_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/codeowners"
+)
+
+func TestCodeowners(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CODEOWNERS")
+}
+
+const content = `# Standardeier
+*                 @navikt/plattform
+
+/apps/            @navikt/team-a @kari
+*.go              @navikt/go-gjengen   # inline-kommentar
+docs/*            docs@nav.no
+/build/**/logs    @ola
+!/hemmelig        @navikt/plattform
+/ugyldig          navikt/team-b
+/uten-eier
+`
+
+var _ = Describe("Parse", func() {
+	It("gir regler med linjenummer og rapporterer ugyldige linjer", func() {
+		rules := codeowners.Parse(content)
+		Expect(rules).To(HaveLen(8))
+		Expect(rules[0].Line).To(Equal(2))
+		Expect(rules[0].Owners).To(Equal([]string{"@navikt/plattform"}))
+		Expect(rules[2].Owners).To(Equal([]string{"@navikt/go-gjengen"}))
+		Expect(rules[5].Error).To(ContainSubstring("negerte"))
+		Expect(rules[6].Error).To(ContainSubstring("navikt/team-b"))
+		Expect(rules[7].Error).To(BeEmpty())
+		Expect(rules[7].Owners).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Match", func() {
+	rules := codeowners.Parse(content)
+
+	DescribeTable("siste treff vinner",
+		func(path string, isDir bool, line int) {
+			rule := codeowners.Match(rules, path, isDir)
+			if line == 0 {
+				Expect(rule).To(BeNil())
+				return
+			}
+			Expect(rule).NotTo(BeNil())
+			Expect(rule.Line).To(Equal(line))
+		},
+		Entry("fallback", "README.md", false, 2),
+		Entry("mappe med /", "apps/api/Dockerfile", false, 4),
+		Entry("mappen selv", "apps", true, 4),
+		Entry("uforankret filendelse", "apps/api/main.go", false, 5),
+		Entry("docs/* tar filer rett under", "docs/index.md", false, 6),
+		Entry("men ikke undermapper", "docs/api/index.md", false, 2),
+		Entry("** i midten", "build/x/y/logs/out.txt", false, 7),
+		Entry("ugyldige linjer brukes ikke", "ugyldig/fil", false, 2),
+		Entry("regel uten eier", "uten-eier/fil", false, 10),
+	)
+
+	It("gir ingen treff uten regler", func() {
+		Expect(codeowners.Match(nil, "Dockerfile", false)).To(BeNil())
+	})
+})
+
+var _ = Describe("Directory.UnknownOwners", func() {
+	It("sjekker team og brukere mot organisasjonen", func() {
+		dir := codeowners.Directory{
+			Org:   "navikt",
+			Teams: map[string]bool{"team-a": true},
+			Users: map[string]bool{"kari": true},
+		}
+		Expect(dir.UnknownOwners([]string{"@navikt/Team-A", "@Kari", "@ola", "@navikt/borte", "@andre/team-a", "docs@nav.no"})).
+			To(Equal([]string{"@ola", "@navikt/borte", "@andre/team-a"}))
+	})
+})
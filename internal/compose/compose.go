@@ -0,0 +1,287 @@
+package compose
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+)
+
+const (
+	MountBind   = "bind"
+	MountVolume = "volume"
+	MountTmpfs  = "tmpfs"
+	MountSecret = "secret"
+)
+
+// docker-compose.yml, compose.yaml og varianter som docker-compose.test.yml og compose.override.yaml
+var composeFilePattern = regexp.MustCompile(`^(docker-)?compose(\.[a-z0-9_-]+)*\.ya?ml$`)
+
+// IsComposeFile sier om et filnavn (i små bokstaver) er en Compose-fil.
+func IsComposeFile(lowerName string) bool {
+	return composeFilePattern.MatchString(lowerName)
+}
+
+type file struct {
+	Services map[string]service `yaml:"services"`
+	Secrets  map[string]struct {
+		File        string `yaml:"file"`
+		Environment string `yaml:"environment"`
+	} `yaml:"secrets"`
+}
+
+// Feltene som kan ha både kort og lang form leses som yaml.Node
+type service struct {
+	Image       string      `yaml:"image"`
+	Build       yaml.Node   `yaml:"build"`
+	Ports       []yaml.Node `yaml:"ports"`
+	Privileged  bool        `yaml:"privileged"`
+	NetworkMode string      `yaml:"network_mode"`
+	EnvFile     yaml.Node   `yaml:"env_file"`
+	Volumes     []yaml.Node `yaml:"volumes"`
+	Secrets     []yaml.Node `yaml:"secrets"`
+}
+
+// Parse leser alle Compose-filene blant de hentede filene.
+func Parse(files map[string][]models.FileEntry) []models.ComposeService {
+	keys := make([]string, 0, len(files))
+	for k := range files {
+		if IsComposeFile(path.Base(k)) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var result []models.ComposeService
+	for _, key := range keys {
+		for _, f := range files[key] {
+			services, err := ParseFile(f.Path, f.Content)
+			if err != nil {
+				continue
+			}
+			result = append(result, services...)
+		}
+	}
+	return result
+}
+
+// ParseFile leser tjenestene i én Compose-fil, sortert på navn.
+func ParseFile(filePath, content string) ([]models.ComposeService, error) {
+	var f file
+	if err := yaml.Unmarshal([]byte(content), &f); err != nil {
+		return nil, fmt.Errorf("ugyldig compose-fil %s: %w", filePath, err)
+	}
+
+	names := make([]string, 0, len(f.Services))
+	for name := range f.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []models.ComposeService
+	for _, name := range names {
+		svc := f.Services[name]
+		s := models.ComposeService{
+			Path:        filePath,
+			Service:     name,
+			Privileged:  svc.Privileged,
+			NetworkMode: svc.NetworkMode,
+		}
+		if svc.Image != "" {
+			resolveImage(&s, svc.Image)
+		}
+		s.BuildContext, s.Dockerfile = build(&svc.Build)
+		for i := range svc.Ports {
+			if p := port(&svc.Ports[i]); p != "" {
+				s.Ports = append(s.Ports, p)
+			}
+		}
+		s.EnvFiles = envFiles(&svc.EnvFile)
+		for i := range svc.Volumes {
+			if v, ok := volume(&svc.Volumes[i]); ok {
+				s.Volumes = append(s.Volumes, v)
+			}
+		}
+		for i := range svc.Secrets {
+			v := secret(&svc.Secrets[i])
+			if def, ok := f.Secrets[v.Source]; ok {
+				// Hemmeligheten er definert på toppnivå med fil eller miljøvariabel som kilde
+				if def.File != "" {
+					v.Source = def.File
+				} else if def.Environment != "" {
+					v.Source = "$" + def.Environment
+				}
+			}
+			s.Volumes = append(s.Volumes, v)
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// resolveImage tolker image på samme måte som FROM i Dockerfiles. Variabler uten default gir unresolved.
+func resolveImage(s *models.ComposeService, raw string) {
+	expanded, resolved := parser.ExpandImage(raw)
+	if !resolved {
+		s.Image = parser.UnresolvedImage
+		s.RawImage = raw
+		return
+	}
+	ref, err := parser.ParseImageRef(expanded)
+	if err != nil {
+		s.Image = strings.ToLower(expanded)
+		return
+	}
+	s.Image = ref.Name
+	s.Tag = ref.Tag
+	s.Registry = ref.Registry
+	s.IsDigestPinned = ref.IsDigestPinned()
+	s.Digest = ref.Digest
+	// Uten tag og digest bruker Docker "latest"
+	if ref.Tag == "" && !ref.IsDigestPinned() {
+		s.Tag = "latest"
+	}
+}
+
+// build håndterer både build: ./dir og build: {context, dockerfile}
+func build(node *yaml.Node) (context, dockerfile string) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, ""
+	case yaml.MappingNode:
+		var b struct {
+			Context    string `yaml:"context"`
+			Dockerfile string `yaml:"dockerfile"`
+		}
+		if err := node.Decode(&b); err == nil {
+			if b.Context == "" {
+				b.Context = "."
+			}
+			return b.Context, b.Dockerfile
+		}
+	}
+	return "", ""
+}
+
+// port gir kort form som "127.0.0.1:8080:80/tcp", også for porter skrevet i lang form
+func port(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	var p struct {
+		Target    string `yaml:"target"`
+		Published string `yaml:"published"`
+		HostIP    string `yaml:"host_ip"`
+		Protocol  string `yaml:"protocol"`
+	}
+	if err := node.Decode(&p); err != nil || p.Target == "" {
+		return ""
+	}
+	result := p.Target
+	if p.Published != "" {
+		result = p.Published + ":" + result
+	}
+	if p.HostIP != "" {
+		result = p.HostIP + ":" + result
+	}
+	if p.Protocol != "" {
+		result += "/" + p.Protocol
+	}
+	return result
+}
+
+// envFiles håndterer streng, liste med strenger og liste med {path, required}
+func envFiles(node *yaml.Node) []string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return []string{node.Value}
+	case yaml.SequenceNode:
+		var result []string
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				result = append(result, item.Value)
+				continue
+			}
+			var e struct {
+				Path string `yaml:"path"`
+			}
+			if err := item.Decode(&e); err == nil && e.Path != "" {
+				result = append(result, e.Path)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// volume håndterer kort form (kilde:mål[:modus]) og lang form ({type, source, target, read_only}).
+// Kilder som ikke er en sti er navngitte volumer; bare mål gir et anonymt volum.
+func volume(node *yaml.Node) (models.ComposeVolume, bool) {
+	if node.Kind == yaml.MappingNode {
+		var v struct {
+			Type     string `yaml:"type"`
+			Source   string `yaml:"source"`
+			Target   string `yaml:"target"`
+			ReadOnly bool   `yaml:"read_only"`
+		}
+		if err := node.Decode(&v); err != nil || v.Target == "" {
+			return models.ComposeVolume{}, false
+		}
+		if v.Type == "" {
+			v.Type = MountVolume
+		}
+		return models.ComposeVolume{Type: v.Type, Source: v.Source, Target: v.Target, ReadOnly: v.ReadOnly}, true
+	}
+
+	parts := strings.Split(node.Value, ":")
+	if len(parts) == 1 {
+		return models.ComposeVolume{Type: MountVolume, Target: parts[0]}, parts[0] != ""
+	}
+	v := models.ComposeVolume{Type: MountVolume, Source: parts[0], Target: parts[1]}
+	if isHostPath(v.Source) {
+		v.Type = MountBind
+	}
+	if len(parts) > 2 {
+		for _, opt := range strings.Split(parts[2], ",") {
+			if opt == "ro" {
+				v.ReadOnly = true
+			}
+		}
+	}
+	return v, true
+}
+
+// secret håndterer kort form (navn) og lang form ({source, target}). Secrets monteres alltid read-only.
+func secret(node *yaml.Node) models.ComposeVolume {
+	v := models.ComposeVolume{Type: MountSecret, ReadOnly: true}
+	if node.Kind == yaml.MappingNode {
+		var s struct {
+			Source string `yaml:"source"`
+			Target string `yaml:"target"`
+		}
+		if err := node.Decode(&s); err == nil {
+			v.Source, v.Target = s.Source, s.Target
+		}
+	} else {
+		v.Source = node.Value
+	}
+	// Standard monteringspunkt er /run/secrets/<navn>, relative mål legges under samme mappe
+	switch {
+	case v.Target == "":
+		v.Target = "/run/secrets/" + v.Source
+	case !strings.HasPrefix(v.Target, "/"):
+		v.Target = "/run/secrets/" + v.Target
+	}
+	return v
+}
+
+func isHostPath(source string) bool {
+	return strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~") ||
+		strings.HasPrefix(source, "$")
+}
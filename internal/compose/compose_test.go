@@ -0,0 +1,134 @@
+package compose_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/compose"
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+)
+
+func TestCompose(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Docker Compose")
+}
+
+var _ = Describe("ParseFile", func() {
+	It("leser tjenester med image, build, porter, volumer, env-filer og secrets", func() {
+		services, err := compose.ParseFile("docker-compose.yml", `x-defaults: &defaults
+  env_file: .env
+
+services:
+  db:
+    <<: *defaults
+    image: postgres:${PG_VERSION:-16}
+    ports:
+      - "127.0.0.1:5432:5432"
+    volumes:
+      - pgdata:/var/lib/postgresql/data
+      - ./init.sql:/docker-entrypoint-initdb.d/init.sql:ro
+    secrets:
+      - db_password
+  app:
+    build:
+      context: ./app
+      dockerfile: Dockerfile.dev
+    privileged: true
+    network_mode: host
+    env_file:
+      - path: .env.local
+        required: false
+    ports:
+      - target: 8080
+        published: "80"
+        protocol: tcp
+    volumes:
+      - type: bind
+        source: /var/run/docker.sock
+        target: /var/run/docker.sock
+      - /tmp/cache
+    secrets:
+      - source: api_key
+        target: key.txt
+  proxy:
+    image: ghcr.io/navikt/proxy@sha256:abc123
+  tool:
+    image: ${TOOL_IMAGE}
+
+secrets:
+  db_password:
+    file: ./secrets/db_password.txt
+  api_key:
+    environment: API_KEY
+
+volumes:
+  pgdata:
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(services).To(HaveLen(4))
+
+		app, db, proxy, tool := services[0], services[1], services[2], services[3]
+
+		Expect(app.Service).To(Equal("app"))
+		Expect(app.Image).To(BeEmpty())
+		Expect(app.BuildContext).To(Equal("./app"))
+		Expect(app.Dockerfile).To(Equal("Dockerfile.dev"))
+		Expect(app.Privileged).To(BeTrue())
+		Expect(app.NetworkMode).To(Equal("host"))
+		Expect(app.EnvFiles).To(Equal([]string{".env.local"}))
+		Expect(app.Ports).To(Equal([]string{"80:8080/tcp"}))
+		Expect(app.Volumes).To(Equal([]models.ComposeVolume{
+			{Type: compose.MountBind, Source: "/var/run/docker.sock", Target: "/var/run/docker.sock"},
+			{Type: compose.MountVolume, Target: "/tmp/cache"},
+			{Type: compose.MountSecret, Source: "$API_KEY", Target: "/run/secrets/key.txt", ReadOnly: true},
+		}))
+
+		Expect(db.Image).To(Equal("postgres"))
+		Expect(db.Tag).To(Equal("16"))
+		Expect(db.Registry).To(Equal(parser.DockerHubRegistry))
+		Expect(db.EnvFiles).To(Equal([]string{".env"}))
+		Expect(db.Ports).To(Equal([]string{"127.0.0.1:5432:5432"}))
+		Expect(db.Volumes).To(Equal([]models.ComposeVolume{
+			{Type: compose.MountVolume, Source: "pgdata", Target: "/var/lib/postgresql/data"},
+			{Type: compose.MountBind, Source: "./init.sql", Target: "/docker-entrypoint-initdb.d/init.sql", ReadOnly: true},
+			{Type: compose.MountSecret, Source: "./secrets/db_password.txt", Target: "/run/secrets/db_password", ReadOnly: true},
+		}))
+
+		Expect(proxy.Image).To(Equal("ghcr.io/navikt/proxy"))
+		Expect(proxy.IsDigestPinned).To(BeTrue())
+		Expect(proxy.Tag).To(BeEmpty())
+
+		Expect(tool.Image).To(Equal(parser.UnresolvedImage))
+		Expect(tool.RawImage).To(Equal("${TOOL_IMAGE}"))
+	})
+
+	It("gir feil for ugyldig YAML", func() {
+		_, err := compose.ParseFile("compose.yaml", "services: [")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Parse", func() {
+	It("leser bare Compose-filer blant de hentede filene", func() {
+		files := map[string][]models.FileEntry{
+			"compose.yaml":            {{Path: "compose.yaml", Content: "services:\n  web:\n    image: nginx\n"}},
+			"docker-compose.test.yml": {{Path: "docker-compose.test.yml", Content: "services:\n  test:\n    image: alpine:3.20\n"}},
+			"dockerfile":              {{Path: "Dockerfile", Content: "FROM alpine"}},
+		}
+		services := compose.Parse(files)
+		Expect(services).To(HaveLen(2))
+		Expect(services[0].Service).To(Equal("web"))
+		Expect(services[0].Tag).To(Equal("latest"))
+		Expect(services[1].Path).To(Equal("docker-compose.test.yml"))
+	})
+
+	It("kjenner igjen Compose-filnavn", func() {
+		Expect(compose.IsComposeFile("docker-compose.override.yaml")).To(BeTrue())
+		Expect(compose.IsComposeFile("compose.yml")).To(BeTrue())
+		Expect(compose.IsComposeFile("docker-compose-old.yml")).To(BeFalse())
+		Expect(compose.IsComposeFile("composer.json")).To(BeFalse())
+	})
+})
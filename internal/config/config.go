@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type StorageType string
@@ -25,8 +26,21 @@ type Config struct {
 	BQTable       string
 	BQCredentials string // Valgfritt hvis GCP auth skjer automatisk
 	Parallelism   int    // maks antall samtidige repo-prosesser
+	MaxFileBytes  int64  // maks størrelse på avkortede filer som hentes via contents-API-et
+
+	LintEnable  []string // Dockerfile-regler som skrus på i tillegg til standardreglene
+	LintDisable []string // Dockerfile-regler som skrus av
+
+	ImageCatalogPath string // valgfri YAML-fil som overstyrer den innebygde base image-katalogen
+
+	RegistryLookup    bool     // slå opp base images i registryet (digest og alder)
+	RegistryAuth      string   // vert=bruker:passord eller vert=token, kommaseparert
+	RegistryPlainHTTP []string // registry-verter uten TLS, f.eks. localhost:5000
 }
 
+// DefaultMaxFileBytes brukes når REPOSNUSERN_MAX_FILE_BYTES ikke er satt.
+const DefaultMaxFileBytes = 1 << 20
+
 // NewConfig oppretter en ny konfigurasjon basert på miljøvariabler
 func NewConfig() (Config, error) {
 	storage := StorageType(os.Getenv("REPO_STORAGE"))
@@ -40,6 +54,15 @@ func NewConfig() (Config, error) {
 		}
 	}
 
+	maxFileBytes := int64(DefaultMaxFileBytes)
+	if mStr := os.Getenv("REPOSNUSERN_MAX_FILE_BYTES"); mStr != "" {
+		if m, err := strconv.ParseInt(mStr, 10, 64); err == nil && m > 0 {
+			maxFileBytes = m
+		} else {
+			return Config{}, errors.New("REPOSNUSERN_MAX_FILE_BYTES må være et positivt heltall")
+		}
+	}
+
 	cfg := Config{
 		Org:           os.Getenv("ORG"),
 		Token:         os.Getenv("GITHUB_TOKEN"),
@@ -52,6 +75,15 @@ func NewConfig() (Config, error) {
 		BQTable:       os.Getenv("BQ_TABLE"),
 		BQCredentials: os.Getenv("BQ_CREDENTIALS"),
 		Parallelism:   parallelism,
+		MaxFileBytes:  maxFileBytes,
+		LintEnable:    splitList(os.Getenv("REPOSNUSERN_LINT_ENABLE")),
+		LintDisable:   splitList(os.Getenv("REPOSNUSERN_LINT_DISABLE")),
+
+		ImageCatalogPath: os.Getenv("REPOSNUSERN_IMAGE_CATALOG"),
+
+		RegistryLookup:    os.Getenv("REPOSNUSERN_REGISTRY_LOOKUP") == "true",
+		RegistryAuth:      os.Getenv("REPOSNUSERN_REGISTRY_AUTH"),
+		RegistryPlainHTTP: splitList(os.Getenv("REPOSNUSERN_REGISTRY_PLAIN_HTTP")),
 	}
 
 	if cfg.Org == "" {
@@ -79,3 +111,14 @@ func NewConfig() (Config, error) {
 
 	return cfg, nil
 }
+
+// splitList deler en kommaseparert miljøvariabel og fjerner tomme elementer
+func splitList(value string) []string {
+	var result []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
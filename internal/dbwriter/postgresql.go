@@ -8,9 +8,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jonmartinstorm/reposnusern/internal/catalog"
+	"github.com/jonmartinstorm/reposnusern/internal/lifecycle"
+	"github.com/jonmartinstorm/reposnusern/internal/linguist"
 	"github.com/jonmartinstorm/reposnusern/internal/models"
 	"github.com/jonmartinstorm/reposnusern/internal/parser"
+	"github.com/jonmartinstorm/reposnusern/internal/similarity"
 	"github.com/jonmartinstorm/reposnusern/internal/storage"
+	"github.com/jonmartinstorm/reposnusern/internal/workflow"
 )
 
 type PostgresWriter struct {
@@ -76,6 +81,13 @@ func (p *PostgresWriter) ImportRepo(ctx context.Context, entry models.RepoEntry,
 		HasSecurityMd: r.Security["has_security_md"],
 		HasDependabot: r.Security["has_dependabot"],
 		HasCodeql:     r.Security["has_codeql"],
+
+		ParentFullName:   sql.NullString{String: r.Lineage.ParentFullName, Valid: r.Lineage.ParentFullName != ""},
+		ParentInOrg:      sql.NullBool{Bool: r.Lineage.ParentInOrg, Valid: r.Lineage.ParentFullName != ""},
+		TemplateFullName: sql.NullString{String: r.Lineage.TemplateFullName, Valid: r.Lineage.TemplateFullName != ""},
+		TemplateInOrg:    sql.NullBool{Bool: r.Lineage.TemplateInOrg, Valid: r.Lineage.TemplateFullName != ""},
+		ForkAheadBy:      nullInt32(r.Lineage.AheadBy),
+		ForkBehindBy:     nullInt32(r.Lineage.BehindBy),
 	}
 
 	if err := queries.InsertOrUpdateRepo(ctx, repo); err != nil {
@@ -87,8 +99,24 @@ func (p *PostgresWriter) ImportRepo(ctx context.Context, entry models.RepoEntry,
 
 	insertLanguages(ctx, queries, id, name, entry.Languages, snapshotDate)
 	insertDockerfiles(ctx, queries, id, name, entry.Files, snapshotDate)
+	insertDockerfileFindings(ctx, queries, id, name, entry.DockerfileFindings, snapshotDate)
+	insertBaseImageDigests(ctx, queries, id, name, entry.BaseImages, snapshotDate)
 	insertCIConfig(ctx, queries, id, name, entry.CIConfig, snapshotDate)
-	insertSBOMPackagesGithub(ctx, queries, id, name, entry.SBOM, snapshotDate)
+	insertWorkflows(ctx, queries, id, name, entry.CIConfig, snapshotDate)
+	insertWorkflowFindings(ctx, queries, id, name, entry.WorkflowFindings, snapshotDate)
+	insertUpdateBots(ctx, queries, id, name, entry.UpdateBots, entry.EcosystemCoverage, snapshotDate)
+	insertCodeowners(ctx, queries, id, name, entry.Codeowners, snapshotDate)
+	insertFrameworks(ctx, queries, id, name, entry.Frameworks, snapshotDate)
+	insertRuntimeVersions(ctx, queries, id, name, entry.RuntimeVersions, snapshotDate)
+	insertDirectDependencies(ctx, queries, id, name, entry.Dependencies, snapshotDate)
+	insertDeployManifests(ctx, queries, id, name, entry.DeployManifests, snapshotDate)
+	insertIaCModules(ctx, queries, id, name, entry.IaCModules, snapshotDate)
+	insertComposeServices(ctx, queries, id, name, entry.ComposeServices, snapshotDate)
+	insertReadmeMetrics(ctx, queries, id, name, entry.ReadmeMetrics, snapshotDate)
+	insertFileFingerprints(ctx, queries, id, name, entry.FileFingerprints, snapshotDate)
+	insertSecretFindings(ctx, queries, id, name, entry.SecretFindings, snapshotDate)
+	insertSBOMPackagesGithub(ctx, queries, id, name, entry.SBOM, entry.SBOMSource, snapshotDate)
+	insertSkippedFiles(ctx, queries, id, name, entry.SkippedFiles, snapshotDate)
 
 	if err := tx.Commit(); err != nil {
 		slog.Error("Commit-feil – ruller tilbake", "repo", name, "error", err)
@@ -98,13 +126,104 @@ func (p *PostgresWriter) ImportRepo(ctx context.Context, entry models.RepoEntry,
 	return nil
 }
 
+// PreviousRepoStates henter repoene i siste snapshot før snapshotTime, også arkiverte repos som ble hoppet over.
+func (p *PostgresWriter) PreviousRepoStates(ctx context.Context, snapshotTime time.Time) ([]lifecycle.RepoState, error) {
+	snapshotDate := snapshotTime.Truncate(24 * time.Hour)
+	queries := storage.New(p.DB)
+
+	rows, err := queries.ListReposInPreviousSnapshot(ctx, snapshotDate)
+	if err != nil {
+		return nil, fmt.Errorf("hent forrige snapshot: %w", err)
+	}
+	skipped, err := queries.ListSkippedArchivedReposInPreviousSnapshot(ctx, snapshotDate)
+	if err != nil {
+		return nil, fmt.Errorf("hent arkiverte repos i forrige snapshot: %w", err)
+	}
+
+	states := make([]lifecycle.RepoState, 0, len(rows)+len(skipped))
+	for _, r := range rows {
+		states = append(states, lifecycle.RepoState{ID: r.ID, FullName: r.FullName, Archived: r.Archived})
+	}
+	for _, r := range skipped {
+		states = append(states, lifecycle.RepoState{ID: r.RepoID, FullName: r.FullName, Archived: true})
+	}
+	return states, nil
+}
+
+// ImportSkippedArchivedRepos lagrer arkiverte repos som ikke ble hentet, så neste kjøring kan se om de er gjenåpnet.
+func (p *PostgresWriter) ImportSkippedArchivedRepos(ctx context.Context, repos []lifecycle.RepoState, snapshotTime time.Time) error {
+	snapshotDate := snapshotTime.Truncate(24 * time.Hour)
+	queries := storage.New(p.DB)
+
+	for _, r := range repos {
+		err := queries.InsertOrUpdateSkippedArchivedRepo(ctx, storage.InsertOrUpdateSkippedArchivedRepoParams{
+			RepoID:     r.ID,
+			HentetDato: snapshotDate,
+			FullName:   r.FullName,
+		})
+		if err != nil {
+			return fmt.Errorf("lagre arkivert repo %d: %w", r.ID, err)
+		}
+	}
+	return nil
+}
+
+func (p *PostgresWriter) ImportLifecycleEvents(ctx context.Context, events []lifecycle.Event, snapshotTime time.Time) error {
+	snapshotDate := snapshotTime.Truncate(24 * time.Hour)
+	queries := storage.New(p.DB)
+
+	for _, e := range events {
+		err := queries.InsertOrUpdateLifecycleEvent(ctx, storage.InsertOrUpdateLifecycleEventParams{
+			RepoID:      e.RepoID,
+			HentetDato:  snapshotDate,
+			EventType:   e.Type,
+			OldFullName: sql.NullString{String: e.OldFullName, Valid: e.OldFullName != ""},
+			NewFullName: sql.NullString{String: e.NewFullName, Valid: e.NewFullName != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("lagre livsløpshendelse for repo %d: %w", e.RepoID, err)
+		}
+	}
+	return nil
+}
+
+// ImportFileLineage lagrer hvilke Dockerfiles og workflows som deler slekt på tvers av repoene.
+func (p *PostgresWriter) ImportFileLineage(ctx context.Context, members []similarity.Member, snapshotTime time.Time) error {
+	snapshotDate := snapshotTime.Truncate(24 * time.Hour)
+	queries := storage.New(p.DB)
+
+	for _, m := range members {
+		err := queries.InsertOrUpdateFileLineage(ctx, storage.InsertOrUpdateFileLineageParams{
+			RepoID:          m.RepoID,
+			HentetDato:      snapshotDate,
+			Path:            m.Path,
+			FileType:        m.FileType,
+			ContentHash:     m.ContentHash,
+			ClusterID:       m.ClusterID,
+			ClusterSize:     int32(m.ClusterSize),
+			ClusterRepos:    int32(m.ClusterRepos),
+			VariantCount:    int32(m.Variants),
+			IsCanonical:     m.IsCanonical,
+			SimhashDistance: int32(m.Distance),
+			Divergence:      float32(m.Divergence),
+		})
+		if err != nil {
+			return fmt.Errorf("lagre slektskap for %s i repo %d: %w", m.Path, m.RepoID, err)
+		}
+	}
+	return nil
+}
+
 func insertLanguages(ctx context.Context, queries *storage.Queries, repoID int64, name string, langs map[string]int, snapshotDate time.Time) {
+	shares := linguist.Shares(langs)
 	for lang, size := range langs {
 		err := queries.InsertOrUpdateRepoLanguage(ctx, storage.InsertOrUpdateRepoLanguageParams{
-			RepoID:     repoID,
-			HentetDato: snapshotDate,
-			Language:   lang,
-			Bytes:      int64(size),
+			RepoID:       repoID,
+			HentetDato:   snapshotDate,
+			Language:     lang,
+			Bytes:        int64(size),
+			Share:        float32(shares[lang]),
+			LanguageType: linguist.Classify(lang),
 		})
 		if err != nil {
 			slog.Warn("Språkfeil", "repo", name, "language", lang, "error", err)
@@ -125,7 +244,8 @@ func insertDockerfiles(
 			continue
 		}
 		for _, f := range fileEntries {
-			features, _ := parser.ParseDockerfile(f.Content)
+			features, stages := parser.ParseDockerfile(f.Content)
+			catalog.Default().ResolveRunsAsRoot(&features, stages)
 			_, err := queries.InsertOrUpdateDockerfile(ctx, storage.InsertOrUpdateDockerfileParams{
 				RepoID:               repoID,
 				HentetDato:           snapshotDate,
@@ -134,6 +254,9 @@ func insertDockerfiles(
 				Content:              f.Content,
 				BaseImage:            sql.NullString{String: features.BaseImage, Valid: features.BaseImage != ""},
 				BaseTag:              sql.NullString{String: features.BaseTag, Valid: features.BaseTag != ""},
+				RawImage:             sql.NullString{String: features.RawImage, Valid: features.RawImage != ""},
+				Registry:             sql.NullString{String: features.Registry, Valid: features.Registry != ""},
+				IsDigestPinned:       sql.NullBool{Bool: features.IsDigestPinned, Valid: true},
 				UsesLatestTag:        sql.NullBool{Bool: features.UsesLatestTag, Valid: true},
 				HasUserInstruction:   sql.NullBool{Bool: features.HasUserInstruction, Valid: true},
 				HasCopySensitive:     sql.NullBool{Bool: features.HasCopySensitive, Valid: true},
@@ -149,12 +272,101 @@ func insertDockerfiles(
 				HasAptGetClean:       sql.NullBool{Bool: features.HasAptGetClean, Valid: true},
 				WorldWritable:        sql.NullBool{Bool: features.WorldWritable, Valid: true},
 				HasSecretsInEnvOrArg: sql.NullBool{Bool: features.HasSecretsInEnvOrArg, Valid: true},
+
+				FinalStageIndex:         sql.NullInt32{Int32: int32(features.FinalStageIndex), Valid: len(stages) > 0},
+				FinalRunsAsRoot:         nullBool(features.FinalRunsAsRoot),
+				FinalInstallsBuildTools: sql.NullBool{Bool: features.FinalInstallsBuildTools, Valid: len(stages) > 0},
 			})
 			if err != nil {
 				slog.Warn("Dockerfile-feil", "repo", name, "fil", f.Path, "error", err)
 				continue
 			}
 
+			classes := catalog.Default().ClassifyStages(stages, snapshotDate)
+			for i, stage := range stages {
+				class := classes[i]
+				err := queries.InsertOrUpdateDockerfileStage(ctx, storage.InsertOrUpdateDockerfileStageParams{
+					RepoID:         repoID,
+					HentetDato:     snapshotDate,
+					Path:           f.Path,
+					StageIndex:     int32(stage.StageIndex),
+					BaseImage:      stage.BaseImage,
+					BaseTag:        stage.BaseTag,
+					RawImage:       nullString(stage.RawImage),
+					Registry:       stage.Registry,
+					IsDigestPinned: stage.IsDigestPinned,
+
+					Alias:              nullString(stage.Alias),
+					BaseStage:          nullString(stage.BaseStage),
+					UserName:           nullString(stage.User),
+					RunsAsRoot:         nullBool(stage.RunsAsRoot),
+					HasPackageInstalls: stage.HasPackageInstalls,
+					InstallsBuildTools: stage.InstallsBuildTools,
+					InstallsCurlOrWget: stage.InstallsCurlOrWget,
+					CopyFrom:           strings.Join(stage.CopyFrom, ","),
+					ExposedPorts:       strings.Join(stage.ExposedPorts, ","),
+					Entrypoint:         nullString(stage.Entrypoint),
+					Cmd:                nullString(stage.Cmd),
+					IsFinal:            stage.IsFinal,
+
+					ImageFamily:  nullString(class.Family),
+					ImageVersion: nullString(class.Version),
+					EolDate:      nullTime(class.EOLDate),
+					EolStatus:    class.EOLStatus,
+				})
+				if err != nil {
+					slog.Warn("Dockerfile-stage-feil", "repo", name, "fil", f.Path, "stage", stage.StageIndex, "error", err)
+				}
+			}
+		}
+	}
+}
+
+func insertDockerfileFindings(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	findings []models.DockerfileFinding,
+	snapshotDate time.Time,
+) {
+	for _, f := range findings {
+		if err := queries.InsertOrUpdateDockerfileFinding(ctx, storage.InsertOrUpdateDockerfileFindingParams{
+			RepoID:      repoID,
+			HentetDato:  snapshotDate,
+			Path:        f.Path,
+			RuleID:      f.RuleID,
+			Severity:    f.Severity,
+			Description: f.Description,
+			StartLine:   int32(f.StartLine),
+			EndLine:     int32(f.EndLine),
+		}); err != nil {
+			slog.Warn("Dockerfile-funn-feil", "repo", name, "fil", f.Path, "rule", f.RuleID, "error", err)
+		}
+	}
+}
+
+func insertBaseImageDigests(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	images []models.BaseImageInfo,
+	snapshotDate time.Time,
+) {
+	for _, img := range images {
+		if err := queries.InsertOrUpdateBaseImageDigest(ctx, storage.InsertOrUpdateBaseImageDigestParams{
+			RepoID:        repoID,
+			HentetDato:    snapshotDate,
+			Reference:     img.Reference,
+			Registry:      img.Registry,
+			PinnedDigest:  nullString(img.PinnedDigest),
+			CurrentDigest: nullString(img.CurrentDigest),
+			ImageCreated:  nullTime(img.ImageCreated),
+			DigestDiffers: img.DigestDiffers,
+			Error:         nullString(img.Error),
+		}); err != nil {
+			slog.Warn("Feil ved lagring av base image-digest", "repo", name, "image", img.Reference, "error", err)
 		}
 	}
 }
@@ -179,12 +391,489 @@ func insertCIConfig(
 	}
 }
 
+func insertWorkflows(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	files []models.FileEntry,
+	snapshotDate time.Time,
+) {
+	for _, f := range files {
+		wf, err := workflow.Parse(f.Content)
+		if err != nil {
+			slog.Warn("Kunne ikke parse workflow", "repo", name, "fil", f.Path, "error", err)
+			continue
+		}
+
+		if err := queries.InsertOrUpdateWorkflow(ctx, storage.InsertOrUpdateWorkflowParams{
+			RepoID:      repoID,
+			HentetDato:  snapshotDate,
+			Path:        f.Path,
+			Name:        nullString(wf.Name),
+			Triggers:    strings.Join(wf.Triggers, ","),
+			Permissions: nullPermissions(wf.Permissions),
+			Secrets:     strings.Join(wf.Secrets, ","),
+		}); err != nil {
+			slog.Warn("Workflow-feil", "repo", name, "fil", f.Path, "error", err)
+			continue
+		}
+
+		for _, job := range wf.Jobs {
+			var uses string
+			if job.Uses != nil {
+				uses = job.Uses.Raw
+			}
+			if err := queries.InsertOrUpdateWorkflowJob(ctx, storage.InsertOrUpdateWorkflowJobParams{
+				RepoID:         repoID,
+				HentetDato:     snapshotDate,
+				Path:           f.Path,
+				JobID:          job.ID,
+				Name:           nullString(job.Name),
+				Line:           int32(job.Line),
+				RunsOn:         strings.Join(job.RunsOn, ","),
+				Permissions:    nullPermissions(job.Permissions),
+				Uses:           nullString(uses),
+				SecretsInherit: job.SecretsInherit,
+			}); err != nil {
+				slog.Warn("Workflow-jobb-feil", "repo", name, "fil", f.Path, "jobb", job.ID, "error", err)
+			}
+		}
+
+		for _, u := range wf.Usages() {
+			if err := queries.InsertOrUpdateWorkflowAction(ctx, storage.InsertOrUpdateWorkflowActionParams{
+				RepoID:     repoID,
+				HentetDato: snapshotDate,
+				Path:       f.Path,
+				JobID:      u.Job,
+				StepIndex:  int32(u.StepIndex),
+				Uses:       u.Raw,
+				Kind:       u.Kind,
+				Action:     u.Action(),
+				Ref:        nullString(u.Ref),
+				RefType:    nullString(u.RefType),
+				Line:       int32(u.Line),
+			}); err != nil {
+				slog.Warn("Workflow-action-feil", "repo", name, "fil", f.Path, "uses", u.Raw, "error", err)
+			}
+		}
+	}
+}
+
+func insertWorkflowFindings(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	findings []models.WorkflowFinding,
+	snapshotDate time.Time,
+) {
+	for _, f := range findings {
+		if err := queries.InsertOrUpdateWorkflowFinding(ctx, storage.InsertOrUpdateWorkflowFindingParams{
+			RepoID:      repoID,
+			HentetDato:  snapshotDate,
+			Path:        f.Path,
+			RuleID:      f.RuleID,
+			Severity:    f.Severity,
+			Description: f.Description,
+			JobID:       f.Job,
+			Line:        int32(f.Line),
+		}); err != nil {
+			slog.Warn("Workflow-funn-feil", "repo", name, "fil", f.Path, "rule", f.RuleID, "error", err)
+		}
+	}
+}
+
+func insertUpdateBots(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	configs []models.UpdateBotConfig,
+	coverage []models.EcosystemCoverage,
+	snapshotDate time.Time,
+) {
+	for _, c := range configs {
+		if err := queries.InsertOrUpdateUpdateBotConfig(ctx, storage.InsertOrUpdateUpdateBotConfigParams{
+			RepoID:     repoID,
+			HentetDato: snapshotDate,
+			Tool:       c.Tool,
+			Path:       c.Path,
+			Ecosystem:  c.Ecosystem,
+			Directory:  c.Directory,
+			Schedule:   nullString(c.Schedule),
+			Groups:     strings.Join(c.Groups, ","),
+			AutoMerge:  c.AutoMerge,
+		}); err != nil {
+			slog.Warn("Feil ved lagring av oppdateringsbot-konfig", "repo", name, "fil", c.Path, "ecosystem", c.Ecosystem, "error", err)
+		}
+	}
+
+	for _, c := range coverage {
+		if err := queries.InsertOrUpdateEcosystemCoverage(ctx, storage.InsertOrUpdateEcosystemCoverageParams{
+			RepoID:     repoID,
+			HentetDato: snapshotDate,
+			Ecosystem:  c.Ecosystem,
+			Manifests:  strings.Join(c.Manifests, ","),
+			CoveredBy:  strings.Join(c.CoveredBy, ","),
+			IsCovered:  len(c.CoveredBy) > 0,
+		}); err != nil {
+			slog.Warn("Feil ved lagring av økosystem-dekning", "repo", name, "ecosystem", c.Ecosystem, "error", err)
+		}
+	}
+}
+
+func insertCodeowners(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	report models.CodeownersReport,
+	snapshotDate time.Time,
+) {
+	invalid := 0
+	for _, r := range report.Rules {
+		if r.Error != "" {
+			invalid++
+		}
+		if err := queries.InsertOrUpdateCodeownersRule(ctx, storage.InsertOrUpdateCodeownersRuleParams{
+			RepoID:        repoID,
+			HentetDato:    snapshotDate,
+			Path:          report.File,
+			Line:          int32(r.Line),
+			Pattern:       r.Pattern,
+			Owners:        strings.Join(r.Owners, ","),
+			UnknownOwners: sql.NullString{String: strings.Join(r.UnknownOwners, ","), Valid: r.UnknownOwners != nil},
+			Error:         nullString(r.Error),
+		}); err != nil {
+			slog.Warn("Feil ved lagring av CODEOWNERS-regel", "repo", name, "linje", r.Line, "error", err)
+		}
+	}
+
+	for _, p := range report.Paths {
+		if err := queries.InsertOrUpdateCodeownersPath(ctx, storage.InsertOrUpdateCodeownersPathParams{
+			RepoID:     repoID,
+			HentetDato: snapshotDate,
+			Path:       p.Path,
+			IsDir:      p.IsDir,
+			Owners:     strings.Join(p.Owners, ","),
+			RuleLine:   sql.NullInt32{Int32: int32(p.RuleLine), Valid: p.RuleLine > 0},
+		}); err != nil {
+			slog.Warn("Feil ved lagring av CODEOWNERS-eierskap", "repo", name, "path", p.Path, "error", err)
+		}
+	}
+
+	if err := queries.InsertOrUpdateCodeownersCoverage(ctx, storage.InsertOrUpdateCodeownersCoverageParams{
+		RepoID:           repoID,
+		HentetDato:       snapshotDate,
+		CodeownersPath:   nullString(report.File),
+		RuleCount:        int32(len(report.Rules)),
+		InvalidRuleCount: int32(invalid),
+		OwnedPercent:     float32(report.OwnedPercent),
+	}); err != nil {
+		slog.Warn("Feil ved lagring av CODEOWNERS-dekning", "repo", name, "error", err)
+	}
+}
+
+func insertFrameworks(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	detections []models.FrameworkDetection,
+	snapshotDate time.Time,
+) {
+	for _, d := range detections {
+		err := queries.InsertOrUpdateRepoFramework(ctx, storage.InsertOrUpdateRepoFrameworkParams{
+			RepoID:     repoID,
+			HentetDato: snapshotDate,
+			Framework:  d.Name,
+			Kind:       d.Kind,
+			Version:    nullString(d.Version),
+			Confidence: float32(d.Confidence),
+			Evidence:   nullString(strings.Join(d.Evidence, "; ")),
+		})
+		if err != nil {
+			slog.Warn("Feil ved lagring av rammeverk", "repo", name, "framework", d.Name, "error", err)
+		}
+	}
+}
+
+func insertRuntimeVersions(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	versions []models.RuntimeVersion,
+	snapshotDate time.Time,
+) {
+	for _, v := range versions {
+		err := queries.InsertOrUpdateRuntimeVersion(ctx, storage.InsertOrUpdateRuntimeVersionParams{
+			RepoID:     repoID,
+			HentetDato: snapshotDate,
+			Runtime:    v.Runtime,
+			Version:    nullString(v.Version),
+			RawValue:   v.Raw,
+			SourcePath: v.Source,
+			Setting:    v.Setting,
+		})
+		if err != nil {
+			slog.Warn("Feil ved lagring av runtime-versjon", "repo", name, "runtime", v.Runtime, "path", v.Source, "error", err)
+		}
+	}
+}
+
+func insertDirectDependencies(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	deps []models.Dependency,
+	snapshotDate time.Time,
+) {
+	for _, d := range deps {
+		err := queries.InsertOrUpdateDirectDependency(ctx, storage.InsertOrUpdateDirectDependencyParams{
+			RepoID:            repoID,
+			HentetDato:        snapshotDate,
+			Ecosystem:         d.Ecosystem,
+			Name:              d.Name,
+			VersionConstraint: nullString(d.Constraint),
+			Scope:             d.Scope,
+			Kind:              d.Kind,
+			SourcePath:        d.Source,
+		})
+		if err != nil {
+			slog.Warn("Feil ved lagring av avhengighet", "repo", name, "dependency", d.Name, "path", d.Source, "error", err)
+		}
+	}
+}
+
+func insertSecretFindings(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	findings []models.SecretFinding,
+	snapshotDate time.Time,
+) {
+	for _, f := range findings {
+		err := queries.InsertOrUpdateSecretFinding(ctx, storage.InsertOrUpdateSecretFindingParams{
+			RepoID:      repoID,
+			HentetDato:  snapshotDate,
+			Path:        f.Path,
+			RuleID:      f.RuleID,
+			Description: f.Description,
+			Line:        int32(f.Line),
+			SecretHash:  f.Hash,
+		})
+		if err != nil {
+			slog.Warn("Feil ved lagring av hemmelighetsfunn", "repo", name, "path", f.Path, "rule", f.RuleID, "error", err)
+		}
+	}
+}
+
+func insertDeployManifests(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	manifests []models.DeployManifest,
+	snapshotDate time.Time,
+) {
+	for _, m := range manifests {
+		err := queries.InsertOrUpdateDeployManifest(ctx, storage.InsertOrUpdateDeployManifestParams{
+			RepoID:                 repoID,
+			HentetDato:             snapshotDate,
+			Path:                   m.Path,
+			Kind:                   m.Kind,
+			Name:                   m.Name,
+			Namespace:              nullString(m.Namespace),
+			Images:                 nullString(strings.Join(m.Images, ",")),
+			ReplicasMin:            nullInt32(m.ReplicasMin),
+			ReplicasMax:            nullInt32(m.ReplicasMax),
+			CpuRequest:             nullString(m.CPURequest),
+			CpuLimit:               nullString(m.CPULimit),
+			MemoryRequest:          nullString(m.MemoryRequest),
+			MemoryLimit:            nullString(m.MemoryLimit),
+			IngressHosts:           nullString(strings.Join(m.IngressHosts, ",")),
+			InboundAccess:          nullString(strings.Join(m.InboundAccess, ",")),
+			OutboundAccess:         nullString(strings.Join(m.OutboundAccess, ",")),
+			LivenessProbe:          nullString(m.LivenessProbe),
+			ReadinessProbe:         nullString(m.ReadinessProbe),
+			RunAsNonRoot:           nullBool(m.RunAsNonRoot),
+			ReadOnlyRootFilesystem: nullBool(m.ReadOnlyRootFilesystem),
+		})
+		if err != nil {
+			slog.Warn("Feil ved lagring av deploy-manifest", "repo", name, "path", m.Path, "kind", m.Kind, "error", err)
+		}
+	}
+}
+
+func insertIaCModules(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	modules []models.IaCModule,
+	snapshotDate time.Time,
+) {
+	for _, m := range modules {
+		err := queries.InsertOrUpdateIaCModule(ctx, storage.InsertOrUpdateIaCModuleParams{
+			RepoID:     repoID,
+			HentetDato: snapshotDate,
+			Path:       m.Path,
+			Tool:       m.Tool,
+			Kind:       m.Kind,
+			Name:       m.Name,
+			Source:     nullString(m.Source),
+			SourceType: nullString(m.SourceType),
+			Version:    nullString(m.Version),
+			Pinned:     nullBool(m.Pinned),
+		})
+		if err != nil {
+			slog.Warn("Feil ved lagring av IaC-modul", "repo", name, "path", m.Path, "kind", m.Kind, "name", m.Name, "error", err)
+		}
+	}
+}
+
+func insertComposeServices(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	services []models.ComposeService,
+	snapshotDate time.Time,
+) {
+	for _, s := range services {
+		err := queries.InsertOrUpdateComposeService(ctx, storage.InsertOrUpdateComposeServiceParams{
+			RepoID:         repoID,
+			HentetDato:     snapshotDate,
+			Path:           s.Path,
+			Service:        s.Service,
+			Image:          nullString(s.Image),
+			ImageTag:       nullString(s.Tag),
+			RawImage:       nullString(s.RawImage),
+			Registry:       nullString(s.Registry),
+			IsDigestPinned: s.IsDigestPinned,
+			Digest:         nullString(s.Digest),
+			BuildContext:   nullString(s.BuildContext),
+			Dockerfile:     nullString(s.Dockerfile),
+			Ports:          nullString(strings.Join(s.Ports, ",")),
+			Privileged:     s.Privileged,
+			NetworkMode:    nullString(s.NetworkMode),
+			EnvFiles:       nullString(strings.Join(s.EnvFiles, ",")),
+		})
+		if err != nil {
+			slog.Warn("Feil ved lagring av compose-tjeneste", "repo", name, "path", s.Path, "service", s.Service, "error", err)
+			continue
+		}
+
+		for _, v := range s.Volumes {
+			err := queries.InsertOrUpdateComposeVolume(ctx, storage.InsertOrUpdateComposeVolumeParams{
+				RepoID:     repoID,
+				HentetDato: snapshotDate,
+				Path:       s.Path,
+				Service:    s.Service,
+				MountType:  v.Type,
+				Source:     nullString(v.Source),
+				Target:     v.Target,
+				ReadOnly:   v.ReadOnly,
+			})
+			if err != nil {
+				slog.Warn("Feil ved lagring av compose-volum", "repo", name, "path", s.Path, "service", s.Service, "target", v.Target, "error", err)
+			}
+		}
+	}
+}
+
+func insertReadmeMetrics(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	m *models.ReadmeMetrics,
+	snapshotDate time.Time,
+) {
+	if m == nil {
+		return
+	}
+	err := queries.InsertOrUpdateReadmeMetrics(ctx, storage.InsertOrUpdateReadmeMetricsParams{
+		RepoID:          repoID,
+		HentetDato:      snapshotDate,
+		Length:          int32(m.Length),
+		WordCount:       int32(m.WordCount),
+		HeadingCount:    int32(len(m.Headings)),
+		Headings:        nullString(strings.Join(m.Headings, "; ")),
+		HasInstallation: m.HasInstallation,
+		HasUsage:        m.HasUsage,
+		HasContact:      m.HasContact,
+		HasOwnership:    m.HasOwnership,
+		BadgeCount:      int32(len(m.Badges)),
+		Badges:          nullString(strings.Join(m.Badges, ",")),
+		InternalLinks:   int32(m.InternalLinks),
+		ExternalLinks:   int32(m.ExternalLinks),
+		CodeBlocks:      int32(m.CodeBlocks),
+		Language:        m.Language,
+		IsTemplate:      len(m.TemplateMarkers) > 0,
+		TemplateMarkers: nullString(strings.Join(m.TemplateMarkers, ",")),
+	})
+	if err != nil {
+		slog.Warn("Feil ved lagring av README-mål", "repo", name, "error", err)
+	}
+}
+
+func insertFileFingerprints(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	fingerprints []models.FileFingerprint,
+	snapshotDate time.Time,
+) {
+	for _, f := range fingerprints {
+		err := queries.InsertOrUpdateFileFingerprint(ctx, storage.InsertOrUpdateFileFingerprintParams{
+			RepoID:      repoID,
+			HentetDato:  snapshotDate,
+			Path:        f.Path,
+			FileType:    f.FileType,
+			ContentHash: f.ContentHash,
+			Simhash:     f.SimHash,
+			LineCount:   int32(f.LineCount),
+		})
+		if err != nil {
+			slog.Warn("Feil ved lagring av fingeravtrykk", "repo", name, "path", f.Path, "error", err)
+		}
+	}
+}
+
+func insertSkippedFiles(
+	ctx context.Context,
+	queries *storage.Queries,
+	repoID int64,
+	name string,
+	files []models.SkippedFile,
+	snapshotDate time.Time,
+) {
+	for _, f := range files {
+		if err := queries.InsertOrUpdateSkippedFile(ctx, storage.InsertOrUpdateSkippedFileParams{
+			RepoID:     repoID,
+			HentetDato: snapshotDate,
+			Path:       f.Path,
+			Reason:     f.Reason,
+			ByteSize:   f.ByteSize,
+		}); err != nil {
+			slog.Warn("Feil ved lagring av hoppet-over fil", "repo", name, "fil", f.Path, "error", err)
+		}
+	}
+}
+
 func insertSBOMPackagesGithub(
 	ctx context.Context,
 	queries *storage.Queries,
 	repoID int64,
 	name string,
 	sbomRaw map[string]interface{},
+	source string,
 	snapshotDate time.Time,
 ) {
 	if sbomRaw == nil {
@@ -235,6 +924,7 @@ func insertSBOMPackagesGithub(
 			Version:    sql.NullString{String: version, Valid: version != ""},
 			License:    sql.NullString{String: license, Valid: license != ""},
 			Purl:       sql.NullString{String: purl, Valid: purl != ""},
+			Source:     source,
 		})
 		if err != nil {
 			slog.Warn("SBOM-insert-feil", "repo", name, "package", nameVal, "error", err)
@@ -249,6 +939,39 @@ func SafeLicense(lic *struct{ SpdxID string }) string {
 	return lic.SpdxID
 }
 
+func nullString(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
+}
+
+// nullPermissions skiller mellom permissions som ikke er satt (NULL) og tom permissions ({}).
+func nullPermissions(v *string) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *v, Valid: true}
+}
+
+func nullTime(v *time.Time) sql.NullTime {
+	if v == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *v, Valid: true}
+}
+
+func nullInt32(v *int) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(*v), Valid: true}
+}
+
+func nullBool(v *bool) sql.NullBool {
+	if v == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *v, Valid: true}
+}
+
 func SafeString(v interface{}) string {
 	if v == nil {
 		return ""
@@ -0,0 +1,544 @@
+package dependencies
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+// Økosystemer navngis som purl-typer, så avhengighetene kan sammenlignes med SBOM-en
+const (
+	EcosystemGo    = "golang"
+	EcosystemNPM   = "npm"
+	EcosystemMaven = "maven"
+	EcosystemPyPI  = "pypi"
+)
+
+const (
+	ScopeRuntime = "runtime"
+	ScopeDev     = "dev"
+	ScopeTest    = "test"
+)
+
+const (
+	KindDirect  = "direct"  // avhengighet repoet selv har lagt til
+	KindParent  = "parent"  // Maven-parent
+	KindManaged = "managed" // versjon satt i dependencyManagement eller en BOM
+	KindPlugin  = "plugin"  // Gradle-plugin
+)
+
+// VersionCatalog er stien til Gradle version catalog, som hentes som eget objekt i GraphQL
+const VersionCatalog = "gradle/libs.versions.toml"
+
+const requirementsTxt = "requirements"
+
+// IsManifest sier om et filnavn på toppnivå er et manifest vi leser direkte avhengigheter fra.
+// Gradle version catalog ligger under gradle/ og hentes for seg.
+func IsManifest(lowerName string) bool {
+	switch lowerName {
+	case "go.mod", "package.json", "pom.xml", "pyproject.toml", "build.gradle", "build.gradle.kts":
+		return true
+	}
+	return strings.HasPrefix(lowerName, requirementsTxt) && strings.HasSuffix(lowerName, ".txt")
+}
+
+// Extract finner de direkte avhengighetene i manifestene blant de hentede filene.
+func Extract(files map[string][]models.FileEntry) []models.Dependency {
+	c := &collector{seen: map[string]bool{}}
+
+	keys := make([]string, 0, len(files))
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, f := range files[key] {
+			switch {
+			case key == "go.mod":
+				c.addAll(ParseGoMod(f.Path, f.Content))
+			case key == "package.json":
+				c.addAll(ParsePackageJSON(f.Path, f.Content))
+			case key == "pom.xml":
+				c.addAll(ParsePom(f.Path, f.Content))
+			case key == "pyproject.toml":
+				c.addAll(ParsePyproject(f.Path, f.Content))
+			case key == "build.gradle", key == "build.gradle.kts":
+				c.addAll(ParseGradleBuild(f.Path, f.Content))
+			case key == VersionCatalog:
+				c.addAll(ParseVersionCatalog(f.Path, f.Content, gradleUsage(files)))
+			case IsManifest(key):
+				c.addAll(ParseRequirements(f.Path, f.Content))
+			}
+		}
+	}
+	return c.result
+}
+
+type collector struct {
+	result []models.Dependency
+	seen   map[string]bool
+}
+
+func (c *collector) addAll(deps []models.Dependency) {
+	for _, d := range deps {
+		key := strings.Join([]string{d.Source, d.Ecosystem, d.Name, d.Scope, d.Kind}, "|")
+		if d.Name == "" || c.seen[key] {
+			continue
+		}
+		c.seen[key] = true
+		c.result = append(c.result, d)
+	}
+}
+
+// ParseGoMod leser require-direktivene. Avhengigheter merket // indirect tas ikke med.
+func ParseGoMod(filePath, content string) []models.Dependency {
+	var result []models.Dependency
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		code, comment, _ := strings.Cut(line, "//")
+		fields := strings.Fields(code)
+
+		switch {
+		case inBlock && strings.HasPrefix(line, ")"):
+			inBlock = false
+			continue
+		case len(fields) >= 2 && fields[0] == "require" && fields[1] == "(":
+			inBlock = true
+			continue
+		case len(fields) >= 3 && fields[0] == "require":
+			fields = fields[1:]
+		case inBlock && len(fields) >= 2:
+		default:
+			continue
+		}
+		if strings.TrimSpace(comment) == "indirect" {
+			continue
+		}
+		result = append(result, models.Dependency{
+			Ecosystem:  EcosystemGo,
+			Name:       fields[0],
+			Constraint: fields[1],
+			Scope:      ScopeRuntime,
+			Kind:       KindDirect,
+			Source:     filePath,
+		})
+	}
+	return result
+}
+
+// ParsePackageJSON leser dependencies, devDependencies, peerDependencies og optionalDependencies.
+func ParsePackageJSON(filePath, content string) []models.Dependency {
+	var pkg map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &pkg); err != nil {
+		return nil
+	}
+	var result []models.Dependency
+	for _, section := range []struct{ key, scope string }{
+		{"dependencies", ScopeRuntime},
+		{"peerDependencies", ScopeRuntime},
+		{"optionalDependencies", ScopeRuntime},
+		{"devDependencies", ScopeDev},
+	} {
+		var deps map[string]string
+		if err := json.Unmarshal(pkg[section.key], &deps); err != nil {
+			continue
+		}
+		names := make([]string, 0, len(deps))
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			result = append(result, models.Dependency{
+				Ecosystem:  EcosystemNPM,
+				Name:       name,
+				Constraint: deps[name],
+				Scope:      section.scope,
+				Kind:       KindDirect,
+				Source:     filePath,
+			})
+		}
+	}
+	return result
+}
+
+type pomProject struct {
+	GroupID string `xml:"groupId"`
+	Version string `xml:"version"`
+	Parent  struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	DependencyManagement struct {
+		Dependencies []pomDependency `xml:"dependencies>dependency"`
+	} `xml:"dependencyManagement"`
+	Dependencies []pomDependency `xml:"dependencies>dependency"`
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+var pomPropertyPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// ParsePom leser parent, dependencyManagement og dependencies. Avhengigheter uten versjon
+// får versjonen fra dependencyManagement i samme fil; resten arves fra parent og står tomme.
+func ParsePom(filePath, content string) []models.Dependency {
+	var project pomProject
+	if err := xml.Unmarshal([]byte(content), &project); err != nil {
+		return nil
+	}
+
+	props := map[string]string{
+		"project.version":        project.Version,
+		"project.groupId":        project.GroupID,
+		"project.parent.version": project.Parent.Version,
+	}
+	if props["project.version"] == "" {
+		props["project.version"] = project.Parent.Version
+	}
+	for _, p := range project.Properties.Entries {
+		props[p.XMLName.Local] = strings.TrimSpace(p.Value)
+	}
+	resolve := func(value string) string {
+		return pomPropertyPattern.ReplaceAllStringFunc(strings.TrimSpace(value), func(m string) string {
+			if v, ok := props[m[2:len(m)-1]]; ok && v != "" {
+				return v
+			}
+			return m
+		})
+	}
+
+	var result []models.Dependency
+	add := func(group, artifact, version, scope, kind string) {
+		group = resolve(group)
+		if group == "" || artifact == "" {
+			return
+		}
+		result = append(result, models.Dependency{
+			Ecosystem:  EcosystemMaven,
+			Name:       group + ":" + strings.TrimSpace(artifact),
+			Constraint: resolve(version),
+			Scope:      scope,
+			Kind:       kind,
+			Source:     filePath,
+		})
+	}
+
+	if project.Parent.ArtifactID != "" {
+		add(project.Parent.GroupID, project.Parent.ArtifactID, project.Parent.Version, ScopeRuntime, KindParent)
+	}
+	managed := map[string]string{}
+	for _, d := range project.DependencyManagement.Dependencies {
+		managed[resolve(d.GroupID)+":"+d.ArtifactID] = d.Version
+		add(d.GroupID, d.ArtifactID, d.Version, mavenScope(d.Scope), KindManaged)
+	}
+	for _, d := range project.Dependencies {
+		version := d.Version
+		if version == "" {
+			version = managed[resolve(d.GroupID)+":"+d.ArtifactID]
+		}
+		add(d.GroupID, d.ArtifactID, version, mavenScope(d.Scope), KindDirect)
+	}
+	return result
+}
+
+func mavenScope(scope string) string {
+	switch strings.TrimSpace(scope) {
+	case "test":
+		return ScopeTest
+	default:
+		// compile, runtime, provided, system og import havner i runtime
+		return ScopeRuntime
+	}
+}
+
+var (
+	gradleDependencyPattern = regexp.MustCompile(`\b([A-Za-z]+)\s*\(?\s*["']([^"':\s]+):([^"':\s]+)(?::([^"'\s]+))?["']`)
+	gradleCatalogUsage      = regexp.MustCompile(`\b([A-Za-z]+)\s*\(?\s*libs\.([A-Za-z0-9_.]+)`)
+	gradlePluginPattern     = regexp.MustCompile(`\bid\s*\(?\s*["']([\w.-]+)["']\s*\)?\s*version\s*\(?\s*["']([^"']+)["']`)
+)
+
+// gradleScope oversetter en Gradle-konfigurasjon til scope. Ukjente navn gir "",
+// så andre funksjonskall med "a:b"-strenger ikke tolkes som avhengigheter.
+func gradleScope(configuration string) string {
+	lower := strings.ToLower(configuration)
+	switch {
+	case strings.HasPrefix(lower, "test"), strings.HasPrefix(lower, "androidtest"):
+		return ScopeTest
+	case lower == "compileonly", lower == "annotationprocessor", lower == "kapt", lower == "ksp", lower == "developmentonly":
+		return ScopeDev
+	case lower == "implementation", lower == "api", lower == "runtimeonly", lower == "compile", lower == "runtime":
+		return ScopeRuntime
+	}
+	return ""
+}
+
+// ParseGradleBuild leser avhengigheter skrevet som "group:artifact:version" og plugins med versjon.
+// Avhengigheter fra version catalog (libs.x) tas med av ParseVersionCatalog.
+func ParseGradleBuild(filePath, content string) []models.Dependency {
+	var result []models.Dependency
+	for _, m := range gradleDependencyPattern.FindAllStringSubmatch(content, -1) {
+		scope := gradleScope(m[1])
+		if scope == "" {
+			continue
+		}
+		result = append(result, models.Dependency{
+			Ecosystem:  EcosystemMaven,
+			Name:       m[2] + ":" + m[3],
+			Constraint: m[4],
+			Scope:      scope,
+			Kind:       KindDirect,
+			Source:     filePath,
+		})
+	}
+	for _, m := range gradlePluginPattern.FindAllStringSubmatch(content, -1) {
+		result = append(result, gradlePlugin(m[1], m[2], filePath))
+	}
+	return result
+}
+
+// Gradle-plugins publiseres med en markør-artefakt id:id.gradle.plugin, som vi bruker som navn
+func gradlePlugin(id, version, source string) models.Dependency {
+	return models.Dependency{
+		Ecosystem:  EcosystemMaven,
+		Name:       id + ":" + id + ".gradle.plugin",
+		Constraint: version,
+		Scope:      ScopeDev,
+		Kind:       KindPlugin,
+		Source:     source,
+	}
+}
+
+// gradleUsage finner scope for hvert alias i version catalog ut fra byggefilene,
+// f.eks. testImplementation(libs.junit.jupiter) gir "junit.jupiter" = test.
+func gradleUsage(files map[string][]models.FileEntry) map[string]string {
+	usage := map[string]string{}
+	for _, key := range []string{"build.gradle", "build.gradle.kts"} {
+		for _, f := range files[key] {
+			for _, m := range gradleCatalogUsage.FindAllStringSubmatch(f.Content, -1) {
+				scope := gradleScope(m[1])
+				if scope == "" {
+					continue
+				}
+				// Runtime vinner hvis samme bibliotek brukes flere steder
+				if existing, ok := usage[m[2]]; !ok || scope == ScopeRuntime || existing == ScopeTest {
+					usage[m[2]] = scope
+				}
+			}
+		}
+	}
+	return usage
+}
+
+// ParseVersionCatalog leser [libraries] og [plugins] i libs.versions.toml, med versjoner fra [versions].
+// Scope hentes fra hvordan aliaset brukes i byggefilene, og er runtime når det ikke er kjent.
+func ParseVersionCatalog(filePath, content string, usage map[string]string) []models.Dependency {
+	entries := parseTOML(content)
+	versions := map[string]string{}
+	for _, e := range entries {
+		if e.Table == "versions" {
+			if inline := tomlInline(e.Value); inline != nil {
+				versions[e.Key] = catalogVersion(inline, "", versions)
+			} else {
+				versions[e.Key] = unquote(e.Value)
+			}
+		}
+	}
+
+	var result []models.Dependency
+	for _, e := range entries {
+		switch e.Table {
+		case "libraries":
+			var module, version string
+			if inline := tomlInline(e.Value); inline != nil {
+				module = inline["module"]
+				if module == "" && inline["group"] != "" {
+					module = inline["group"] + ":" + inline["name"]
+				}
+				version = catalogVersion(inline, "version", versions)
+			} else {
+				parts := strings.SplitN(unquote(e.Value), ":", 3)
+				if len(parts) == 3 {
+					module, version = parts[0]+":"+parts[1], parts[2]
+				}
+			}
+			if module == "" {
+				continue
+			}
+			scope := usage[strings.NewReplacer("-", ".", "_", ".").Replace(e.Key)]
+			if scope == "" {
+				scope = ScopeRuntime
+			}
+			result = append(result, models.Dependency{
+				Ecosystem:  EcosystemMaven,
+				Name:       module,
+				Constraint: version,
+				Scope:      scope,
+				Kind:       KindDirect,
+				Source:     filePath,
+			})
+		case "plugins":
+			if inline := tomlInline(e.Value); inline != nil {
+				result = append(result, gradlePlugin(inline["id"], catalogVersion(inline, "version", versions), filePath))
+			} else if id, version, ok := strings.Cut(unquote(e.Value), ":"); ok {
+				result = append(result, gradlePlugin(id, version, filePath))
+			}
+		}
+	}
+	return result
+}
+
+// catalogVersion håndterer version = "1.0", version.ref = "x" og rich versions som
+// version = { strictly = "1.0" } eller { require = "1.0", prefer = "1.1" }.
+func catalogVersion(inline map[string]string, prefix string, versions map[string]string) string {
+	if prefix != "" {
+		if v := inline[prefix]; v != "" {
+			return v
+		}
+		if ref := inline[prefix+".ref"]; ref != "" {
+			return versions[ref]
+		}
+		prefix += "."
+	}
+	for _, key := range []string{"strictly", "require", "prefer"} {
+		if v := inline[prefix+key]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// PEP 508: navn, valgfrie extras, versjonskrav og markører etter ;
+var (
+	pep508Pattern  = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(?:\(([^)]*)\)|([^;]*))`)
+	pypiSeparators = regexp.MustCompile(`[-_.]+`)
+)
+
+func parsePEP508(spec, scope, source string) (models.Dependency, bool) {
+	m := pep508Pattern.FindStringSubmatch(strings.TrimSpace(spec))
+	if m == nil {
+		return models.Dependency{}, false
+	}
+	constraint := strings.TrimSpace(m[2] + m[3])
+	// Direkte URL-er (name @ https://...) har ingen versjon
+	if strings.HasPrefix(constraint, "@") {
+		constraint = ""
+	}
+	return models.Dependency{
+		Ecosystem:  EcosystemPyPI,
+		Name:       pypiName(m[1]),
+		Constraint: constraint,
+		Scope:      scope,
+		Kind:       KindDirect,
+		Source:     source,
+	}, true
+}
+
+// pypiName normaliserer navnet slik PyPI gjør (PEP 503), så det kan sammenlignes med SBOM-en.
+func pypiName(name string) string {
+	return strings.ToLower(pypiSeparators.ReplaceAllString(name, "-"))
+}
+
+// ParseRequirements leser requirements.txt. Scope utledes av filnavnet,
+// f.eks. requirements-dev.txt og requirements-test.txt.
+func ParseRequirements(filePath, content string) []models.Dependency {
+	scope := groupScope(strings.TrimSuffix(strings.TrimPrefix(strings.ToLower(path.Base(filePath)), requirementsTxt), ".txt"))
+	var result []models.Dependency
+	for _, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, " #")
+		line = strings.TrimSpace(line)
+		// Kommentarer, opsjoner som -r og -e, og URL-er hoppes over
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+		if dep, ok := parsePEP508(line, scope, filePath); ok {
+			result = append(result, dep)
+		}
+	}
+	return result
+}
+
+// groupScope gjetter scope ut fra navnet på en gruppe eller fil, f.eks. "test", "dev" eller "lint".
+func groupScope(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "test"):
+		return ScopeTest
+	case strings.Contains(name, "dev"), strings.Contains(name, "lint"), strings.Contains(name, "doc"), strings.Contains(name, "typing"):
+		return ScopeDev
+	}
+	return ScopeRuntime
+}
+
+// ParsePyproject leser PEP 621 ([project]), PEP 735 ([dependency-groups]) og Poetry.
+func ParsePyproject(filePath, content string) []models.Dependency {
+	var result []models.Dependency
+	addSpecs := func(specs []string, scope string) {
+		for _, spec := range specs {
+			if dep, ok := parsePEP508(spec, scope, filePath); ok {
+				result = append(result, dep)
+			}
+		}
+	}
+
+	for _, e := range parseTOML(content) {
+		switch {
+		case e.Table == "project" && e.Key == "dependencies":
+			addSpecs(tomlArray(e.Value), ScopeRuntime)
+		case e.Table == "project.optional-dependencies", e.Table == "dependency-groups":
+			scope := groupScope(e.Key)
+			if e.Table == "dependency-groups" && scope == ScopeRuntime {
+				// Dependency groups er aldri en del av pakken som installeres
+				scope = ScopeDev
+			}
+			addSpecs(tomlArray(e.Value), scope)
+		case e.Table == "tool.poetry.dependencies", e.Table == "tool.poetry.dev-dependencies",
+			strings.HasPrefix(e.Table, "tool.poetry.group.") && strings.HasSuffix(e.Table, ".dependencies"):
+			if strings.EqualFold(e.Key, "python") {
+				continue
+			}
+			scope := ScopeRuntime
+			switch {
+			case e.Table == "tool.poetry.dev-dependencies":
+				scope = ScopeDev
+			case strings.HasPrefix(e.Table, "tool.poetry.group."):
+				// Gruppen main er de vanlige avhengighetene, andre grupper er valgfrie
+				group := strings.TrimSuffix(strings.TrimPrefix(e.Table, "tool.poetry.group."), ".dependencies")
+				if scope = groupScope(group); scope == ScopeRuntime && group != "main" {
+					scope = ScopeDev
+				}
+			}
+			constraint := unquote(e.Value)
+			if inline := tomlInline(e.Value); inline != nil {
+				constraint = inline["version"]
+			}
+			result = append(result, models.Dependency{
+				Ecosystem:  EcosystemPyPI,
+				Name:       pypiName(e.Key),
+				Constraint: constraint,
+				Scope:      scope,
+				Kind:       KindDirect,
+				Source:     filePath,
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,203 @@
+package dependencies_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/dependencies"
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+func TestDependencies(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Direkte avhengigheter fra manifester")
+}
+
+// summary gir "navn constraint scope kind" per avhengighet, så testene blir lette å lese
+func summary(deps []models.Dependency) []string {
+	var result []string
+	for _, d := range deps {
+		result = append(result, d.Name+" "+d.Constraint+" "+d.Scope+" "+d.Kind)
+	}
+	return result
+}
+
+var _ = Describe("Parsere", func() {
+	It("leser go.mod uten indirekte avhengigheter", func() {
+		deps := dependencies.ParseGoMod("go.mod", `module x
+
+go 1.22
+
+require github.com/lib/pq v1.10.9
+
+require (
+	github.com/gin-gonic/gin v1.9.1
+	golang.org/x/sys v0.33.0 // indirect
+)
+`)
+		Expect(summary(deps)).To(Equal([]string{
+			"github.com/lib/pq v1.10.9 runtime direct",
+			"github.com/gin-gonic/gin v1.9.1 runtime direct",
+		}))
+		Expect(deps[0].Ecosystem).To(Equal(dependencies.EcosystemGo))
+	})
+
+	It("leser package.json med dev-avhengigheter", func() {
+		deps := dependencies.ParsePackageJSON("package.json", `{
+  "dependencies": {"react": "^18.2.0", "next": "14.1.0"},
+  "devDependencies": {"typescript": "~5.4.0"}
+}`)
+		Expect(summary(deps)).To(Equal([]string{
+			"next 14.1.0 runtime direct",
+			"react ^18.2.0 runtime direct",
+			"typescript ~5.4.0 dev direct",
+		}))
+	})
+
+	It("leser pom.xml med parent, dependencyManagement og properties", func() {
+		deps := dependencies.ParsePom("pom.xml", `<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>3.3.0</version>
+  </parent>
+  <properties><testcontainers.version>1.19.8</testcontainers.version></properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>org.testcontainers</groupId>
+        <artifactId>testcontainers-bom</artifactId>
+        <version>${testcontainers.version}</version>
+        <type>pom</type>
+        <scope>import</scope>
+      </dependency>
+      <dependency>
+        <groupId>io.micrometer</groupId>
+        <artifactId>micrometer-registry-prometheus</artifactId>
+        <version>1.13.0</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-web</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>io.micrometer</groupId>
+      <artifactId>micrometer-registry-prometheus</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>org.testcontainers</groupId>
+      <artifactId>postgresql</artifactId>
+      <scope>test</scope>
+    </dependency>
+  </dependencies>
+</project>`)
+		Expect(summary(deps)).To(Equal([]string{
+			"org.springframework.boot:spring-boot-starter-parent 3.3.0 runtime parent",
+			"org.testcontainers:testcontainers-bom 1.19.8 runtime managed",
+			"io.micrometer:micrometer-registry-prometheus 1.13.0 runtime managed",
+			"org.springframework.boot:spring-boot-starter-web  runtime direct",
+			"io.micrometer:micrometer-registry-prometheus 1.13.0 runtime direct",
+			"org.testcontainers:postgresql  test direct",
+		}))
+	})
+
+	It("leser Gradle version catalog og henter scope fra byggefilen", func() {
+		usage := dependencies.ParseGradleBuild("build.gradle.kts", `plugins {
+    id("org.jetbrains.kotlin.jvm") version "2.0.0"
+}
+dependencies {
+    implementation(libs.ktor.server.core)
+    testImplementation(libs.kotest.runner)
+    implementation("com.nimbusds:nimbus-jose-jwt:9.40")
+    exclude("org.slf4j:slf4j-api")
+}`)
+		Expect(summary(usage)).To(Equal([]string{
+			"com.nimbusds:nimbus-jose-jwt 9.40 runtime direct",
+			"org.jetbrains.kotlin.jvm:org.jetbrains.kotlin.jvm.gradle.plugin 2.0.0 dev plugin",
+		}))
+
+		files := map[string][]models.FileEntry{
+			"build.gradle.kts": {{Path: "build.gradle.kts", Content: "dependencies {\n    implementation(libs.ktor.server.core)\n    testImplementation(libs.kotest.runner)\n}"}},
+			dependencies.VersionCatalog: {{Path: dependencies.VersionCatalog, Content: `[versions]
+ktor = "2.3.11" # kommentar
+kotest = { strictly = "5.9.0" }
+
+[libraries]
+ktor-server-core = { module = "io.ktor:ktor-server-core", version.ref = "ktor" }
+kotest_runner = { group = "io.kotest", name = "kotest-runner-junit5", version.ref = "kotest" }
+logback = "ch.qos.logback:logback-classic:1.5.6"
+
+[plugins]
+ktor = { id = "io.ktor.plugin", version.ref = "ktor" }
+`}},
+		}
+		deps := dependencies.Extract(files)
+		Expect(summary(deps)).To(Equal([]string{
+			"io.ktor:ktor-server-core 2.3.11 runtime direct",
+			"io.kotest:kotest-runner-junit5 5.9.0 test direct",
+			"ch.qos.logback:logback-classic 1.5.6 runtime direct",
+			"io.ktor.plugin:io.ktor.plugin.gradle.plugin 2.3.11 dev plugin",
+		}))
+	})
+
+	It("leser requirements.txt med scope fra filnavnet", func() {
+		content := `# kommentar
+-r base.txt
+Django>=4.2,<5.0
+uvicorn[standard]==0.29.0 ; python_version >= "3.8"
+pytest_asyncio
+git+https://github.com/x/y.git
+`
+		Expect(summary(dependencies.ParseRequirements("requirements.txt", content))).To(Equal([]string{
+			"django >=4.2,<5.0 runtime direct",
+			"uvicorn ==0.29.0 runtime direct",
+			"pytest-asyncio  runtime direct",
+		}))
+		Expect(dependencies.ParseRequirements("requirements-dev.txt", "ruff")[0].Scope).To(Equal(dependencies.ScopeDev))
+	})
+
+	It("leser pyproject.toml med PEP 621, dependency groups og Poetry", func() {
+		deps := dependencies.ParsePyproject("pyproject.toml", `[project]
+name = "app"
+requires-python = ">=3.11"
+dependencies = [
+    "fastapi>=0.110",
+    "pydantic-settings",
+]
+
+[project.optional-dependencies]
+test = ["pytest>=8"]
+
+[dependency-groups]
+lint = ["ruff"]
+
+[tool.poetry.dependencies]
+python = "^3.11"
+httpx = { version = "^0.27", extras = ["http2"] }
+
+[tool.poetry.group.test.dependencies]
+respx = "^0.21"
+`)
+		Expect(summary(deps)).To(Equal([]string{
+			"fastapi >=0.110 runtime direct",
+			"pydantic-settings  runtime direct",
+			"pytest >=8 test direct",
+			"ruff  dev direct",
+			"httpx ^0.27 runtime direct",
+			"respx ^0.21 test direct",
+		}))
+	})
+})
+
+var _ = Describe("IsManifest", func() {
+	It("kjenner igjen manifester på toppnivå", func() {
+		Expect(dependencies.IsManifest("requirements-test.txt")).To(BeTrue())
+		Expect(dependencies.IsManifest("pom.xml")).To(BeTrue())
+		Expect(dependencies.IsManifest("readme.txt")).To(BeFalse())
+	})
+})
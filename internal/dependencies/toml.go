@@ -0,0 +1,156 @@
+package dependencies
+
+import "strings"
+
+// tomlEntry er én nøkkel = verdi i en TOML-fil, med tabellen den står i.
+// Verdien er rå TOML, f.eks. `"1.0"`, `{ module = "a:b" }` eller `["x", "y"]`.
+type tomlEntry struct {
+	Table string
+	Key   string
+	Value string
+}
+
+// parseTOML leser de delene av TOML vi trenger for manifester: tabeller, nøkkel = verdi,
+// inline-tabeller og lister over flere linjer. Det er ingen full TOML-parser,
+// men nok for pyproject.toml og Gradle version catalogs.
+func parseTOML(content string) []tomlEntry {
+	var result []tomlEntry
+	table := ""
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(stripTOMLComment(lines[i]))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && !strings.Contains(line, "=") {
+			table = strings.TrimSpace(strings.Trim(line, "[]"))
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		// Lister og inline-tabeller kan gå over flere linjer
+		for depth(value) > 0 && i+1 < len(lines) {
+			i++
+			value += " " + strings.TrimSpace(stripTOMLComment(lines[i]))
+		}
+		result = append(result, tomlEntry{Table: table, Key: unquote(strings.TrimSpace(key)), Value: value})
+	}
+	return result
+}
+
+// depth teller åpne [ og { utenfor strenger.
+func depth(s string) int {
+	n := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			n++
+		case c == ']' || c == '}':
+			n--
+		}
+	}
+	return n
+}
+
+func stripTOMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitTopLevel deler på sep utenfor strenger, lister og inline-tabeller.
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	var quote byte
+	level, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			level++
+		case c == ']' || c == '}':
+			level--
+		case c == sep && level == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// tomlArray returnerer strengene i en TOML-liste.
+func tomlArray(value string) []string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return nil
+	}
+	var result []string
+	for _, item := range splitTopLevel(value[1:len(value)-1], ',') {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, unquote(item))
+		}
+	}
+	return result
+}
+
+// tomlInline leser en inline-tabell til et flatt map. Nøstede tabeller som
+// version = { strictly = "1.0" } får nøkler som "version.strictly".
+func tomlInline(value string) map[string]string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "{") || !strings.HasSuffix(value, "}") {
+		return nil
+	}
+	result := map[string]string{}
+	for _, item := range splitTopLevel(value[1:len(value)-1], ',') {
+		key, val, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		key = unquote(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+		if nested := tomlInline(val); nested != nil {
+			for k, v := range nested {
+				result[key+"."+k] = v
+			}
+			continue
+		}
+		result[key] = unquote(val)
+	}
+	return result
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
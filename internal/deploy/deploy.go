@@ -0,0 +1,491 @@
+package deploy
+
+import (
+	"bytes"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+// FilesKey er nøkkelen deploy-manifestene lagres under i RepoEntry.Files
+const FilesKey = "deploy"
+
+// MaxFiles begrenser hvor mange manifester vi henter per repo, siden hver fil er et eget REST-kall
+const MaxFiles = 50
+
+const (
+	KindNaisApplication = "nais-application"
+	KindNaisJob         = "nais-naisjob"
+	KindDeployment      = "deployment"
+	KindStatefulSet     = "statefulset"
+	KindDaemonSet       = "daemonset"
+	KindJob             = "job"
+	KindCronJob         = "cronjob"
+	KindIngress         = "ingress"
+	KindHelmValues      = "helm-values"
+)
+
+// Mapper som typisk inneholder NAIS- eller Kubernetes-manifester, i små bokstaver
+var manifestDirs = map[string]bool{
+	".nais":       true,
+	"nais":        true,
+	"k8s":         true,
+	"kubernetes":  true,
+	"kube":        true,
+	"deploy":      true,
+	"deployment":  true,
+	"deployments": true,
+	"manifests":   true,
+	"charts":      true,
+	"chart":       true,
+	"helm":        true,
+}
+
+// Kubernetes-typene vi leser, med stien til pod-spec fra spec
+var workloads = map[string]struct {
+	kind    string
+	podSpec []string
+}{
+	"Deployment":  {KindDeployment, []string{"template", "spec"}},
+	"StatefulSet": {KindStatefulSet, []string{"template", "spec"}},
+	"DaemonSet":   {KindDaemonSet, []string{"template", "spec"}},
+	"Job":         {KindJob, []string{"template", "spec"}},
+	"CronJob":     {KindCronJob, []string{"jobTemplate", "spec", "template", "spec"}},
+}
+
+// NAIS-filer bruker ofte {{ image }} og lignende plassholdere som fylles inn ved deploy
+var templatePattern = regexp.MustCompile(`\{\{-?\s*([^{}]*?)\s*-?\}\}`)
+
+// HasCandidates sier om toppnivået i repoet tyder på deploy-manifester,
+// så vi bare går gjennom hele treet for repoer som sannsynligvis har dem.
+func HasCandidates(rootFiles, rootDirs []string) bool {
+	for _, f := range rootFiles {
+		lower := strings.ToLower(f)
+		if isYAML(lower) && (strings.HasPrefix(lower, "nais") || lower == "chart.yaml") {
+			return true
+		}
+	}
+	for _, d := range rootDirs {
+		if manifestDirs[strings.ToLower(d)] {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectPaths velger deploy-manifestene blant alle filene i repo-treet: nais*.yaml hvor som helst,
+// YAML i kjente deploy-mapper, og Chart.yaml med values*.yaml i Helm-charts.
+// Helm-templates er Go-maler og ikke gyldig YAML, så de hoppes over.
+func SelectPaths(paths []string) []string {
+	charts := map[string]bool{}
+	for _, p := range paths {
+		if strings.EqualFold(path.Base(p), "chart.yaml") {
+			charts[path.Dir(p)] = true
+		}
+	}
+
+	var result []string
+	for _, p := range paths {
+		lower := strings.ToLower(p)
+		if !isYAML(lower) {
+			continue
+		}
+		base := path.Base(lower)
+		dirs := strings.Split(path.Dir(lower), "/")
+		if contains(dirs, "templates") {
+			continue
+		}
+		switch {
+		case base == "chart.yaml":
+		case charts[path.Dir(p)]:
+			if !strings.HasPrefix(base, "values") {
+				continue
+			}
+		case strings.HasPrefix(base, "nais"):
+		case anyManifestDir(dirs):
+		default:
+			continue
+		}
+		result = append(result, p)
+	}
+	sort.Strings(result)
+	if len(result) > MaxFiles {
+		result = result[:MaxFiles]
+	}
+	return result
+}
+
+// Parse leser deploy-fakta fra manifestene. Filer og dokumenter som ikke lar seg parse hoppes over.
+func Parse(files []models.FileEntry) []models.DeployManifest {
+	charts := map[string]string{}
+	for _, f := range files {
+		if strings.EqualFold(path.Base(f.Path), "chart.yaml") {
+			charts[path.Dir(f.Path)] = chartName(f.Content)
+		}
+	}
+
+	var result []models.DeployManifest
+	for _, f := range files {
+		if strings.EqualFold(path.Base(f.Path), "chart.yaml") {
+			continue
+		}
+		if name, ok := charts[path.Dir(f.Path)]; ok {
+			if m, ok := parseHelmValues(f.Path, name, f.Content); ok {
+				result = append(result, m)
+			}
+			continue
+		}
+		for _, doc := range documents(f.Content) {
+			if m, ok := parseDocument(f.Path, doc); ok {
+				result = append(result, m)
+			}
+		}
+	}
+	return result
+}
+
+// documents deler en YAML-fil i dokumenter, med malplassholdere byttet ut med <navn>
+func documents(content string) []*yaml.Node {
+	content = templatePattern.ReplaceAllString(content, "<$1>")
+	dec := yaml.NewDecoder(bytes.NewReader([]byte(content)))
+	var result []*yaml.Node
+	for {
+		var doc yaml.Node
+		// io.EOF eller et dokument som ikke lar seg parse avslutter filen
+		if err := dec.Decode(&doc); err != nil {
+			return result
+		}
+		if len(doc.Content) > 0 && doc.Content[0].Kind == yaml.MappingNode {
+			result = append(result, doc.Content[0])
+		}
+	}
+}
+
+func parseDocument(filePath string, doc *yaml.Node) (models.DeployManifest, bool) {
+	apiVersion := scalar(lookup(doc, "apiVersion"))
+	kind := scalar(lookup(doc, "kind"))
+	metadata := lookup(doc, "metadata")
+	spec := lookup(doc, "spec")
+
+	m := models.DeployManifest{
+		Path:      filePath,
+		Name:      scalar(lookup(metadata, "name")),
+		Namespace: scalar(lookup(metadata, "namespace")),
+	}
+
+	switch {
+	case strings.HasPrefix(apiVersion, "nais.io/") && (kind == "Application" || kind == "Naisjob"):
+		m.Kind = KindNaisApplication
+		if kind == "Naisjob" {
+			m.Kind = KindNaisJob
+		}
+		parseNais(&m, spec)
+	case kind == "Ingress":
+		m.Kind = KindIngress
+		for _, rule := range items(lookup(spec, "rules")) {
+			m.IngressHosts = appendUnique(m.IngressHosts, scalar(lookup(rule, "host")))
+		}
+		for _, tls := range items(lookup(spec, "tls")) {
+			for _, host := range items(lookup(tls, "hosts")) {
+				m.IngressHosts = appendUnique(m.IngressHosts, scalar(host))
+			}
+		}
+	default:
+		w, ok := workloads[kind]
+		if !ok || strings.HasPrefix(apiVersion, "nais.io/") {
+			return m, false
+		}
+		m.Kind = w.kind
+		if replicas, ok := intValue(lookup(spec, "replicas")); ok {
+			m.ReplicasMin, m.ReplicasMax = &replicas, &replicas
+		}
+		podSpec := spec
+		for _, key := range w.podSpec {
+			podSpec = lookup(podSpec, key)
+		}
+		parsePodSpec(&m, podSpec)
+	}
+	return m, true
+}
+
+// parseNais leser spec for nais.io Application og Naisjob
+func parseNais(m *models.DeployManifest, spec *yaml.Node) {
+	m.Images = appendUnique(m.Images, scalar(lookup(spec, "image")))
+
+	replicas := lookup(spec, "replicas")
+	if v, ok := intValue(lookup(replicas, "min")); ok {
+		m.ReplicasMin = &v
+	}
+	if v, ok := intValue(lookup(replicas, "max")); ok {
+		m.ReplicasMax = &v
+	}
+
+	parseResources(m, lookup(spec, "resources"))
+
+	for _, ingress := range items(lookup(spec, "ingresses")) {
+		m.IngressHosts = appendUnique(m.IngressHosts, hostOf(scalar(ingress)))
+	}
+
+	policy := lookup(spec, "accessPolicy")
+	for _, rule := range items(lookup(lookup(policy, "inbound"), "rules")) {
+		m.InboundAccess = appendUnique(m.InboundAccess, accessRule(rule))
+	}
+	outbound := lookup(policy, "outbound")
+	for _, rule := range items(lookup(outbound, "rules")) {
+		m.OutboundAccess = appendUnique(m.OutboundAccess, accessRule(rule))
+	}
+	for _, ext := range items(lookup(outbound, "external")) {
+		m.OutboundAccess = appendUnique(m.OutboundAccess, scalar(lookup(ext, "host")))
+	}
+
+	// NAIS har egne probe-felter med path, ikke Kubernetes-prober
+	m.LivenessProbe = scalar(lookup(lookup(spec, "liveness"), "path"))
+	m.ReadinessProbe = scalar(lookup(lookup(spec, "readiness"), "path"))
+
+	sc := lookup(spec, "securityContext")
+	m.RunAsNonRoot = boolValue(lookup(sc, "runAsNonRoot"))
+	m.ReadOnlyRootFilesystem = boolValue(lookup(sc, "readOnlyRootFilesystem"))
+}
+
+// parsePodSpec leser images fra alle containere, og ressurser og prober fra den første.
+// securityContext gjelder bare hvis alle containere har det satt; én false gir false.
+func parsePodSpec(m *models.DeployManifest, podSpec *yaml.Node) {
+	containers := items(lookup(podSpec, "containers"))
+	if len(containers) == 0 {
+		return
+	}
+	for _, c := range containers {
+		m.Images = appendUnique(m.Images, scalar(lookup(c, "image")))
+	}
+
+	first := containers[0]
+	parseResources(m, lookup(first, "resources"))
+	m.LivenessProbe = probe(lookup(first, "livenessProbe"))
+	m.ReadinessProbe = probe(lookup(first, "readinessProbe"))
+
+	podNonRoot := boolValue(lookup(lookup(podSpec, "securityContext"), "runAsNonRoot"))
+	var nonRoot, readOnly []*bool
+	for _, c := range containers {
+		sc := lookup(c, "securityContext")
+		v := boolValue(lookup(sc, "runAsNonRoot"))
+		if v == nil {
+			v = podNonRoot
+		}
+		nonRoot = append(nonRoot, v)
+		readOnly = append(readOnly, boolValue(lookup(sc, "readOnlyRootFilesystem")))
+	}
+	m.RunAsNonRoot = all(nonRoot)
+	m.ReadOnlyRootFilesystem = all(readOnly)
+}
+
+// parseHelmValues leser de vanlige nøklene fra values.yaml i charts laget med helm create
+func parseHelmValues(filePath, chart, content string) (models.DeployManifest, bool) {
+	docs := documents(content)
+	if len(docs) == 0 {
+		return models.DeployManifest{}, false
+	}
+	values := docs[0]
+
+	m := models.DeployManifest{Path: filePath, Kind: KindHelmValues, Name: chart}
+
+	image := lookup(values, "image")
+	if ref := scalar(image); ref != "" {
+		m.Images = appendUnique(m.Images, ref)
+	} else if repo := scalar(lookup(image, "repository")); repo != "" {
+		if tag := scalar(lookup(image, "tag")); tag != "" {
+			repo += ":" + tag
+		}
+		m.Images = appendUnique(m.Images, repo)
+	}
+
+	if replicas, ok := intValue(lookup(values, "replicaCount")); ok {
+		m.ReplicasMin, m.ReplicasMax = &replicas, &replicas
+	}
+	autoscaling := lookup(values, "autoscaling")
+	if scalar(lookup(autoscaling, "enabled")) == "true" {
+		if v, ok := intValue(lookup(autoscaling, "minReplicas")); ok {
+			m.ReplicasMin = &v
+		}
+		if v, ok := intValue(lookup(autoscaling, "maxReplicas")); ok {
+			m.ReplicasMax = &v
+		}
+	}
+
+	parseResources(&m, lookup(values, "resources"))
+
+	ingress := lookup(values, "ingress")
+	if scalar(lookup(ingress, "enabled")) != "false" {
+		for _, h := range items(lookup(ingress, "hosts")) {
+			host := scalar(h)
+			if host == "" {
+				host = scalar(lookup(h, "host"))
+			}
+			m.IngressHosts = appendUnique(m.IngressHosts, host)
+		}
+	}
+
+	m.LivenessProbe = probe(lookup(values, "livenessProbe"))
+	m.ReadinessProbe = probe(lookup(values, "readinessProbe"))
+
+	sc := lookup(values, "securityContext")
+	m.RunAsNonRoot = boolValue(lookup(sc, "runAsNonRoot"))
+	if m.RunAsNonRoot == nil {
+		m.RunAsNonRoot = boolValue(lookup(lookup(values, "podSecurityContext"), "runAsNonRoot"))
+	}
+	m.ReadOnlyRootFilesystem = boolValue(lookup(sc, "readOnlyRootFilesystem"))
+	return m, true
+}
+
+func parseResources(m *models.DeployManifest, resources *yaml.Node) {
+	requests := lookup(resources, "requests")
+	limits := lookup(resources, "limits")
+	m.CPURequest = scalar(lookup(requests, "cpu"))
+	m.MemoryRequest = scalar(lookup(requests, "memory"))
+	m.CPULimit = scalar(lookup(limits, "cpu"))
+	m.MemoryLimit = scalar(lookup(limits, "memory"))
+}
+
+// probe gir stien for HTTP-prober og typen ellers; tom streng betyr ingen probe
+func probe(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return ""
+	}
+	if http := lookup(node, "httpGet"); http != nil {
+		if p := scalar(lookup(http, "path")); p != "" {
+			return p
+		}
+		return "/"
+	}
+	for _, key := range []string{"tcpSocket", "grpc", "exec"} {
+		if lookup(node, key) != nil {
+			return key
+		}
+	}
+	return ""
+}
+
+// accessRule skriver en NAIS access policy-regel som [cluster/][namespace/]application
+func accessRule(rule *yaml.Node) string {
+	var parts []string
+	for _, key := range []string{"cluster", "namespace", "application"} {
+		if v := scalar(lookup(rule, key)); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func chartName(content string) string {
+	docs := documents(content)
+	if len(docs) == 0 {
+		return ""
+	}
+	return scalar(lookup(docs[0], "name"))
+}
+
+// hostOf henter vertsnavnet fra en ingress-URL; verdier uten skjema er allerede et vertsnavn
+func hostOf(ingress string) string {
+	if u, err := url.Parse(ingress); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	return ingress
+}
+
+// all slår sammen verdien per container: false hvis noen er false, ellers nil hvis noen mangler
+func all(values []*bool) *bool {
+	var result *bool
+	missing := false
+	for _, v := range values {
+		switch {
+		case v == nil:
+			missing = true
+		case !*v:
+			return v
+		default:
+			result = v
+		}
+	}
+	if missing {
+		return nil
+	}
+	return result
+}
+
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+func items(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+func intValue(node *yaml.Node) (int, bool) {
+	v, err := strconv.Atoi(scalar(node))
+	return v, err == nil
+}
+
+func boolValue(node *yaml.Node) *bool {
+	switch scalar(node) {
+	case "true":
+		v := true
+		return &v
+	case "false":
+		v := false
+		return &v
+	}
+	return nil
+}
+
+func appendUnique(list []string, value string) []string {
+	if value == "" || contains(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func anyManifestDir(dirs []string) bool {
+	for _, d := range dirs {
+		if manifestDirs[d] {
+			return true
+		}
+	}
+	return false
+}
+
+func isYAML(lowerName string) bool {
+	return strings.HasSuffix(lowerName, ".yaml") || strings.HasSuffix(lowerName, ".yml")
+}
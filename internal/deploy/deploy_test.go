@@ -0,0 +1,231 @@
+package deploy_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/deploy"
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+func TestDeploy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Deploy-manifester")
+}
+
+var _ = Describe("SelectPaths", func() {
+	It("velger NAIS-filer, YAML i deploy-mapper og Helm-charts uten templates", func() {
+		paths := []string{
+			"nais.yaml",
+			".nais/dev.yaml",
+			".nais/prod.yml",
+			"apps/api/nais-prod.yaml",
+			"k8s/base/deployment.yaml",
+			"charts/api/Chart.yaml",
+			"charts/api/values.yaml",
+			"charts/api/values-prod.yaml",
+			"charts/api/templates/deployment.yaml",
+			"charts/api/.helmignore",
+			".github/workflows/ci.yml",
+			"docker-compose.yml",
+			"src/main/resources/application.yaml",
+		}
+		Expect(deploy.SelectPaths(paths)).To(Equal([]string{
+			".nais/dev.yaml",
+			".nais/prod.yml",
+			"apps/api/nais-prod.yaml",
+			"charts/api/Chart.yaml",
+			"charts/api/values-prod.yaml",
+			"charts/api/values.yaml",
+			"k8s/base/deployment.yaml",
+			"nais.yaml",
+		}))
+	})
+
+	It("går bare gjennom treet når toppnivået tyder på manifester", func() {
+		Expect(deploy.HasCandidates(nil, []string{".nais", "src"})).To(BeTrue())
+		Expect(deploy.HasCandidates([]string{"nais.yml"}, nil)).To(BeTrue())
+		Expect(deploy.HasCandidates([]string{"go.mod"}, []string{"cmd"})).To(BeFalse())
+	})
+})
+
+var _ = Describe("Parse", func() {
+	It("leser NAIS Application med plassholdere", func() {
+		manifests := deploy.Parse([]models.FileEntry{{Path: ".nais/prod.yaml", Content: `apiVersion: nais.io/v1alpha1
+kind: Application
+metadata:
+  name: minside-api
+  namespace: min-side
+spec:
+  image: {{ image }}
+  replicas:
+    min: 2
+    max: 4
+  resources:
+    requests:
+      cpu: 50m
+      memory: 256Mi
+    limits:
+      memory: 512Mi
+  ingresses:
+    - https://minside-api.intern.nav.no/api
+  liveness:
+    path: /internal/isalive
+  readiness:
+    path: /internal/isready
+  accessPolicy:
+    inbound:
+      rules:
+        - application: minside
+        - application: tms-varsel
+          namespace: min-side
+          cluster: prod-gcp
+    outbound:
+      rules:
+        - application: pdl-api
+          namespace: pdl
+      external:
+        - host: api.github.com
+`}})
+		Expect(manifests).To(HaveLen(1))
+		m := manifests[0]
+		Expect(m.Kind).To(Equal(deploy.KindNaisApplication))
+		Expect(m.Name).To(Equal("minside-api"))
+		Expect(m.Namespace).To(Equal("min-side"))
+		Expect(m.Images).To(Equal([]string{"<image>"}))
+		Expect(*m.ReplicasMin).To(Equal(2))
+		Expect(*m.ReplicasMax).To(Equal(4))
+		Expect(m.CPURequest).To(Equal("50m"))
+		Expect(m.CPULimit).To(BeEmpty())
+		Expect(m.MemoryLimit).To(Equal("512Mi"))
+		Expect(m.IngressHosts).To(Equal([]string{"minside-api.intern.nav.no"}))
+		Expect(m.InboundAccess).To(Equal([]string{"minside", "prod-gcp/min-side/tms-varsel"}))
+		Expect(m.OutboundAccess).To(Equal([]string{"pdl/pdl-api", "api.github.com"}))
+		Expect(m.LivenessProbe).To(Equal("/internal/isalive"))
+		Expect(m.ReadinessProbe).To(Equal("/internal/isready"))
+		Expect(m.RunAsNonRoot).To(BeNil())
+	})
+
+	It("leser Kubernetes-workloads og ingresser fra filer med flere dokumenter", func() {
+		manifests := deploy.Parse([]models.FileEntry{{Path: "k8s/app.yaml", Content: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 3
+  template:
+    spec:
+      securityContext:
+        runAsNonRoot: true
+      containers:
+        - name: api
+          image: ghcr.io/navikt/api:1.2.3
+          resources:
+            limits:
+              cpu: "1"
+          livenessProbe:
+            httpGet:
+              path: /health
+          readinessProbe:
+            tcpSocket:
+              port: 8080
+          securityContext:
+            readOnlyRootFilesystem: true
+        - name: sidecar
+          image: envoyproxy/envoy:v1.30
+          securityContext:
+            readOnlyRootFilesystem: false
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: api
+spec:
+  rules:
+    - host: api.example.com
+  tls:
+    - hosts: [api.example.com, www.example.com]
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - image: busybox
+              securityContext:
+                runAsNonRoot: false
+`}})
+		Expect(manifests).To(HaveLen(3))
+
+		d := manifests[0]
+		Expect(d.Kind).To(Equal(deploy.KindDeployment))
+		Expect(d.Images).To(Equal([]string{"ghcr.io/navikt/api:1.2.3", "envoyproxy/envoy:v1.30"}))
+		Expect(*d.ReplicasMin).To(Equal(3))
+		Expect(d.CPULimit).To(Equal("1"))
+		Expect(d.LivenessProbe).To(Equal("/health"))
+		Expect(d.ReadinessProbe).To(Equal("tcpSocket"))
+		Expect(*d.RunAsNonRoot).To(BeTrue())
+		Expect(*d.ReadOnlyRootFilesystem).To(BeFalse())
+
+		Expect(manifests[1].Kind).To(Equal(deploy.KindIngress))
+		Expect(manifests[1].IngressHosts).To(Equal([]string{"api.example.com", "www.example.com"}))
+
+		Expect(manifests[2].Kind).To(Equal(deploy.KindCronJob))
+		Expect(manifests[2].Images).To(Equal([]string{"busybox"}))
+		Expect(*manifests[2].RunAsNonRoot).To(BeFalse())
+		Expect(manifests[2].ReadOnlyRootFilesystem).To(BeNil())
+	})
+
+	It("leser values.yaml i Helm-charts med navnet fra Chart.yaml", func() {
+		manifests := deploy.Parse([]models.FileEntry{
+			{Path: "charts/api/Chart.yaml", Content: "apiVersion: v2\nname: api\nversion: 0.1.0\n"},
+			{Path: "charts/api/values.yaml", Content: `replicaCount: 1
+image:
+  repository: ghcr.io/navikt/api
+  tag: "2.0.0"
+autoscaling:
+  enabled: true
+  minReplicas: 2
+  maxReplicas: 6
+ingress:
+  enabled: true
+  hosts:
+    - host: api.local
+podSecurityContext:
+  runAsNonRoot: true
+securityContext:
+  readOnlyRootFilesystem: true
+resources:
+  requests:
+    memory: 128Mi
+`},
+		})
+		Expect(manifests).To(HaveLen(1))
+		m := manifests[0]
+		Expect(m.Kind).To(Equal(deploy.KindHelmValues))
+		Expect(m.Name).To(Equal("api"))
+		Expect(m.Images).To(Equal([]string{"ghcr.io/navikt/api:2.0.0"}))
+		Expect(*m.ReplicasMin).To(Equal(2))
+		Expect(*m.ReplicasMax).To(Equal(6))
+		Expect(m.IngressHosts).To(Equal([]string{"api.local"}))
+		Expect(m.MemoryRequest).To(Equal("128Mi"))
+		Expect(*m.RunAsNonRoot).To(BeTrue())
+		Expect(*m.ReadOnlyRootFilesystem).To(BeTrue())
+	})
+
+	It("hopper over filer som ikke er gyldig YAML", func() {
+		Expect(deploy.Parse([]models.FileEntry{{Path: "k8s/broken.yaml", Content: "kind: [Deployment"}})).To(BeEmpty())
+	})
+})
@@ -0,0 +1,76 @@
+package fetcher
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+	"github.com/jonmartinstorm/reposnusern/internal/registry"
+)
+
+// ResolveBaseImages slår opp hver unike base image-referanse i repoets Dockerfiles
+// mot registryet. Feil lagres per referanse og stopper ikke resten.
+func ResolveBaseImages(ctx context.Context, client *registry.Client, files map[string][]models.FileEntry) []models.BaseImageInfo {
+	refs := map[string]parser.ImageRef{}
+	for key, list := range files {
+		if !strings.HasPrefix(key, "dockerfile") {
+			continue
+		}
+		for _, f := range list {
+			_, stages := parser.ParseDockerfile(f.Content)
+			for _, stage := range stages {
+				if stage.BaseStage != "" || stage.BaseImage == parser.UnresolvedImage {
+					continue
+				}
+				ref, err := parser.ParseImageRef(stage.BaseImage)
+				if err != nil {
+					continue
+				}
+				ref.Tag = stage.BaseTag
+				ref.Digest = stage.Digest
+				refs[reference(ref)] = ref
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(refs))
+	for k := range refs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var result []models.BaseImageInfo
+	for _, key := range keys {
+		ref := refs[key]
+		info := models.BaseImageInfo{
+			Reference:    key,
+			Registry:     ref.Registry,
+			PinnedDigest: ref.Digest,
+		}
+		current, err := client.Resolve(ctx, ref)
+		if err != nil {
+			slog.Warn("Registry-oppslag feilet", "image", key, "error", err)
+			info.Error = err.Error()
+		} else {
+			info.CurrentDigest = current.Digest
+			info.ImageCreated = current.Created
+			info.DigestDiffers = ref.Digest != "" && ref.Tag != "" && ref.Digest != current.Digest
+		}
+		result = append(result, info)
+	}
+	return result
+}
+
+func reference(ref parser.ImageRef) string {
+	s := ref.Name
+	if ref.Tag != "" {
+		s += ":" + ref.Tag
+	}
+	if ref.Digest != "" {
+		s += "@" + ref.Digest
+	}
+	return s
+}
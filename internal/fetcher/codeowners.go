@@ -0,0 +1,121 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/jonmartinstorm/reposnusern/internal/codeowners"
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+// orgDirectory henter team og medlemmer i org én gang per kjøring.
+// Returnerer nil hvis tokenet ikke har tilgang, og da sjekkes ikke eierne.
+func (r *RepoFetcher) orgDirectory(ctx context.Context) *codeowners.Directory {
+	r.directoryOnce.Do(func() {
+		dir, err := FetchOrgDirectory(ctx, r.Cfg.Org, r.Cfg.Token)
+		if err != nil {
+			slog.Warn("Klarte ikke hente team og medlemmer – sjekker ikke CODEOWNERS-eiere", "org", r.Cfg.Org, "error", err)
+			return
+		}
+		r.directory = dir
+	})
+	return r.directory
+}
+
+// FetchOrgDirectory henter alle team og medlemmer i org.
+func FetchOrgDirectory(ctx context.Context, org, token string) (*codeowners.Directory, error) {
+	dir := &codeowners.Directory{Org: org, Teams: map[string]bool{}, Users: map[string]bool{}}
+
+	for _, list := range []struct {
+		path  string
+		field string
+		into  map[string]bool
+	}{
+		{"teams", "slug", dir.Teams},
+		{"members", "login", dir.Users},
+	} {
+		for page := 1; ; page++ {
+			url := fmt.Sprintf("https://api.github.com/orgs/%s/%s?per_page=100&page=%d", org, list.path, page)
+			var items []map[string]interface{}
+			if err := DoRequestWithRateLimit(ctx, "GET", url, token, nil, &items); err != nil {
+				return nil, fmt.Errorf("hent %s: %w", list.path, err)
+			}
+			for _, item := range items {
+				if name, _ := item[list.field].(string); name != "" {
+					list.into[strings.ToLower(name)] = true
+				}
+			}
+			if len(items) < 100 {
+				break
+			}
+		}
+	}
+	return dir, nil
+}
+
+// AnalyzeCodeowners parser CODEOWNERS og sjekker hvem som eier mappene på toppnivå
+// og filene vi har hentet. dir kan være nil, og da sjekkes ikke eierne mot org.
+func AnalyzeCodeowners(entry *models.RepoEntry, dir *codeowners.Directory) models.CodeownersReport {
+	var report models.CodeownersReport
+	var rules []codeowners.Rule
+	for _, loc := range codeowners.Locations {
+		if files := entry.Files[strings.ToLower(loc)]; len(files) > 0 {
+			report.File = files[0].Path
+			rules = codeowners.Parse(files[0].Content)
+			break
+		}
+	}
+
+	for _, rule := range rules {
+		row := models.CodeownersRule{
+			Line:    rule.Line,
+			Pattern: rule.Pattern,
+			Owners:  rule.Owners,
+			Error:   rule.Error,
+		}
+		if dir != nil && rule.Error == "" {
+			// Tom liste, ikke nil, så writerne kan skille "sjekket" fra "ikke sjekket"
+			row.UnknownOwners = append([]string{}, dir.UnknownOwners(rule.Owners)...)
+		}
+		report.Rules = append(report.Rules, row)
+	}
+
+	var paths []models.CodeownersPath
+	for _, d := range entry.RootDirs {
+		paths = append(paths, models.CodeownersPath{Path: d, IsDir: true})
+	}
+	var files []string
+	for _, list := range entry.Files {
+		for _, f := range list {
+			files = append(files, f.Path)
+		}
+	}
+	for _, f := range entry.CIConfig {
+		files = append(files, f.Path)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		if f != report.File {
+			paths = append(paths, models.CodeownersPath{Path: f})
+		}
+	}
+
+	owned := 0
+	for i := range paths {
+		if rule := codeowners.Match(rules, paths[i].Path, paths[i].IsDir); rule != nil {
+			paths[i].RuleLine = rule.Line
+			paths[i].Owners = rule.Owners
+			if len(rule.Owners) > 0 {
+				owned++
+			}
+		}
+	}
+	report.Paths = paths
+	if len(paths) > 0 {
+		report.OwnedPercent = float64(owned) * 100 / float64(len(paths))
+	}
+	return report
+}
@@ -5,21 +5,44 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/jonmartinstorm/reposnusern/internal/catalog"
+	"github.com/jonmartinstorm/reposnusern/internal/codeowners"
+	"github.com/jonmartinstorm/reposnusern/internal/compose"
 	"github.com/jonmartinstorm/reposnusern/internal/config"
+	"github.com/jonmartinstorm/reposnusern/internal/dependencies"
+	"github.com/jonmartinstorm/reposnusern/internal/deploy"
+	"github.com/jonmartinstorm/reposnusern/internal/frameworks"
+	"github.com/jonmartinstorm/reposnusern/internal/iac"
+	"github.com/jonmartinstorm/reposnusern/internal/linter"
 	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/readme"
+	"github.com/jonmartinstorm/reposnusern/internal/registry"
+	"github.com/jonmartinstorm/reposnusern/internal/sbom"
+	"github.com/jonmartinstorm/reposnusern/internal/secrets"
+	"github.com/jonmartinstorm/reposnusern/internal/similarity"
+	"github.com/jonmartinstorm/reposnusern/internal/toolchain"
+	"github.com/jonmartinstorm/reposnusern/internal/updatebot"
+	"github.com/jonmartinstorm/reposnusern/internal/workflow"
 )
 
 type RepoFetcher struct {
-	Cfg config.Config
+	Cfg      config.Config
+	Registry *registry.Client // nil når registry-oppslag er slått av
+
+	directoryOnce sync.Once
+	directory     *codeowners.Directory
 }
 
 type TreeFile struct {
@@ -32,9 +55,13 @@ type TreeFile struct {
 var HttpClient = http.DefaultClient
 
 func NewRepoFetcher(cfg config.Config) *RepoFetcher {
-	return &RepoFetcher{
+	r := &RepoFetcher{
 		Cfg: cfg,
 	}
+	if cfg.RegistryLookup {
+		r.Registry = registry.NewClient(registry.ParseCredentials(cfg.RegistryAuth), cfg.RegistryPlainHTTP)
+	}
+	return r
 }
 
 func (r *RepoFetcher) GetReposPage(ctx context.Context, cfg config.Config, page int) ([]models.RepoMeta, error) {
@@ -50,6 +77,23 @@ func (r *RepoFetcher) GetReposPage(ctx context.Context, cfg config.Config, page
 	return pageRepos, nil
 }
 
+// LookupRepoByID slår opp et repo på stabil ID, også etter rename eller overføring.
+// Returnerer nil uten feil når repoet er slettet eller vi ikke har tilgang.
+func (r *RepoFetcher) LookupRepoByID(ctx context.Context, id int64) (*models.RepoMeta, error) {
+	url := fmt.Sprintf("https://api.github.com/repositories/%d", id)
+
+	var repo models.RepoMeta
+	err := DoRequestWithRateLimit(ctx, "GET", url, r.Cfg.Token, nil, &repo)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusForbidden) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &repo, nil
+}
+
 func (r *RepoFetcher) FetchRepoGraphQL(ctx context.Context, baseRepo models.RepoMeta) (*models.RepoEntry, error) {
 	query := BuildRepoQuery(r.Cfg.Org, baseRepo.Name)
 
@@ -77,10 +121,17 @@ func (r *RepoFetcher) FetchRepoGraphQL(ctx context.Context, baseRepo models.Repo
 		return nil, fmt.Errorf("ingen repository-data for %s/%s", r.Cfg.Org, baseRepo.Name)
 	}
 
-	sbom := fetchSBOM(ctx, r.Cfg.Org, baseRepo.Name, r.Cfg.Token)
+	githubSBOM := fetchSBOM(ctx, r.Cfg.Org, baseRepo.Name, r.Cfg.Token)
 	entry := ParseRepoData(data, baseRepo)
+	r.fetchTruncatedFiles(ctx, entry)
 
-	entry.SBOM = sbom
+	if repoData, ok := data["repository"].(map[string]interface{}); ok {
+		r.fetchRemainingLanguages(ctx, entry, ExtractLanguagesPageInfo(repoData))
+	}
+
+	if entry.Repo.IsFork {
+		r.fetchForkLag(ctx, &entry.Repo)
+	}
 
 	if IsMonorepoCandidate(entry) {
 		slog.Info("Monorepo-kandidat – henter dype Dockerfiles", "repo", baseRepo.FullName)
@@ -89,36 +140,187 @@ func (r *RepoFetcher) FetchRepoGraphQL(ctx context.Context, baseRepo models.Repo
 		entry.Files["dockerfile"] = append(entry.Files["dockerfile"], files...)
 	}
 
+	for key, files := range FetchTreeFilesREST(ctx, r.Cfg.Org, baseRepo.Name, r.Cfg.Token, entry.RootFiles, entry.RootDirs) {
+		entry.Files[key] = files
+	}
+
+	if githubSBOM != nil {
+		entry.SBOM = githubSBOM
+		entry.SBOMSource = sbom.SourceGitHub
+	}
+	AnalyzeEntry(ctx, entry, linter.New(r.Cfg.LintEnable, r.Cfg.LintDisable, catalog.Default()), r.orgDirectory(ctx), r.Registry)
+
 	return entry, nil
 }
 
+// AnalyzeEntry fyller ut alt som utledes fra de hentede filene. Hemmelighetene byttes ut
+// først, så ingen analyse og ingen writer ser dem. Registry-oppslag hoppes over når client er nil.
+func AnalyzeEntry(ctx context.Context, entry *models.RepoEntry, l *linter.Linter, dir *codeowners.Directory, client *registry.Client) {
+	entry.SecretFindings = secrets.RedactEntry(entry)
+
+	if entry.SBOM == nil {
+		// Dependency graph er ofte slått av på private repos – bygg SBOM fra låsefilene i stedet
+		if local := sbom.BuildLocal(entry.Repo.FullName, entry.Files); local != nil {
+			slog.Info("Bruker lokalt generert SBOM", "repo", entry.Repo.FullName)
+			entry.SBOM = local
+			entry.SBOMSource = sbom.SourceLocal
+		}
+	}
+
+	entry.DockerfileFindings = LintDockerfiles(l, entry.Files)
+	entry.WorkflowFindings = CheckWorkflows(entry.Repo, entry.CIConfig)
+	entry.UpdateBots, entry.EcosystemCoverage = updatebot.Analyze(entry.Files, entry.RootFiles, entry.CIConfig)
+	entry.Codeowners = AnalyzeCodeowners(entry, dir)
+	entry.Dependencies = dependencies.Extract(entry.Files)
+	entry.Frameworks = frameworks.Detect(entry)
+	entry.RuntimeVersions = toolchain.Versions(entry)
+	entry.DeployManifests = deploy.Parse(entry.Files[deploy.FilesKey])
+	entry.IaCModules = iac.Parse(entry.Files[iac.FilesKey])
+	entry.ComposeServices = compose.Parse(entry.Files)
+	entry.ReadmeMetrics = readme.Analyze(entry.Repo.Readme, entry.Repo.FullName)
+	if client != nil {
+		entry.BaseImages = ResolveBaseImages(ctx, client, entry.Files)
+	}
+	entry.FileFingerprints = similarity.Fingerprints(entry)
+}
+
+// CheckWorkflows kjører sikkerhetssjekkene på alle workflows i repoet.
+// Workflows som ikke lar seg parse hoppes over, de ligger fortsatt i ci_configs.
+func CheckWorkflows(repo models.RepoMeta, files []models.FileEntry) []models.WorkflowFinding {
+	ctx := workflow.Context{Owner: ownerOf(repo.FullName), Public: !repo.Private}
+
+	var findings []models.WorkflowFinding
+	for _, f := range files {
+		wf, err := workflow.Parse(f.Content)
+		if err != nil {
+			continue
+		}
+		for _, finding := range workflow.Check(wf, ctx) {
+			findings = append(findings, models.WorkflowFinding{
+				Path:        f.Path,
+				RuleID:      finding.RuleID,
+				Severity:    finding.Severity,
+				Description: finding.Description,
+				Job:         finding.Job,
+				Line:        finding.Line,
+			})
+		}
+	}
+	return findings
+}
+
+// LintDockerfiles kjører linteren på alle Dockerfiles i repoet.
+func LintDockerfiles(l *linter.Linter, files map[string][]models.FileEntry) []models.DockerfileFinding {
+	var findings []models.DockerfileFinding
+	for key, list := range files {
+		if !strings.HasPrefix(key, "dockerfile") {
+			continue
+		}
+		for _, f := range list {
+			for _, finding := range l.Lint(f.Content) {
+				findings = append(findings, models.DockerfileFinding{
+					Path:        f.Path,
+					RuleID:      finding.RuleID,
+					Severity:    string(finding.Severity),
+					Description: finding.Description,
+					StartLine:   finding.StartLine,
+					EndLine:     finding.EndLine,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// fetchTruncatedFiles henter avkortede filer via contents-API-et når de er under
+// konfigurert maksstørrelse. Filer som ikke kan hentes blir stående i SkippedFiles.
+func (r *RepoFetcher) fetchTruncatedFiles(ctx context.Context, entry *models.RepoEntry) {
+	var stillSkipped []models.SkippedFile
+
+	maxBytes := r.Cfg.MaxFileBytes
+	if maxBytes <= 0 {
+		maxBytes = config.DefaultMaxFileBytes
+	}
+
+	for _, skipped := range entry.SkippedFiles {
+		if skipped.Reason != SkipReasonTruncated {
+			stillSkipped = append(stillSkipped, skipped)
+			continue
+		}
+		if skipped.ByteSize > maxBytes {
+			skipped.Reason = SkipReasonTooLarge
+			stillSkipped = append(stillSkipped, skipped)
+			continue
+		}
+
+		content, err := FetchRawFile(ctx, r.Cfg.Org, entry.Repo.Name, r.Cfg.Token, skipped.Path, maxBytes)
+		if err != nil {
+			slog.Warn("Klarte ikke hente avkortet fil", "repo", entry.Repo.FullName, "path", skipped.Path, "error", err)
+			skipped.Reason = SkipReasonFetchFailed
+			stillSkipped = append(stillSkipped, skipped)
+			continue
+		}
+
+		file := models.FileEntry{Path: skipped.Path, Content: content}
+		switch {
+		case skipped.Path == "README.md":
+			entry.Repo.Readme = content
+		case strings.HasPrefix(skipped.Path, ".github/workflows/"):
+			entry.CIConfig = append(entry.CIConfig, file)
+		default:
+			key := strings.ToLower(skipped.Path)
+			entry.Files[key] = append(entry.Files[key], file)
+		}
+	}
+
+	entry.SkippedFiles = stillSkipped
+}
+
 func DoRequestWithRateLimit(ctx context.Context, method, url, token string, body []byte, out interface{}) error {
+	resp, err := doRequest(ctx, method, url, token, "application/vnd.github+json", body)
+	if err != nil {
+		return err
+	}
+	defer closeBody(resp)
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// APIError er et svar fra GitHub med status utenfor 2xx.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitHub API-feil: status %d – %s", e.StatusCode, e.Body)
+}
+
+// doRequest gjør et kall mot GitHub, venter ved rate limit og returnerer en 2xx-respons.
+// Kalleren må lukke body.
+func doRequest(ctx context.Context, method, url, token, accept string, body []byte) (*http.Response, error) {
 	for {
 		slog.Info("Henter URL", "url", url)
 
 		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
 		if err != nil {
-			return err
+			return nil, err
 		}
 		req.Header.Set("Authorization", "Bearer "+token)
-		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("Accept", accept)
 		if method == "POST" {
 			req.Header.Set("Content-Type", "application/json")
 		}
 
 		resp, err := HttpClient.Do(req)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		defer func() {
-			if err := resp.Body.Close(); err != nil {
-				log.Printf("advarsel: klarte ikke å lukke body: %v", err)
-			}
-		}()
 
 		if rl := resp.Header.Get("X-RateLimit-Remaining"); rl == "0" {
 			reset := resp.Header.Get("X-RateLimit-Reset")
 			if ts, err := strconv.ParseInt(reset, 10, 64); err == nil {
+				closeBody(resp)
 				wait := time.Until(time.Unix(ts, 0)) + time.Second
 				slog.Warn("Rate limit nådd", "venter", wait.Truncate(time.Second))
 				time.Sleep(wait)
@@ -128,11 +330,18 @@ func DoRequestWithRateLimit(ctx context.Context, method, url, token string, body
 
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			bodyBytes, _ := io.ReadAll(resp.Body)
+			closeBody(resp)
 			slog.Error("GitHub-feil", "status", resp.StatusCode, "body", string(bodyBytes))
-			return fmt.Errorf("GitHub API-feil: status %d – %s", resp.StatusCode, string(bodyBytes))
+			return nil, &APIError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
 		}
 
-		return json.NewDecoder(resp.Body).Decode(out)
+		return resp, nil
+	}
+}
+
+func closeBody(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		log.Printf("advarsel: klarte ikke å lukke body: %v", err)
 	}
 }
 
@@ -158,12 +367,21 @@ func ParseRepoData(data map[string]interface{}, baseRepo models.RepoMeta) *model
 	updatedRepo := baseRepo
 	updatedRepo.Readme = ExtractReadme(repoData)
 	updatedRepo.Security = ExtractSecurity(repoData)
+	updatedRepo.Lineage = ExtractLineage(repoData, ownerOf(baseRepo.FullName))
+	if updatedRepo.DefaultBranch == "" {
+		if ref, ok := repoData["defaultBranchRef"].(map[string]interface{}); ok {
+			updatedRepo.DefaultBranch, _ = ref["name"].(string)
+		}
+	}
 
 	return &models.RepoEntry{
-		Repo:      updatedRepo,
-		Languages: ExtractLanguages(repoData),
-		Files:     ExtractFiles(repoData),
-		CIConfig:  ExtractCI(repoData),
+		Repo:         updatedRepo,
+		Languages:    ExtractLanguages(repoData),
+		Files:        ExtractFiles(repoData),
+		CIConfig:     ExtractCI(repoData),
+		SkippedFiles: ExtractSkippedFiles(repoData),
+		RootFiles:    ExtractRootFiles(repoData),
+		RootDirs:     ExtractRootDirs(repoData),
 	}
 }
 
@@ -191,6 +409,120 @@ func IsMonorepoCandidate(entry *models.RepoEntry) bool {
 	return noDockerfiles && (langs > 0 || hasMatrix || hasSecuritySignals)
 }
 
+// Årsaker til at en ønsket fil ikke ble lagret med innhold.
+const (
+	SkipReasonBinary      = "binary"
+	SkipReasonTruncated   = "truncated"
+	SkipReasonTooLarge    = "too_large"
+	SkipReasonFetchFailed = "fetch_failed"
+)
+
+// blobText returnerer teksten i et GraphQL Blob-objekt, men bare hvis den er komplett.
+// GitHub gir null-tekst for binærfiler og avkortet tekst for store filer.
+func blobText(obj map[string]interface{}) string {
+	if truncated, _ := obj["isTruncated"].(bool); truncated {
+		return ""
+	}
+	text, _ := obj["text"].(string)
+	return text
+}
+
+// blobSkipReason sier hvorfor en blob mangler komplett tekst, eller "" hvis den er lesbar.
+func blobSkipReason(obj map[string]interface{}) string {
+	if binary, _ := obj["isBinary"].(bool); binary {
+		return SkipReasonBinary
+	}
+	if truncated, _ := obj["isTruncated"].(bool); truncated {
+		return SkipReasonTruncated
+	}
+	// Null-tekst uten binærflagg betyr at GitHub ikke ville levere innholdet
+	if _, ok := obj["text"].(string); !ok {
+		if _, hasSize := obj["byteSize"]; hasSize {
+			return SkipReasonTruncated
+		}
+	}
+	return ""
+}
+
+func blobSize(obj map[string]interface{}) int64 {
+	size, _ := obj["byteSize"].(float64)
+	return int64(size)
+}
+
+// ExtractSkippedFiles finner ønskede filer (README, workflows, konfigfiler under .github,
+// Dockerfiles og låsefiler) som GitHub ikke leverte komplett tekst for.
+func ExtractSkippedFiles(data map[string]interface{}) []models.SkippedFile {
+	var skipped []models.SkippedFile
+
+	add := func(path string, obj map[string]interface{}) {
+		if reason := blobSkipReason(obj); reason != "" {
+			skipped = append(skipped, models.SkippedFile{
+				Path:     path,
+				Reason:   reason,
+				ByteSize: blobSize(obj),
+			})
+		}
+	}
+
+	if readme, ok := data["README"].(map[string]interface{}); ok {
+		add("README.md", readme)
+	}
+
+	configKeys := make([]string, 0, len(githubConfigFiles))
+	for key := range githubConfigFiles {
+		configKeys = append(configKeys, key)
+	}
+	sort.Strings(configKeys)
+	for _, key := range configKeys {
+		if obj, ok := data[key].(map[string]interface{}); ok {
+			add(githubConfigFiles[key], obj)
+		}
+	}
+
+	for _, key := range []string{"workflows", "dependencies"} {
+		tree, ok := data[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entries, _ := tree["entries"].([]interface{})
+		for _, raw := range entries {
+			entry, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := entry["name"].(string)
+			obj, ok := entry["object"].(map[string]interface{})
+			if !ok || name == "" {
+				continue
+			}
+			if key == "workflows" {
+				add(".github/workflows/"+name, obj)
+			} else if isWantedRootFile(strings.ToLower(name)) {
+				add(name, obj)
+			}
+		}
+	}
+	return skipped
+}
+
+func isWantedRootFile(lowerName string) bool {
+	return strings.Contains(lowerName, "dockerfile") || sbom.IsLockfile(lowerName) || updatebot.IsConfigFile(lowerName) ||
+		toolchain.IsVersionFile(lowerName) || dependencies.IsManifest(lowerName) || compose.IsComposeFile(lowerName)
+}
+
+// Konfigfiler som hentes som egne objekter i GraphQL-spørringen, med stien de har i repoet
+var githubConfigFiles = map[string]string{
+	"dependabot":       ".github/dependabot.yml",
+	"dependabotYaml":   ".github/dependabot.yaml",
+	"renovateGithub":   ".github/renovate.json",
+	"renovateGithub5":  ".github/renovate.json5",
+	"codeownersGithub": ".github/CODEOWNERS",
+	"codeownersRoot":   "CODEOWNERS",
+	"codeownersDocs":   "docs/CODEOWNERS",
+	"versionCatalog":   dependencies.VersionCatalog,
+	"codeql":           ".github/codeql.yml",
+}
+
 func ExtractLanguages(data map[string]interface{}) map[string]int {
 	langs := map[string]int{}
 
@@ -223,10 +555,55 @@ func ExtractLanguages(data map[string]interface{}) map[string]int {
 	return langs
 }
 
+// ExtractLanguagesPageInfo returnerer cursor for neste side med språk, eller "" hvis alle er hentet.
+func ExtractLanguagesPageInfo(data map[string]interface{}) string {
+	langsData, ok := data["languages"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	pageInfo, ok := langsData["pageInfo"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if hasNext, _ := pageInfo["hasNextPage"].(bool); !hasNext {
+		return ""
+	}
+	cursor, _ := pageInfo["endCursor"].(string)
+	return cursor
+}
+
+// fetchRemainingLanguages blar gjennom resten av språkene når et repo har flere enn én side.
+func (r *RepoFetcher) fetchRemainingLanguages(ctx context.Context, entry *models.RepoEntry, cursor string) {
+	for cursor != "" {
+		bodyBytes, err := json.Marshal(map[string]string{
+			"query": BuildLanguagesQuery(r.Cfg.Org, entry.Repo.Name, cursor),
+		})
+		if err != nil {
+			return
+		}
+
+		var result map[string]interface{}
+		if err := DoRequestWithRateLimit(ctx, "POST", "https://api.github.com/graphql", r.Cfg.Token, bodyBytes, &result); err != nil {
+			slog.Warn("Klarte ikke hente flere språk", "repo", entry.Repo.FullName, "error", err)
+			return
+		}
+
+		data, _ := result["data"].(map[string]interface{})
+		repoData, ok := data["repository"].(map[string]interface{})
+		if !ok {
+			return
+		}
+		for lang, size := range ExtractLanguages(repoData) {
+			entry.Languages[lang] = size
+		}
+		cursor = ExtractLanguagesPageInfo(repoData)
+	}
+}
+
 func ExtractFiles(data map[string]interface{}) map[string][]models.FileEntry {
 	files := map[string][]map[string]string{}
 
-	// Dependency files
+	// Dockerfiles, låsefiler, Renovate-konfig, versjonsfiler og Compose-filer på toppnivå
 	if deps, ok := data["dependencies"].(map[string]interface{}); ok {
 		if entries, ok := deps["entries"].([]interface{}); ok {
 			for _, raw := range entries {
@@ -238,15 +615,13 @@ func ExtractFiles(data map[string]interface{}) map[string][]models.FileEntry {
 				name, _ := entry["name"].(string)
 				lowerName := strings.ToLower(name)
 
-				if !strings.Contains(lowerName, "dockerfile") {
+				if !isWantedRootFile(lowerName) {
 					continue
 				}
 
 				var content string
 				if obj, ok := entry["object"].(map[string]interface{}); ok {
-					if text, ok := obj["text"].(string); ok {
-						content = text
-					}
+					content = blobText(obj)
 				}
 
 				if content != "" {
@@ -258,9 +633,50 @@ func ExtractFiles(data map[string]interface{}) map[string][]models.FileEntry {
 			}
 		}
 	}
+
+	for key, path := range githubConfigFiles {
+		if obj, ok := data[key].(map[string]interface{}); ok {
+			if content := blobText(obj); content != "" {
+				key := strings.ToLower(path)
+				files[key] = append(files[key], map[string]string{
+					"path":    path,
+					"content": content,
+				})
+			}
+		}
+	}
 	return ConvertFiles(files)
 }
 
+// ExtractRootFiles returnerer navnet på alle filer på toppnivå.
+func ExtractRootFiles(data map[string]interface{}) []string {
+	return rootEntries(data, false)
+}
+
+// ExtractRootDirs returnerer navnet på alle mapper på toppnivå.
+func ExtractRootDirs(data map[string]interface{}) []string {
+	return rootEntries(data, true)
+}
+
+func rootEntries(data map[string]interface{}, dirs bool) []string {
+	var names []string
+	if deps, ok := data["dependencies"].(map[string]interface{}); ok {
+		entries, _ := deps["entries"].([]interface{})
+		for _, raw := range entries {
+			entry, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := entry["name"].(string)
+			entryType, _ := entry["type"].(string)
+			if name != "" && (entryType == "tree") == dirs {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
 func ExtractCI(data map[string]interface{}) []models.FileEntry {
 	ci := []map[string]string{}
 	// CI config
@@ -273,12 +689,10 @@ func ExtractCI(data map[string]interface{}) []models.FileEntry {
 				}
 				name, _ := entry["name"].(string)
 
-				// Hent .object.text hvis det finnes og er string
+				// Hent .object.text hvis det finnes og ikke er avkortet
 				var content string
 				if obj, ok := entry["object"].(map[string]interface{}); ok {
-					if text, ok := obj["text"].(string); ok {
-						content = text
-					}
+					content = blobText(obj)
 				}
 
 				// Bare legg til hvis det finnes
@@ -294,19 +708,73 @@ func ExtractCI(data map[string]interface{}) []models.FileEntry {
 	return ConvertToFileEntries(ci)
 }
 
+// ExtractLineage henter upstream-repo for forks og mal-repo for template-genererte repos.
+// org brukes til å avgjøre om opphavet ligger i samme organisasjon.
+func ExtractLineage(data map[string]interface{}, org string) models.RepoLineage {
+	var lineage models.RepoLineage
+
+	if parent, ok := data["parent"].(map[string]interface{}); ok {
+		lineage.ParentFullName, _ = parent["nameWithOwner"].(string)
+		lineage.ParentInOrg = strings.EqualFold(loginOf(parent), org)
+		if ref, ok := parent["defaultBranchRef"].(map[string]interface{}); ok {
+			lineage.ParentDefaultBranch, _ = ref["name"].(string)
+		}
+	}
+
+	if tmpl, ok := data["templateRepository"].(map[string]interface{}); ok {
+		lineage.TemplateFullName, _ = tmpl["nameWithOwner"].(string)
+		lineage.TemplateInOrg = strings.EqualFold(loginOf(tmpl), org)
+	}
+
+	return lineage
+}
+
+func loginOf(repo map[string]interface{}) string {
+	owner, _ := repo["owner"].(map[string]interface{})
+	login, _ := owner["login"].(string)
+	return login
+}
+
+func ownerOf(fullName string) string {
+	owner, _, _ := strings.Cut(fullName, "/")
+	return owner
+}
+
+// fetchForkLag sammenligner forkens default branch med parent sin, og setter hvor
+// mange commits forken er foran og bak.
+func (r *RepoFetcher) fetchForkLag(ctx context.Context, repo *models.RepoMeta) {
+	lineage := &repo.Lineage
+	if lineage.ParentFullName == "" || lineage.ParentDefaultBranch == "" || repo.DefaultBranch == "" {
+		return
+	}
+
+	url := fmt.Sprintf("https://api.github.com/repos/%s/compare/%s...%s:%s",
+		lineage.ParentFullName, lineage.ParentDefaultBranch, ownerOf(repo.FullName), repo.DefaultBranch)
+
+	var comparison struct {
+		AheadBy  int `json:"ahead_by"`
+		BehindBy int `json:"behind_by"`
+	}
+	if err := DoRequestWithRateLimit(ctx, "GET", url, r.Cfg.Token, nil, &comparison); err != nil {
+		slog.Warn("Klarte ikke sammenligne fork med parent", "repo", repo.FullName, "parent", lineage.ParentFullName, "error", err)
+		return
+	}
+
+	lineage.AheadBy = &comparison.AheadBy
+	lineage.BehindBy = &comparison.BehindBy
+}
+
 func ExtractSecurity(data map[string]interface{}) map[string]bool {
 	security := map[string]bool{}
 	security["has_security_md"] = data["SECURITY"] != nil
-	security["has_dependabot"] = data["dependabot"] != nil
+	security["has_dependabot"] = data["dependabot"] != nil || data["dependabotYaml"] != nil
 	security["has_codeql"] = data["codeql"] != nil
 	return security
 }
 
 func ExtractReadme(data map[string]interface{}) string {
 	if val, ok := data["README"].(map[string]interface{}); ok {
-		if text, ok := val["text"].(string); ok {
-			return text
-		}
+		return blobText(val)
 	}
 	return ""
 }
@@ -318,23 +786,106 @@ func BuildRepoQuery(owner string, name string) string {
 			defaultBranchRef {
 				name
 			}
+			parent {
+				nameWithOwner
+				owner {
+					login
+				}
+				defaultBranchRef {
+					name
+				}
+			}
+			templateRepository {
+				nameWithOwner
+				owner {
+					login
+				}
+			}
 			README: object(expression: "HEAD:README.md") {
 				... on Blob {
+					byteSize
+					isBinary
+					isTruncated
 					text
 				}
 			}
 			SECURITY: object(expression: "HEAD:SECURITY.md") {
 				... on Blob {
+					byteSize
+					isBinary
+					isTruncated
 					text
 				}
 			}
 			dependabot: object(expression: "HEAD:.github/dependabot.yml") {
 				... on Blob {
+					byteSize
+					isBinary
+					isTruncated
+					text
+				}
+			}
+			dependabotYaml: object(expression: "HEAD:.github/dependabot.yaml") {
+				... on Blob {
+					byteSize
+					isBinary
+					isTruncated
+					text
+				}
+			}
+			renovateGithub: object(expression: "HEAD:.github/renovate.json") {
+				... on Blob {
+					byteSize
+					isBinary
+					isTruncated
+					text
+				}
+			}
+			renovateGithub5: object(expression: "HEAD:.github/renovate.json5") {
+				... on Blob {
+					byteSize
+					isBinary
+					isTruncated
+					text
+				}
+			}
+			codeownersGithub: object(expression: "HEAD:.github/CODEOWNERS") {
+				... on Blob {
+					byteSize
+					isBinary
+					isTruncated
+					text
+				}
+			}
+			codeownersRoot: object(expression: "HEAD:CODEOWNERS") {
+				... on Blob {
+					byteSize
+					isBinary
+					isTruncated
+					text
+				}
+			}
+			codeownersDocs: object(expression: "HEAD:docs/CODEOWNERS") {
+				... on Blob {
+					byteSize
+					isBinary
+					isTruncated
+					text
+				}
+			}
+			versionCatalog: object(expression: "HEAD:gradle/libs.versions.toml") {
+				... on Blob {
+					byteSize
+					isBinary
+					isTruncated
 					text
 				}
 			}
 			codeql: object(expression: "HEAD:.github/codeql.yml") {
 				... on Blob {
+					byteSize
+					isBinary
+					isTruncated
 					text
 				}
 			}
@@ -344,6 +895,9 @@ func BuildRepoQuery(owner string, name string) string {
 						name
 						object {
 							... on Blob {
+								byteSize
+								isBinary
+								isTruncated
 								text
 							}
 						}
@@ -354,15 +908,24 @@ func BuildRepoQuery(owner string, name string) string {
 				... on Tree {
 					entries {
 						name
+						type
 						object {
 							... on Blob {
+								byteSize
+								isBinary
+								isTruncated
 								text
 							}
 						}
 					}
 				}
 			}
-			languages(first: 10) {
+			languages(first: %d, orderBy: {field: SIZE, direction: DESC}) {
+				totalSize
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
 				edges {
 					size
 					node {
@@ -371,10 +934,34 @@ func BuildRepoQuery(owner string, name string) string {
 				}
 			}
 		}
-	}`, owner, name)
+	}`, owner, name, languagesPageSize)
 	return query
 }
 
+// GraphQL tillater maks 100 noder per side.
+const languagesPageSize = 100
+
+// BuildLanguagesQuery henter neste side med språk etter cursor.
+func BuildLanguagesQuery(owner, name, after string) string {
+	return fmt.Sprintf(`
+	{
+		repository(owner: "%s", name: "%s") {
+			languages(first: %d, after: "%s", orderBy: {field: SIZE, direction: DESC}) {
+				pageInfo {
+					hasNextPage
+					endCursor
+				}
+				edges {
+					size
+					node {
+						name
+					}
+				}
+			}
+		}
+	}`, owner, name, languagesPageSize, after)
+}
+
 func ConvertToFileEntries(entries []map[string]string) []models.FileEntry {
 	var result []models.FileEntry
 	for _, e := range entries {
@@ -395,8 +982,62 @@ func ConvertFiles(input map[string][]map[string]string) map[string][]models.File
 }
 
 func FetchDockerfilesFromRepoREST(ctx context.Context, owner, repo, token string) []models.FileEntry {
-	var results []models.FileEntry
+	paths, err := fetchTreePaths(ctx, owner, repo, token)
+	if err != nil {
+		slog.Warn("Klarte ikke hente repo-tree", "repo", owner+"/"+repo, "error", err)
+		return nil
+	}
+
+	var dockerfiles []string
+	for _, p := range paths {
+		if strings.Contains(strings.ToLower(p), "dockerfile") {
+			dockerfiles = append(dockerfiles, p)
+		}
+	}
+	return fetchFiles(ctx, owner, repo, token, dockerfiles)
+}
+
+// treeSelector velger filer fra hele repo-treet til én nøkkel i RepoEntry.Files.
+// hasCandidates ser bare på toppnivået, så treet hentes kun for repoer der noe tyder på treff.
+type treeSelector struct {
+	key           string
+	hasCandidates func(rootFiles, rootDirs []string) bool
+	selectPaths   func(paths []string) []string
+}
+
+var treeSelectors = []treeSelector{
+	{key: deploy.FilesKey, hasCandidates: deploy.HasCandidates, selectPaths: deploy.SelectPaths},
+	{key: iac.FilesKey, hasCandidates: iac.HasCandidates, selectPaths: iac.SelectPaths},
+}
 
+// FetchTreeFilesREST henter repo-treet én gang og henter filene hver selector velger,
+// som deploy-manifester og IaC-filer, gruppert på nøkkel.
+func FetchTreeFilesREST(ctx context.Context, owner, repo, token string, rootFiles, rootDirs []string) map[string][]models.FileEntry {
+	wanted := false
+	for _, s := range treeSelectors {
+		wanted = wanted || s.hasCandidates(rootFiles, rootDirs)
+	}
+	if !wanted {
+		return nil
+	}
+
+	paths, err := fetchTreePaths(ctx, owner, repo, token)
+	if err != nil {
+		slog.Warn("Klarte ikke hente repo-tree", "repo", owner+"/"+repo, "error", err)
+		return nil
+	}
+
+	result := map[string][]models.FileEntry{}
+	for _, s := range treeSelectors {
+		if files := fetchFiles(ctx, owner, repo, token, s.selectPaths(paths)); len(files) > 0 {
+			result[s.key] = files
+		}
+	}
+	return result
+}
+
+// fetchTreePaths returnerer stien til alle filer i repoet via det rekursive git-treet.
+func fetchTreePaths(ctx context.Context, owner, repo, token string) ([]string, error) {
 	treeURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/git/trees/HEAD?recursive=1", owner, repo)
 
 	var tree struct {
@@ -406,24 +1047,27 @@ func FetchDockerfilesFromRepoREST(ctx context.Context, owner, repo, token string
 		} `json:"tree"`
 	}
 
-	err := DoRequestWithRateLimit(ctx, "GET", treeURL, token, nil, &tree)
-	if err != nil {
-		slog.Warn("Klarte ikke hente repo-tree", "repo", owner+"/"+repo, "error", err)
-		return results
+	if err := DoRequestWithRateLimit(ctx, "GET", treeURL, token, nil, &tree); err != nil {
+		return nil, err
 	}
 
+	var paths []string
 	for _, entry := range tree.Tree {
-		if entry.Type != "blob" {
-			continue
-		}
-		if !strings.Contains(strings.ToLower(entry.Path), "dockerfile") {
-			continue
+		if entry.Type == "blob" {
+			paths = append(paths, entry.Path)
 		}
+	}
+	return paths, nil
+}
 
-		content := fetchFileContent(ctx, owner, repo, token, entry.Path)
+// fetchFiles henter innholdet i filene; filer som ikke lar seg hente hoppes over.
+func fetchFiles(ctx context.Context, owner, repo, token string, paths []string) []models.FileEntry {
+	var results []models.FileEntry
+	for _, p := range paths {
+		content := fetchFileContent(ctx, owner, repo, token, p)
 		if content != "" {
 			results = append(results, models.FileEntry{
-				Path:    entry.Path,
+				Path:    p,
 				Content: content,
 			})
 		}
@@ -431,6 +1075,27 @@ func FetchDockerfilesFromRepoREST(ctx context.Context, owner, repo, token string
 	return results
 }
 
+// FetchRawFile henter en fil via contents-API-et som rå bytes, opptil maxBytes.
+// Brukes for filer GraphQL avkortet.
+func FetchRawFile(ctx context.Context, owner, repo, token, path string, maxBytes int64) (string, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", owner, repo, path)
+
+	resp, err := doRequest(ctx, "GET", url, token, "application/vnd.github.raw", nil)
+	if err != nil {
+		return "", err
+	}
+	defer closeBody(resp)
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(content)) > maxBytes {
+		return "", fmt.Errorf("%s er større enn %d bytes", path, maxBytes)
+	}
+	return string(content), nil
+}
+
 func fetchFileContent(ctx context.Context, owner, repo, token, path string) string {
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contents/%s", owner, repo, path)
 
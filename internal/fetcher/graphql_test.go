@@ -2,17 +2,23 @@ package fetcher_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/jonmartinstorm/reposnusern/internal/codeowners"
 	"github.com/jonmartinstorm/reposnusern/internal/fetcher"
+	"github.com/jonmartinstorm/reposnusern/internal/linter"
 	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/registry"
+	"github.com/jonmartinstorm/reposnusern/internal/sbom"
 )
 
 // Ginkgo sin test-runner. Denne trengs for at "go test" skal vite hvor den skal starte.
@@ -57,6 +63,13 @@ var _ = Describe("GraphQL-relaterte hjelpefunksjoner", func() {
 			Expect(query).To(ContainSubstring(`repository(owner: "navikt", name: "arbeidsgiver")`))
 			Expect(query).To(ContainSubstring("defaultBranchRef"))
 		})
+
+		It("skal be om størrelse og binær/avkortet-flagg for blobs", func() {
+			query := fetcher.BuildRepoQuery("navikt", "arbeidsgiver")
+			Expect(query).To(ContainSubstring("byteSize"))
+			Expect(query).To(ContainSubstring("isBinary"))
+			Expect(query).To(ContainSubstring("isTruncated"))
+		})
 	})
 
 	Describe("parseRepoData", func() {
@@ -145,6 +158,39 @@ var _ = Describe("GraphQL-relaterte hjelpefunksjoner", func() {
 		})
 	})
 
+	Describe("extractLanguagesPageInfo", func() {
+		It("skal returnere cursor når det finnes flere sider", func() {
+			data := map[string]interface{}{
+				"languages": map[string]interface{}{
+					"pageInfo": map[string]interface{}{
+						"hasNextPage": true,
+						"endCursor":   "Y3Vyc29yOjEwMA==",
+					},
+				},
+			}
+			Expect(fetcher.ExtractLanguagesPageInfo(data)).To(Equal("Y3Vyc29yOjEwMA=="))
+		})
+
+		It("skal returnere tom streng på siste side", func() {
+			data := map[string]interface{}{
+				"languages": map[string]interface{}{
+					"pageInfo": map[string]interface{}{
+						"hasNextPage": false,
+						"endCursor":   "Y3Vyc29yOjEwMA==",
+					},
+				},
+			}
+			Expect(fetcher.ExtractLanguagesPageInfo(data)).To(BeEmpty())
+			Expect(fetcher.ExtractLanguagesPageInfo(map[string]interface{}{})).To(BeEmpty())
+		})
+
+		It("skal bygge spørring for neste side", func() {
+			query := fetcher.BuildLanguagesQuery("navikt", "arbeidsgiver", "abc")
+			Expect(query).To(ContainSubstring(`after: "abc"`))
+			Expect(query).To(ContainSubstring("first: 100"))
+		})
+	})
+
 	Describe("extractCI", func() {
 		It("skal hente ut CI-workflows med korrekt filsti og innhold", func() {
 			data := map[string]interface{}{
@@ -215,6 +261,34 @@ var _ = Describe("GraphQL-relaterte hjelpefunksjoner", func() {
 		})
 	})
 
+	Describe("extractLineage", func() {
+		It("skal hente parent og template og avgjøre om de ligger i org", func() {
+			data := map[string]interface{}{
+				"parent": map[string]interface{}{
+					"nameWithOwner":    "spring-projects/spring-boot",
+					"owner":            map[string]interface{}{"login": "spring-projects"},
+					"defaultBranchRef": map[string]interface{}{"name": "main"},
+				},
+				"templateRepository": map[string]interface{}{
+					"nameWithOwner": "navikt/template-ktor",
+					"owner":         map[string]interface{}{"login": "navikt"},
+				},
+			}
+			got := fetcher.ExtractLineage(data, "NAVIKT")
+			Expect(got.ParentFullName).To(Equal("spring-projects/spring-boot"))
+			Expect(got.ParentInOrg).To(BeFalse())
+			Expect(got.ParentDefaultBranch).To(Equal("main"))
+			Expect(got.TemplateFullName).To(Equal("navikt/template-ktor"))
+			Expect(got.TemplateInOrg).To(BeTrue())
+			Expect(got.AheadBy).To(BeNil())
+		})
+
+		It("skal gi tomt opphav for vanlige repos", func() {
+			got := fetcher.ExtractLineage(map[string]interface{}{"parent": nil}, "navikt")
+			Expect(got).To(Equal(models.RepoLineage{}))
+		})
+	})
+
 	Describe("extractFiles", func() {
 		It("skal hente ut kun gyldige Dockerfile-objekter med innhold", func() {
 			data := map[string]interface{}{
@@ -246,6 +320,232 @@ var _ = Describe("GraphQL-relaterte hjelpefunksjoner", func() {
 			Expect(got["dockerfile"][0].Path).To(Equal("Dockerfile"))
 			Expect(got["dockerfile"][0].Content).To(Equal("FROM alpine"))
 		})
+
+		It("skal også ta med låsefiler som trengs for lokal SBOM", func() {
+			data := map[string]interface{}{
+				"dependencies": map[string]interface{}{
+					"entries": []interface{}{
+						map[string]interface{}{
+							"name": "go.sum",
+							"object": map[string]interface{}{
+								"text": "github.com/lib/pq v1.10.9 h1:abc=",
+							},
+						},
+					},
+				},
+			}
+			got := fetcher.ExtractFiles(data)
+			Expect(got).To(HaveKey("go.sum"))
+			Expect(got["go.sum"][0].Path).To(Equal("go.sum"))
+		})
+
+		It("skal ta med Compose-filer", func() {
+			data := map[string]interface{}{
+				"dependencies": map[string]interface{}{
+					"entries": []interface{}{
+						map[string]interface{}{"name": "docker-compose.yml", "object": map[string]interface{}{"text": "services: {}"}},
+						map[string]interface{}{"name": "compose.override.yaml", "object": map[string]interface{}{"text": "services: {}"}},
+					},
+				},
+			}
+			got := fetcher.ExtractFiles(data)
+			Expect(got).To(HaveKey("docker-compose.yml"))
+			Expect(got).To(HaveKey("compose.override.yaml"))
+		})
+
+		It("skal ta med konfig for Dependabot og Renovate og versjonsfiler, og liste alle filer på toppnivå", func() {
+			data := map[string]interface{}{
+				"dependabotYaml": map[string]interface{}{"text": "version: 2"},
+				"dependencies": map[string]interface{}{
+					"entries": []interface{}{
+						map[string]interface{}{"name": "renovate.json", "object": map[string]interface{}{"text": "{}"}},
+						map[string]interface{}{"name": "Makefile", "object": map[string]interface{}{"text": "all:"}},
+						map[string]interface{}{"name": ".nvmrc", "object": map[string]interface{}{"text": "20"}},
+						map[string]interface{}{"name": "src"},
+					},
+				},
+			}
+			got := fetcher.ExtractFiles(data)
+			Expect(got).To(HaveKey(".github/dependabot.yaml"))
+			Expect(got).To(HaveKey("renovate.json"))
+			Expect(got).To(HaveKey(".nvmrc"))
+			Expect(got).NotTo(HaveKey("makefile"))
+			Expect(fetcher.ExtractRootFiles(data)).To(Equal([]string{"renovate.json", "Makefile", ".nvmrc", "src"}))
+			Expect(fetcher.ExtractSecurity(data)["has_dependabot"]).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("extractSkippedFiles", func() {
+	It("skal registrere binære og avkortede filer med årsak", func() {
+		data := map[string]interface{}{
+			"README": map[string]interface{}{
+				"text":        "# Start på en lang README",
+				"isTruncated": true,
+				"byteSize":    float64(600000),
+			},
+			"dependencies": map[string]interface{}{
+				"entries": []interface{}{
+					map[string]interface{}{
+						"name": "Dockerfile",
+						"object": map[string]interface{}{
+							"text":     nil,
+							"isBinary": true,
+							"byteSize": float64(42),
+						},
+					},
+					map[string]interface{}{
+						"name": "logo.png",
+						"object": map[string]interface{}{
+							"text":     nil,
+							"isBinary": true,
+						},
+					},
+					map[string]interface{}{
+						"name": "go.sum",
+						"object": map[string]interface{}{
+							"text":        "github.com/lib/pq v1.10.9 h1:abc=",
+							"isTruncated": false,
+						},
+					},
+				},
+			},
+		}
+
+		got := fetcher.ExtractSkippedFiles(data)
+		Expect(got).To(ConsistOf(
+			models.SkippedFile{Path: "README.md", Reason: fetcher.SkipReasonTruncated, ByteSize: 600000},
+			models.SkippedFile{Path: "Dockerfile", Reason: fetcher.SkipReasonBinary, ByteSize: 42},
+		))
+
+		// Avkortet tekst skal ikke lagres som om den var komplett
+		Expect(fetcher.ExtractReadme(data)).To(Equal(""))
+	})
+
+	It("skal registrere avkortede konfigfiler som hentes som egne objekter", func() {
+		data := map[string]interface{}{
+			"versionCatalog": map[string]interface{}{
+				"text":        "[versions]\nkotlin = \"2.0",
+				"isTruncated": true,
+				"isBinary":    false,
+				"byteSize":    float64(210000),
+			},
+			"renovateGithub": map[string]interface{}{
+				"text":     nil,
+				"isBinary": true,
+				"byteSize": float64(12),
+			},
+			"dependabot": map[string]interface{}{
+				"text":     "version: 2\n",
+				"byteSize": float64(11),
+			},
+		}
+
+		Expect(fetcher.ExtractSkippedFiles(data)).To(Equal([]models.SkippedFile{
+			{Path: ".github/renovate.json", Reason: fetcher.SkipReasonBinary, ByteSize: 12},
+			{Path: "gradle/libs.versions.toml", Reason: fetcher.SkipReasonTruncated, ByteSize: 210000},
+		}))
+		Expect(fetcher.ExtractFiles(data)).NotTo(HaveKey("gradle/libs.versions.toml"))
+	})
+})
+
+var _ = Describe("LintDockerfiles", func() {
+	It("linter bare Dockerfiles og tar med stien", func() {
+		files := map[string][]models.FileEntry{
+			"dockerfile": {{Path: "app/Dockerfile", Content: "FROM alpine\nUSER app"}},
+			"go.sum":     {{Path: "go.sum", Content: "FROM alpine"}},
+		}
+		findings := fetcher.LintDockerfiles(linter.New(nil, nil, nil), files)
+		Expect(findings).To(Equal([]models.DockerfileFinding{{
+			Path:        "app/Dockerfile",
+			RuleID:      "DF001",
+			Severity:    "warning",
+			Description: "Base image bruker latest eller mangler tag",
+			StartLine:   1,
+			EndLine:     1,
+		}}))
+	})
+})
+
+var _ = Describe("CheckWorkflows", func() {
+	It("sjekker workflows med eier og synlighet fra repoet", func() {
+		files := []models.FileEntry{
+			{Path: ".github/workflows/ci.yml", Content: "on: push\npermissions: {}\njobs:\n  a:\n    runs-on: self-hosted\n"},
+			{Path: ".github/workflows/ugyldig.yml", Content: "on: [push\n"},
+		}
+
+		findings := fetcher.CheckWorkflows(models.RepoMeta{FullName: "navikt/app"}, files)
+		Expect(findings).To(Equal([]models.WorkflowFinding{{
+			Path:        ".github/workflows/ci.yml",
+			RuleID:      "GHA005",
+			Severity:    "warning",
+			Description: "Self-hosted runner i offentlig repo",
+			Job:         "a",
+			Line:        4,
+		}}))
+
+		Expect(fetcher.CheckWorkflows(models.RepoMeta{FullName: "navikt/app", Private: true}, files)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("AnalyzeCodeowners", func() {
+	entry := &models.RepoEntry{
+		RootDirs: []string{"apps", "docs"},
+		Files: map[string][]models.FileEntry{
+			".github/codeowners": {{Path: ".github/CODEOWNERS", Content: "/apps/ @navikt/team-a @ukjent\n/.github/ @navikt/plattform\n/feil eier-uten-krøllalfa\n"}},
+			"dockerfile":         {{Path: "Dockerfile", Content: "FROM alpine"}},
+		},
+		CIConfig: []models.FileEntry{{Path: ".github/workflows/ci.yml"}},
+	}
+
+	It("regner ut dekning for mapper og hentede filer", func() {
+		dir := &codeowners.Directory{Org: "navikt", Teams: map[string]bool{"team-a": true}, Users: map[string]bool{}}
+		report := fetcher.AnalyzeCodeowners(entry, dir)
+
+		Expect(report.File).To(Equal(".github/CODEOWNERS"))
+		Expect(report.Rules).To(HaveLen(3))
+		Expect(report.Rules[0].UnknownOwners).To(Equal([]string{"@ukjent"}))
+		Expect(report.Rules[1].UnknownOwners).To(Equal([]string{"@navikt/plattform"}))
+		Expect(report.Rules[2].Error).NotTo(BeEmpty())
+
+		Expect(report.Paths).To(Equal([]models.CodeownersPath{
+			{Path: "apps", IsDir: true, Owners: []string{"@navikt/team-a", "@ukjent"}, RuleLine: 1},
+			{Path: "docs", IsDir: true},
+			{Path: ".github/workflows/ci.yml", Owners: []string{"@navikt/plattform"}, RuleLine: 2},
+			{Path: "Dockerfile"},
+		}))
+		Expect(report.OwnedPercent).To(Equal(50.0))
+	})
+
+	It("sjekker ikke eiere uten oversikt over org, og gir 0 % uten CODEOWNERS", func() {
+		Expect(fetcher.AnalyzeCodeowners(entry, nil).Rules[0].UnknownOwners).To(BeNil())
+
+		report := fetcher.AnalyzeCodeowners(&models.RepoEntry{RootDirs: []string{"src"}}, nil)
+		Expect(report.File).To(BeEmpty())
+		Expect(report.OwnedPercent).To(BeZero())
+		Expect(report.Paths).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("ResolveBaseImages", func() {
+	It("slår opp hver unike referanse én gang og lagrer feil per referanse", func() {
+		files := map[string][]models.FileEntry{
+			"dockerfile": {
+				{Path: "Dockerfile", Content: "FROM 127.0.0.1:1/team/app:1.0 AS build\nFROM build"},
+				{Path: "worker/Dockerfile", Content: "FROM 127.0.0.1:1/team/app:1.0@sha256:abc"},
+			},
+		}
+		client := registry.NewClient(nil, []string{"127.0.0.1:1"})
+
+		images := fetcher.ResolveBaseImages(context.Background(), client, files)
+		Expect(images).To(HaveLen(2))
+		Expect(images[0].Reference).To(Equal("127.0.0.1:1/team/app:1.0"))
+		Expect(images[1].Reference).To(Equal("127.0.0.1:1/team/app:1.0@sha256:abc"))
+		Expect(images[1].PinnedDigest).To(Equal("sha256:abc"))
+		for _, img := range images {
+			Expect(img.Error).NotTo(BeEmpty())
+			Expect(img.DigestDiffers).To(BeFalse())
+		}
 	})
 })
 
@@ -338,3 +638,60 @@ var _ = Describe("doRequestWithRateLimit", func() {
 		Expect(err.Error()).To(ContainSubstring("access denied"))
 	})
 })
+
+var _ = Describe("AnalyzeEntry", func() {
+	It("setter SBOM-kilde bare når det finnes en SBOM", func() {
+		entry := &models.RepoEntry{Files: map[string][]models.FileEntry{
+			"dockerfile": {{Path: "Dockerfile", Content: "FROM alpine:3.20"}},
+		}}
+		fetcher.AnalyzeEntry(context.Background(), entry, linter.New(nil, nil, nil), nil, nil)
+		Expect(entry.SBOM).To(BeNil())
+		Expect(entry.SBOMSource).To(BeEmpty())
+
+		entry.Files["go.sum"] = []models.FileEntry{{Path: "go.sum", Content: "github.com/lib/pq v1.10.9 h1:abc="}}
+		fetcher.AnalyzeEntry(context.Background(), entry, linter.New(nil, nil, nil), nil, nil)
+		Expect(entry.SBOM).NotTo(BeNil())
+		Expect(entry.SBOMSource).To(Equal(sbom.SourceLocal))
+	})
+
+	It("bytter ut hemmeligheter før analysene, så de ikke havner i utledede felter", func() {
+		// Satt sammen så testfilen selv ikke ser ut som den lekker tokens
+		token := "ghp_" + strings.Repeat("aB3dE5", 6)
+		entry := &models.RepoEntry{
+			Repo: models.RepoMeta{Name: "app", FullName: "navikt/app"},
+			Files: map[string][]models.FileEntry{
+				"package.json": {{Path: "package.json", Content: `{"dependencies": {"felles": "git+https://` + token + `@github.com/navikt/felles.git"}}`}},
+				"iac": {{Path: "infra/main.tf", Content: `module "dns" {
+  source = "git::https://` + token + `@github.com/navikt/tf-modules.git//dns?ref=v1.2.0"
+}`}},
+				"docker-compose.yml": {{Path: "docker-compose.yml", Content: `services:
+  app:
+    image: ghcr.io/navikt/app:1.0
+    environment:
+      GITHUB_TOKEN: ` + token}},
+				"deploy": {{Path: ".nais/prod.yaml", Content: `apiVersion: nais.io/v1alpha1
+kind: Application
+metadata:
+  name: app
+  namespace: team
+spec:
+  image: ghcr.io/navikt/app:1.0
+  env:
+    - name: GITHUB_TOKEN
+      value: ` + token}},
+			},
+		}
+
+		fetcher.AnalyzeEntry(context.Background(), entry, linter.New(nil, nil, nil), nil, nil)
+
+		Expect(entry.Dependencies).NotTo(BeEmpty())
+		Expect(entry.IaCModules).NotTo(BeEmpty())
+		Expect(entry.ComposeServices).NotTo(BeEmpty())
+		Expect(entry.DeployManifests).NotTo(BeEmpty())
+		Expect(entry.SecretFindings).To(HaveLen(4))
+
+		out, err := json.Marshal(entry)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(out)).NotTo(ContainSubstring(token))
+	})
+})
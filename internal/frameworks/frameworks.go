@@ -0,0 +1,367 @@
+package frameworks
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jonmartinstorm/reposnusern/internal/linguist"
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+	"github.com/jonmartinstorm/reposnusern/internal/sbom"
+	"github.com/jonmartinstorm/reposnusern/internal/workflow"
+)
+
+const (
+	KindFramework = "framework"
+	KindRuntime   = "runtime"
+)
+
+// Vekter for hvert signal. Summen kappes til 1, så flere uavhengige signaler gir høyere sikkerhet.
+const (
+	weightPackage  = 0.6
+	weightLanguage = 0.2
+	weightImage    = 0.3
+	weightWorkflow = 0.2
+	weightNetHTTP  = 0.4
+
+	// Språk med mindre andel enn dette regnes ikke som signal
+	minLanguageShare = 0.05
+)
+
+// rule beskriver hvordan ett rammeverk eller én runtime kjennes igjen.
+// Pakker skrives som purl-type/navn, der * på slutten matcher alt med samme prefiks.
+// Versjonen hentes fra første pakke i listen som har en.
+type rule struct {
+	name      string
+	kind      string
+	packages  []string
+	languages []string
+	images    []string // prefiks på siste del av image-navnet, f.eks. "temurin"
+	actions   []string
+}
+
+var rules = []rule{
+	{
+		name: "Spring Boot",
+		kind: KindFramework,
+		packages: []string{
+			"maven/org.springframework.boot/spring-boot-starter-parent",
+			"maven/org.springframework.boot/org.springframework.boot.gradle.plugin",
+			"maven/org.springframework.boot/spring-boot",
+			"maven/org.springframework.boot/*",
+		},
+		languages: []string{"Java", "Kotlin"},
+	},
+	{
+		name:      "Ktor",
+		kind:      KindFramework,
+		packages:  []string{"maven/io.ktor/ktor-server-core*", "maven/io.ktor/ktor-server*"},
+		languages: []string{"Kotlin"},
+	},
+	{
+		name:      "Quarkus",
+		kind:      KindFramework,
+		packages:  []string{"maven/io.quarkus/quarkus-core", "maven/io.quarkus/*"},
+		languages: []string{"Java", "Kotlin"},
+	},
+	{
+		name:      "Next.js",
+		kind:      KindFramework,
+		packages:  []string{"npm/next"},
+		languages: []string{"TypeScript", "JavaScript"},
+	},
+	{
+		name:      "React",
+		kind:      KindFramework,
+		packages:  []string{"npm/react"},
+		languages: []string{"TypeScript", "JavaScript"},
+	},
+	{
+		name:      "Express",
+		kind:      KindFramework,
+		packages:  []string{"npm/express"},
+		languages: []string{"TypeScript", "JavaScript"},
+	},
+	{
+		name:      "FastAPI",
+		kind:      KindFramework,
+		packages:  []string{"pypi/fastapi"},
+		languages: []string{"Python"},
+	},
+	{
+		name:      "Django",
+		kind:      KindFramework,
+		packages:  []string{"pypi/django"},
+		languages: []string{"Python"},
+	},
+	{
+		name:      "Gin",
+		kind:      KindFramework,
+		packages:  []string{"golang/github.com/gin-gonic/gin"},
+		languages: []string{"Go"},
+	},
+	{
+		name:      "Echo",
+		kind:      KindFramework,
+		packages:  []string{"golang/github.com/labstack/echo*"},
+		languages: []string{"Go"},
+	},
+	{
+		name:      "JVM",
+		kind:      KindRuntime,
+		packages:  []string{"maven/*"},
+		languages: []string{"Java", "Kotlin", "Scala", "Groovy"},
+		images:    []string{"temurin", "eclipse-temurin", "openjdk", "amazoncorretto", "zulu", "java", "jre", "jdk"},
+		actions:   []string{"actions/setup-java"},
+	},
+	{
+		name:      "Node.js",
+		kind:      KindRuntime,
+		packages:  []string{"npm/*"},
+		languages: []string{"TypeScript", "JavaScript"},
+		images:    []string{"node"},
+		actions:   []string{"actions/setup-node"},
+	},
+	{
+		name:      "Python",
+		kind:      KindRuntime,
+		packages:  []string{"pypi/*"},
+		languages: []string{"Python"},
+		images:    []string{"python"},
+		actions:   []string{"actions/setup-python"},
+	},
+	{
+		name:      "Go",
+		kind:      KindRuntime,
+		packages:  []string{"golang/*"},
+		languages: []string{"Go"},
+		images:    []string{"golang"},
+		actions:   []string{"actions/setup-go"},
+	},
+}
+
+// Go-rammeverk som gjør at vi ikke gjetter på ren net/http
+var goWebFrameworks = []string{"Gin", "Echo"}
+
+type dependency struct {
+	key     string // purl-type/navn
+	version string
+	source  string // "SBOM" eller manifestet avhengigheten står i
+}
+
+// signals er alt Detect ser på, samlet fra RepoEntry.
+type signals struct {
+	dependencies []dependency
+	shares       map[string]float64
+	images       []string
+	actions      []string
+	exposes      bool
+}
+
+// Detect finner rammeverk og runtimes ut fra pakkene i SBOM-en og manifestene, språkfordelingen,
+// base images i Dockerfiles og setup-actions i workflows.
+// Rammeverk krever treff på en pakke; språk og images alene gir bare runtimes.
+func Detect(entry *models.RepoEntry) []models.FrameworkDetection {
+	s := collect(entry)
+
+	var result []models.FrameworkDetection
+	found := map[string]bool{}
+	for _, r := range rules {
+		d, ok := r.evaluate(s)
+		if !ok {
+			continue
+		}
+		found[d.Name] = true
+		result = append(result, d)
+	}
+
+	if d, ok := detectNetHTTP(s, found); ok {
+		result = append(result, d)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Kind != result[j].Kind {
+			return result[i].Kind == KindFramework
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (r rule) evaluate(s signals) (models.FrameworkDetection, bool) {
+	d := models.FrameworkDetection{Name: r.name, Kind: r.kind}
+	var confidence float64
+
+	if dep, ok := s.match(r.packages); ok {
+		confidence += weightPackage
+		d.Version = dep.version
+		if r.kind == KindRuntime {
+			// Runtimes får ikke versjon fra en tilfeldig pakke
+			d.Version = ""
+			d.Evidence = append(d.Evidence, fmt.Sprintf("%s-pakker i %s", strings.SplitN(dep.key, "/", 2)[0], dep.source))
+		} else {
+			d.Evidence = append(d.Evidence, fmt.Sprintf("pakke %s i %s", dep.key, dep.source))
+		}
+	} else if r.kind == KindFramework {
+		return d, false
+	}
+
+	for _, lang := range r.languages {
+		if share := s.shares[lang]; share >= minLanguageShare {
+			confidence += weightLanguage
+			d.Evidence = append(d.Evidence, fmt.Sprintf("%.0f%% %s", share*100, lang))
+			break
+		}
+	}
+	if image, ok := matchPrefix(s.images, r.images); ok {
+		confidence += weightImage
+		d.Evidence = append(d.Evidence, "base image "+image)
+	}
+	if action, ok := matchExact(s.actions, r.actions); ok {
+		confidence += weightWorkflow
+		d.Evidence = append(d.Evidence, "workflow bruker "+action)
+	}
+
+	if confidence == 0 {
+		return d, false
+	}
+	d.Confidence = capConfidence(confidence)
+	return d, true
+}
+
+// detectNetHTTP gjetter på net/http for Go-tjenester som eksponerer en port
+// uten noen av de andre Go-rammeverkene. Det er en svak antakelse og får lav sikkerhet.
+func detectNetHTTP(s signals, found map[string]bool) (models.FrameworkDetection, bool) {
+	share := s.shares["Go"]
+	if share < minLanguageShare || !s.exposes {
+		return models.FrameworkDetection{}, false
+	}
+	for _, name := range goWebFrameworks {
+		if found[name] {
+			return models.FrameworkDetection{}, false
+		}
+	}
+	return models.FrameworkDetection{
+		Name:       "Go net/http",
+		Kind:       KindFramework,
+		Confidence: weightNetHTTP,
+		Evidence:   []string{fmt.Sprintf("%.0f%% Go", share*100), "EXPOSE i Dockerfile uten Gin eller Echo"},
+	}, true
+}
+
+func collect(entry *models.RepoEntry) signals {
+	s := signals{shares: linguist.Shares(entry.Languages)}
+
+	for _, p := range sbom.Packages(entry.SBOM) {
+		purlType, name, version, ok := sbom.ParsePURL(p.PURL)
+		if !ok {
+			continue
+		}
+		s.dependencies = append(s.dependencies, dependency{key: purlType + "/" + name, version: version, source: "SBOM"})
+	}
+	// Direkte avhengigheter kommer etter SBOM-en, så løste versjoner foretrekkes framfor versjonskrav.
+	// Maven-parent og Gradle-plugins finnes bare her.
+	for _, d := range entry.Dependencies {
+		name := d.Name
+		if d.Ecosystem == "maven" {
+			name = strings.Replace(name, ":", "/", 1)
+		}
+		s.dependencies = append(s.dependencies, dependency{key: d.Ecosystem + "/" + name, version: d.Constraint, source: d.Source})
+	}
+
+	for key, list := range entry.Files {
+		if !strings.HasPrefix(key, "dockerfile") {
+			continue
+		}
+		for _, f := range list {
+			features, stages := parser.ParseDockerfile(f.Content)
+			s.exposes = s.exposes || features.HasExpose
+			for _, stage := range stages {
+				if stage.BaseStage != "" || stage.BaseImage == parser.UnresolvedImage {
+					continue
+				}
+				s.images = append(s.images, stage.BaseImage)
+			}
+		}
+	}
+	sort.Strings(s.images)
+
+	for _, f := range entry.CIConfig {
+		wf, err := workflow.Parse(f.Content)
+		if err != nil {
+			continue
+		}
+		for _, u := range wf.Usages() {
+			if u.Kind == workflow.KindAction {
+				s.actions = append(s.actions, u.Action())
+			}
+		}
+	}
+	return s
+}
+
+// match finner første avhengighet som passer, i mønstrenes rekkefølge.
+// Et treff med versjon foretrekkes framfor et uten.
+func (s signals) match(patterns []string) (dependency, bool) {
+	var fallback *dependency
+	for _, pattern := range patterns {
+		for i, dep := range s.dependencies {
+			if !matchPattern(pattern, dep.key) {
+				continue
+			}
+			if dep.version != "" {
+				return dep, true
+			}
+			if fallback == nil {
+				fallback = &s.dependencies[i]
+			}
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return dependency{}, false
+}
+
+func matchPattern(pattern, key string) bool {
+	key = strings.ToLower(key)
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(key, prefix)
+	}
+	return key == pattern
+}
+
+// matchPrefix sammenligner siste del av image-navnet, så både "eclipse-temurin"
+// og "gcr.io/distroless/java21-debian12" kjennes igjen.
+func matchPrefix(images, prefixes []string) (string, bool) {
+	for _, image := range images {
+		name := path.Base(image)
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				return image, true
+			}
+		}
+	}
+	return "", false
+}
+
+func matchExact(values, wanted []string) (string, bool) {
+	for _, v := range values {
+		for _, w := range wanted {
+			if strings.EqualFold(v, w) {
+				return w, true
+			}
+		}
+	}
+	return "", false
+}
+
+func capConfidence(c float64) float64 {
+	if c > 1 {
+		return 1
+	}
+	// Unngå 0.30000000000000004 i databasen
+	return float64(int(c*100+0.5)) / 100
+}
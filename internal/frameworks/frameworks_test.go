@@ -0,0 +1,123 @@
+package frameworks_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/frameworks"
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+func TestFrameworks(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Rammeverksdeteksjon")
+}
+
+func sbomWith(purls ...string) map[string]interface{} {
+	var packages []interface{}
+	for _, p := range purls {
+		packages = append(packages, map[string]interface{}{
+			"name": p,
+			"externalRefs": []interface{}{
+				map[string]interface{}{"referenceType": "purl", "referenceLocator": p},
+			},
+		})
+	}
+	return map[string]interface{}{"sbom": map[string]interface{}{"packages": packages}}
+}
+
+func byName(detections []models.FrameworkDetection) map[string]models.FrameworkDetection {
+	result := map[string]models.FrameworkDetection{}
+	for _, d := range detections {
+		result[d.Name] = d
+	}
+	return result
+}
+
+var _ = Describe("Detect", func() {
+	It("kombinerer pakker, språk, base image og workflow for Spring Boot på JVM", func() {
+		entry := &models.RepoEntry{
+			Languages: map[string]int{"Kotlin": 9000, "Shell": 100},
+			SBOM: sbomWith(
+				"pkg:maven/org.springframework.boot/spring-boot-starter-web@3.2.1",
+				"pkg:maven/org.springframework.boot/spring-boot@3.2.1",
+			),
+			Files: map[string][]models.FileEntry{
+				"dockerfile": {{Path: "Dockerfile", Content: "FROM gcr.io/distroless/java21-debian12\nEXPOSE 8080\n"}},
+			},
+			CIConfig: []models.FileEntry{{
+				Path:    ".github/workflows/build.yml",
+				Content: "on: push\njobs:\n  b:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/setup-java@v4\n",
+			}},
+		}
+
+		found := byName(frameworks.Detect(entry))
+		Expect(found).To(HaveLen(2))
+
+		spring := found["Spring Boot"]
+		Expect(spring.Kind).To(Equal(frameworks.KindFramework))
+		Expect(spring.Version).To(Equal("3.2.1"))
+		Expect(spring.Confidence).To(Equal(0.8))
+		Expect(spring.Evidence).To(Equal([]string{"pakke maven/org.springframework.boot/spring-boot i SBOM", "99% Kotlin"}))
+
+		jvm := found["JVM"]
+		Expect(jvm.Kind).To(Equal(frameworks.KindRuntime))
+		Expect(jvm.Version).To(BeEmpty())
+		Expect(jvm.Confidence).To(Equal(1.0))
+		Expect(jvm.Evidence).To(ContainElement("base image gcr.io/distroless/java21-debian12"))
+		Expect(jvm.Evidence).To(ContainElement("workflow bruker actions/setup-java"))
+	})
+
+	It("bruker direkte avhengigheter, f.eks. Spring Boot-versjonen fra Maven-parent", func() {
+		entry := &models.RepoEntry{
+			Dependencies: []models.Dependency{
+				{Ecosystem: "maven", Name: "org.springframework.boot:spring-boot-starter-parent", Constraint: "3.3.0", Kind: "parent", Source: "pom.xml"},
+				{Ecosystem: "maven", Name: "org.springframework.boot:spring-boot-starter-web", Kind: "direct", Source: "pom.xml"},
+			},
+		}
+		found := byName(frameworks.Detect(entry))
+		Expect(found["Spring Boot"].Version).To(Equal("3.3.0"))
+		Expect(found["Spring Boot"].Evidence).To(Equal([]string{"pakke maven/org.springframework.boot/spring-boot-starter-parent i pom.xml"}))
+	})
+
+	It("finner Next.js og React med scoped og escapede purls", func() {
+		entry := &models.RepoEntry{
+			Languages: map[string]int{"TypeScript": 100},
+			SBOM:      sbomWith("pkg:npm/next@14.1.0", "pkg:npm/react@18.2.0", "pkg:npm/%40types/react@18.2.0"),
+		}
+		detections := frameworks.Detect(entry)
+		Expect(detections).To(HaveLen(3))
+		Expect(detections[0].Name).To(Equal("Next.js"))
+		Expect(detections[0].Version).To(Equal("14.1.0"))
+		Expect(detections[1].Name).To(Equal("React"))
+		Expect(detections[2].Name).To(Equal("Node.js"))
+	})
+
+	It("gjetter på net/http bare for Go-tjenester uten Gin eller Echo", func() {
+		entry := &models.RepoEntry{
+			Languages: map[string]int{"Go": 100},
+			Files: map[string][]models.FileEntry{
+				"dockerfile": {{Path: "Dockerfile", Content: "FROM golang:1.22 AS build\nFROM scratch\nEXPOSE 8080\n"}},
+			},
+		}
+		found := byName(frameworks.Detect(entry))
+		Expect(found).To(HaveKey("Go net/http"))
+		Expect(found["Go net/http"].Confidence).To(BeNumerically("<", 0.5))
+		Expect(found["Go"].Evidence).To(ContainElement("base image golang"))
+
+		entry.SBOM = sbomWith("pkg:golang/github.com/labstack/echo/v4@v4.11.4")
+		found = byName(frameworks.Detect(entry))
+		Expect(found).NotTo(HaveKey("Go net/http"))
+		Expect(found["Echo"].Version).To(Equal("v4.11.4"))
+	})
+
+	It("krever pakketreff for rammeverk", func() {
+		entry := &models.RepoEntry{Languages: map[string]int{"Python": 100}}
+		detections := frameworks.Detect(entry)
+		Expect(detections).To(HaveLen(1))
+		Expect(detections[0].Name).To(Equal("Python"))
+		Expect(detections[0].Confidence).To(Equal(0.2))
+	})
+})
@@ -0,0 +1,146 @@
+package iac
+
+import (
+	"regexp"
+	"strings"
+)
+
+// block er én HCL-blokk, f.eks. module "vpc" { ... } eller aws = { ... } inne i required_providers.
+type block struct {
+	Type   string
+	Labels []string
+	// Attrs har enkle verdier med anførselstegn fjernet; lister og uttrykk står som rå tekst
+	Attrs  map[string]string
+	Blocks []*block
+}
+
+func (b *block) children(typ string) []*block {
+	var result []*block
+	for _, c := range b.Blocks {
+		if c.Type == typ {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+var (
+	heredocStart = regexp.MustCompile(`<<-?\s*([A-Za-z_][A-Za-z0-9_]*)\s*$`)
+	quotedLabel  = regexp.MustCompile(`"([^"]*)"`)
+)
+
+// parseHCL leser blokkene og de enkle attributtene i en HCL-fil. Det er ingen full HCL-parser,
+// men nok for terraform-, module- og provider-blokker og .terraform.lock.hcl.
+// Klammer i strenger, kommentarer og heredocs hoppes over.
+func parseHCL(content string) *block {
+	root := &block{Attrs: map[string]string{}}
+	stack := []*block{root}
+	var stmt strings.Builder
+	heredoc := ""
+	inComment := false
+
+	flush := func() {
+		text := strings.TrimSpace(stmt.String())
+		stmt.Reset()
+		if text == "" {
+			return
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		stack[len(stack)-1].Attrs[key] = unquote(strings.TrimSpace(value))
+	}
+
+	lines := strings.Split(content, "\n")
+	for _, line := range lines {
+		if heredoc != "" {
+			if strings.TrimSpace(line) == heredoc {
+				heredoc = ""
+			}
+			continue
+		}
+		inString := false
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			if inComment {
+				if c == '*' && i+1 < len(line) && line[i+1] == '/' {
+					inComment = false
+					i++
+				}
+				continue
+			}
+			if inString {
+				stmt.WriteByte(c)
+				switch c {
+				case '\\':
+					if i+1 < len(line) {
+						i++
+						stmt.WriteByte(line[i])
+					}
+				case '"':
+					inString = false
+				}
+				continue
+			}
+			switch {
+			case c == '"':
+				inString = true
+				stmt.WriteByte(c)
+			case c == '#' || (c == '/' && i+1 < len(line) && line[i+1] == '/'):
+				i = len(line)
+			case c == '/' && i+1 < len(line) && line[i+1] == '*':
+				inComment = true
+				i++
+			case c == '{':
+				b := newBlock(stmt.String())
+				stmt.Reset()
+				parent := stack[len(stack)-1]
+				parent.Blocks = append(parent.Blocks, b)
+				stack = append(stack, b)
+			case c == '}':
+				flush()
+				if len(stack) > 1 {
+					stack = stack[:len(stack)-1]
+				}
+			case c == ',':
+				flush()
+			default:
+				stmt.WriteByte(c)
+			}
+		}
+		if m := heredocStart.FindStringSubmatch(stmt.String()); m != nil {
+			heredoc = m[1]
+			stmt.Reset()
+			continue
+		}
+		flush()
+	}
+	return root
+}
+
+// newBlock lager en blokk fra teksten foran {, f.eks. `module "vpc"` eller `aws =`.
+func newBlock(header string) *block {
+	header = strings.TrimSpace(header)
+	b := &block{Attrs: map[string]string{}}
+	if strings.HasSuffix(header, "=") {
+		b.Type = strings.Trim(strings.TrimSpace(strings.TrimSuffix(header, "=")), `"`)
+		return b
+	}
+	fields := strings.Fields(header)
+	if len(fields) > 0 {
+		b.Type = fields[0]
+	}
+	for _, m := range quotedLabel.FindAllStringSubmatch(header, -1) {
+		b.Labels = append(b.Labels, m[1])
+	}
+	return b
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
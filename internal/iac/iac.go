@@ -0,0 +1,311 @@
+package iac
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/workflow"
+)
+
+// FilesKey er nøkkelen IaC-filene lagres under i RepoEntry.Files
+const FilesKey = "iac"
+
+// MaxFiles begrenser hvor mange IaC-filer vi henter per repo, siden hver fil er et eget REST-kall
+const MaxFiles = 100
+
+const (
+	ToolTerraform = "terraform"
+	ToolPulumi    = "pulumi"
+	ToolBicep     = "bicep"
+)
+
+const (
+	KindRequiredVersion = "required_version"
+	KindProvider        = "provider"
+	KindLockedProvider  = "locked_provider"
+	KindModule          = "module"
+	KindBackend         = "backend"
+	KindProject         = "project"
+)
+
+const (
+	SourceLocal        = "local"
+	SourceRegistry     = "registry"
+	SourceGit          = "git"
+	SourceMercurial    = "hg"
+	SourceHTTP         = "http"
+	SourceS3           = "s3"
+	SourceGCS          = "gcs"
+	SourceTemplateSpec = "template-spec"
+)
+
+const terraformLockFile = ".terraform.lock.hcl"
+
+// Mapper på toppnivå som typisk inneholder infrastrukturkode, i små bokstaver
+var iacDirs = map[string]bool{
+	"terraform":      true,
+	"infra":          true,
+	"infrastructure": true,
+	"iac":            true,
+	"tf":             true,
+	"pulumi":         true,
+	"bicep":          true,
+}
+
+var (
+	// Terraform Registry: [vert/]namespace/navn/provider[//undermappe]
+	registrySource = regexp.MustCompile(`^([a-z0-9.-]+\.[a-z]+/)?[A-Za-z0-9_-]+/[A-Za-z0-9_-]+/[A-Za-z0-9_-]+(//.*)?$`)
+	exactVersion   = regexp.MustCompile(`^=?\s*v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+	bicepModule    = regexp.MustCompile(`(?m)^\s*module\s+(\w+)\s+'([^']+)'`)
+)
+
+// HasCandidates sier om toppnivået i repoet tyder på infrastrukturkode.
+func HasCandidates(rootFiles, rootDirs []string) bool {
+	for _, f := range rootFiles {
+		if isIaCFile(strings.ToLower(f)) {
+			return true
+		}
+	}
+	for _, d := range rootDirs {
+		if iacDirs[strings.ToLower(d)] {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectPaths velger IaC-filene blant alle filene i repo-treet. Nedlastede moduler i .terraform/ hoppes over.
+func SelectPaths(paths []string) []string {
+	var result []string
+	for _, p := range paths {
+		lower := strings.ToLower(p)
+		if strings.HasPrefix(lower, ".terraform/") || strings.Contains(lower, "/.terraform/") ||
+			strings.Contains(lower, "node_modules/") {
+			continue
+		}
+		if isIaCFile(path.Base(lower)) {
+			result = append(result, p)
+		}
+	}
+	sort.Strings(result)
+	if len(result) > MaxFiles {
+		result = result[:MaxFiles]
+	}
+	return result
+}
+
+func isIaCFile(lowerName string) bool {
+	return strings.HasSuffix(lowerName, ".tf") || strings.HasSuffix(lowerName, ".bicep") ||
+		lowerName == terraformLockFile || lowerName == "pulumi.yaml" || lowerName == "pulumi.yml"
+}
+
+// Parse leser versjonskrav, moduler og backends fra IaC-filene.
+func Parse(files []models.FileEntry) []models.IaCModule {
+	var result []models.IaCModule
+	for _, f := range files {
+		lower := strings.ToLower(path.Base(f.Path))
+		switch {
+		case lower == terraformLockFile:
+			result = append(result, ParseTerraformLock(f.Path, f.Content)...)
+		case strings.HasSuffix(lower, ".tf"):
+			result = append(result, ParseTerraform(f.Path, f.Content)...)
+		case strings.HasSuffix(lower, ".bicep"):
+			result = append(result, ParseBicep(f.Path, f.Content)...)
+		default:
+			result = append(result, ParsePulumi(f.Path, f.Content)...)
+		}
+	}
+	return result
+}
+
+// ParseTerraform leser required_version, required_providers og backend fra terraform-blokken,
+// og kilde og versjon for alle module-blokker.
+func ParseTerraform(filePath, content string) []models.IaCModule {
+	root := parseHCL(content)
+	var result []models.IaCModule
+	add := func(m models.IaCModule) {
+		m.Path = filePath
+		m.Tool = ToolTerraform
+		result = append(result, m)
+	}
+
+	for _, tf := range root.children("terraform") {
+		if v := tf.Attrs["required_version"]; v != "" {
+			add(models.IaCModule{Kind: KindRequiredVersion, Name: ToolTerraform, Version: v})
+		}
+		for _, rp := range tf.children("required_providers") {
+			// Gammel syntaks: aws = "~> 3.0"
+			names := make([]string, 0, len(rp.Attrs))
+			for name := range rp.Attrs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				add(models.IaCModule{Kind: KindProvider, Name: name, Source: providerSource(name, ""), Version: rp.Attrs[name]})
+			}
+			for _, p := range rp.Blocks {
+				add(models.IaCModule{Kind: KindProvider, Name: p.Type, Source: providerSource(p.Type, p.Attrs["source"]), Version: p.Attrs["version"]})
+			}
+		}
+		for _, b := range tf.children("backend") {
+			if len(b.Labels) > 0 {
+				add(models.IaCModule{Kind: KindBackend, Name: b.Labels[0]})
+			}
+		}
+		if len(tf.children("cloud")) > 0 {
+			add(models.IaCModule{Kind: KindBackend, Name: "cloud"})
+		}
+	}
+
+	for _, mod := range root.children("module") {
+		if len(mod.Labels) == 0 {
+			continue
+		}
+		source := mod.Attrs["source"]
+		m := models.IaCModule{Kind: KindModule, Name: mod.Labels[0], Source: source, SourceType: terraformSourceType(source)}
+		switch m.SourceType {
+		case SourceRegistry:
+			m.Version = mod.Attrs["version"]
+			m.Pinned = boolPtr(exactVersion.MatchString(m.Version))
+		case SourceGit, SourceMercurial:
+			m.Version = gitRef(source)
+			refType := workflow.RefType(m.Version)
+			m.Pinned = boolPtr(refType == workflow.RefSHA || refType == workflow.RefTag)
+		}
+		add(m)
+	}
+	return result
+}
+
+// ParseTerraformLock leser de låste provider-versjonene i .terraform.lock.hcl.
+func ParseTerraformLock(filePath, content string) []models.IaCModule {
+	var result []models.IaCModule
+	for _, p := range parseHCL(content).children("provider") {
+		if len(p.Labels) == 0 {
+			continue
+		}
+		address := p.Labels[0]
+		result = append(result, models.IaCModule{
+			Path:    filePath,
+			Tool:    ToolTerraform,
+			Kind:    KindLockedProvider,
+			Name:    path.Base(address),
+			Source:  address,
+			Version: p.Attrs["version"],
+		})
+	}
+	return result
+}
+
+// ParseBicep leser module-deklarasjoner. Moduler fra registry (br:) og template specs (ts:)
+// har versjonen etter siste kolon.
+func ParseBicep(filePath, content string) []models.IaCModule {
+	var result []models.IaCModule
+	for _, m := range bicepModule.FindAllStringSubmatch(content, -1) {
+		mod := models.IaCModule{Path: filePath, Tool: ToolBicep, Kind: KindModule, Name: m[1], Source: m[2], SourceType: SourceLocal}
+		switch {
+		case strings.HasPrefix(m[2], "br:") || strings.HasPrefix(m[2], "br/"):
+			mod.SourceType = SourceRegistry
+		case strings.HasPrefix(m[2], "ts:") || strings.HasPrefix(m[2], "ts/"):
+			mod.SourceType = SourceTemplateSpec
+		}
+		if mod.SourceType != SourceLocal {
+			last := m[2][strings.LastIndex(m[2], "/")+1:]
+			if i := strings.LastIndex(last, ":"); i >= 0 {
+				mod.Version = last[i+1:]
+			}
+			mod.Pinned = boolPtr(mod.Version != "" && mod.Version != "latest")
+		}
+		result = append(result, mod)
+	}
+	return result
+}
+
+// ParsePulumi leser prosjektnavn, runtime og backend fra Pulumi.yaml.
+func ParsePulumi(filePath, content string) []models.IaCModule {
+	var project struct {
+		Name    string    `yaml:"name"`
+		Runtime yaml.Node `yaml:"runtime"`
+		Backend struct {
+			URL string `yaml:"url"`
+		} `yaml:"backend"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &project); err != nil || project.Name == "" {
+		return nil
+	}
+
+	// runtime er enten en streng eller { name: ..., options: ... }
+	runtime := project.Runtime.Value
+	if project.Runtime.Kind == yaml.MappingNode {
+		var r struct {
+			Name string `yaml:"name"`
+		}
+		if err := project.Runtime.Decode(&r); err == nil {
+			runtime = r.Name
+		}
+	}
+
+	result := []models.IaCModule{{Path: filePath, Tool: ToolPulumi, Kind: KindProject, Name: project.Name, Source: runtime}}
+	if project.Backend.URL != "" {
+		backend := project.Backend.URL
+		if u, err := url.Parse(backend); err == nil && u.Scheme != "" {
+			backend = u.Scheme
+		}
+		result = append(result, models.IaCModule{Path: filePath, Tool: ToolPulumi, Kind: KindBackend, Name: backend, Source: project.Backend.URL})
+	}
+	return result
+}
+
+// terraformSourceType klassifiserer en modulkilde slik Terraform tolker den.
+func terraformSourceType(source string) string {
+	lower := strings.ToLower(source)
+	switch {
+	case strings.HasPrefix(lower, "./") || strings.HasPrefix(lower, "../"):
+		return SourceLocal
+	case strings.HasPrefix(lower, "git::") || strings.HasPrefix(lower, "git@") ||
+		strings.HasPrefix(lower, "github.com/") || strings.HasPrefix(lower, "bitbucket.org/"):
+		return SourceGit
+	case strings.HasPrefix(lower, "hg::"):
+		return SourceMercurial
+	case strings.HasPrefix(lower, "s3::") || strings.Contains(lower, ".amazonaws.com/"):
+		return SourceS3
+	case strings.HasPrefix(lower, "gcs::") || strings.HasPrefix(lower, "https://www.googleapis.com/storage/"):
+		return SourceGCS
+	case strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://"):
+		return SourceHTTP
+	case registrySource.MatchString(source):
+		return SourceRegistry
+	}
+	return ""
+}
+
+// gitRef henter ?ref= fra en git-kilde
+func gitRef(source string) string {
+	_, query, ok := strings.Cut(source, "?")
+	if !ok {
+		return ""
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return ""
+	}
+	return values.Get("ref")
+}
+
+// providerSource gir full kilde for en provider; uten source bruker Terraform hashicorp/<navn>
+func providerSource(name, source string) string {
+	if source != "" {
+		return source
+	}
+	return "hashicorp/" + name
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
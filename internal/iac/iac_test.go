@@ -0,0 +1,163 @@
+package iac_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/iac"
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+func TestIaC(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Infrastruktur som kode")
+}
+
+// summary gir "kind navn kilde versjon pinned" per rad, så testene blir lette å lese
+func summary(modules []models.IaCModule) []string {
+	var result []string
+	for _, m := range modules {
+		pinned := "-"
+		if m.Pinned != nil && *m.Pinned {
+			pinned = "pinned"
+		} else if m.Pinned != nil {
+			pinned = "unpinned"
+		}
+		result = append(result, m.Kind+" "+m.Name+" "+m.Source+" "+m.Version+" "+pinned)
+	}
+	return result
+}
+
+var _ = Describe("ParseTerraform", func() {
+	It("leser terraform-blokken og moduler med kilde og ref", func() {
+		modules := iac.ParseTerraform("infra/main.tf", `# Oppsett for prod
+terraform {
+  required_version = ">= 1.5.0"
+
+  required_providers {
+    google = {
+      source  = "hashicorp/google"
+      version = "~> 5.0" // kommentar med { klamme
+    }
+    random = "3.6.0"
+  }
+
+  backend "gcs" {
+    bucket = "tf-state"
+  }
+}
+
+/* module "kommentert" {
+  source = "./gammel"
+} */
+
+module "network" {
+  source  = "terraform-google-modules/network/google"
+  version = "9.1.0"
+}
+
+module "gke" {
+  source  = "terraform-google-modules/kubernetes-engine/google"
+  version = "~> 30.0"
+}
+
+module "dns" {
+  source = "git::https://github.com/navikt/tf-modules.git//dns?ref=v1.2.0"
+}
+
+module "iam" {
+  source = "github.com/navikt/tf-iam?ref=main"
+}
+
+module "local" {
+  source = "./modules/local"
+  labels = { team = "${var.team}" }
+}
+
+resource "google_storage_bucket" "b" {
+  name = "b"
+  lifecycle_rule {
+    condition { age = 30 }
+  }
+  metadata = <<EOT
+{ "ikke": "hcl" }
+EOT
+}
+`)
+		Expect(summary(modules)).To(Equal([]string{
+			"required_version terraform  >= 1.5.0 -",
+			"provider random hashicorp/random 3.6.0 -",
+			"provider google hashicorp/google ~> 5.0 -",
+			"backend gcs   -",
+			"module network terraform-google-modules/network/google 9.1.0 pinned",
+			"module gke terraform-google-modules/kubernetes-engine/google ~> 30.0 unpinned",
+			"module dns git::https://github.com/navikt/tf-modules.git//dns?ref=v1.2.0 v1.2.0 pinned",
+			"module iam github.com/navikt/tf-iam?ref=main main unpinned",
+			"module local ./modules/local  -",
+		}))
+		Expect(modules[4].SourceType).To(Equal(iac.SourceRegistry))
+		Expect(modules[6].SourceType).To(Equal(iac.SourceGit))
+		Expect(modules[8].SourceType).To(Equal(iac.SourceLocal))
+		Expect(modules[0].Tool).To(Equal(iac.ToolTerraform))
+	})
+
+	It("leser låste provider-versjoner", func() {
+		modules := iac.ParseTerraformLock(".terraform.lock.hcl", `provider "registry.terraform.io/hashicorp/google" {
+  version     = "5.31.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:abc=",
+  ]
+}
+`)
+		Expect(summary(modules)).To(Equal([]string{"locked_provider google registry.terraform.io/hashicorp/google 5.31.0 -"}))
+	})
+})
+
+var _ = Describe("ParseBicep og ParsePulumi", func() {
+	It("leser Bicep-moduler fra registry og lokale filer", func() {
+		modules := iac.ParseBicep("main.bicep", `module storage 'br/public:avm/res/storage/storage-account:0.9.0' = {
+  name: 'storage'
+}
+module app './app.bicep' = {
+  name: 'app'
+}
+`)
+		Expect(summary(modules)).To(Equal([]string{
+			"module storage br/public:avm/res/storage/storage-account:0.9.0 0.9.0 pinned",
+			"module app ./app.bicep  -",
+		}))
+	})
+
+	It("leser prosjekt, runtime og backend fra Pulumi.yaml", func() {
+		modules := iac.ParsePulumi("Pulumi.yaml", `name: plattform
+runtime:
+  name: nodejs
+  options:
+    typescript: true
+backend:
+  url: gs://pulumi-state
+`)
+		Expect(summary(modules)).To(Equal([]string{
+			"project plattform nodejs  -",
+			"backend gs gs://pulumi-state  -",
+		}))
+	})
+})
+
+var _ = Describe("SelectPaths", func() {
+	It("velger IaC-filer og hopper over nedlastede moduler", func() {
+		Expect(iac.SelectPaths([]string{
+			"infra/main.tf",
+			"infra/.terraform/modules/x/main.tf",
+			"infra/.terraform.lock.hcl",
+			"Pulumi.yaml",
+			"bicep/main.bicep",
+			"README.md",
+		})).To(Equal([]string{"Pulumi.yaml", "bicep/main.bicep", "infra/.terraform.lock.hcl", "infra/main.tf"}))
+		Expect(iac.HasCandidates(nil, []string{"terraform"})).To(BeTrue())
+		Expect(iac.HasCandidates([]string{"main.go"}, []string{"cmd"})).To(BeFalse())
+	})
+})
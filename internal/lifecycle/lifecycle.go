@@ -0,0 +1,113 @@
+package lifecycle
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// Hendelsestyper for et repos livsløp, utledet ved å sammenligne to snapshots på stabil repo-ID.
+const (
+	EventCreated     = "created"
+	EventRenamed     = "renamed"
+	EventTransferred = "transferred"
+	EventArchived    = "archived"
+	EventUnarchived  = "unarchived"
+	// GitHub svarer 404 både for slettede repos og repos vi ikke har tilgang til
+	EventDeletedOrInaccessible = "deleted_or_inaccessible"
+)
+
+// RepoState er det vi trenger å vite om et repo i ett snapshot.
+type RepoState struct {
+	ID       int64
+	FullName string
+	Archived bool
+}
+
+type Event struct {
+	RepoID      int64
+	Type        string
+	OldFullName string
+	NewFullName string
+}
+
+// LookupFunc slår opp et repo på ID utenfor org-listingen. Returnerer nil uten feil
+// når repoet er slettet eller utilgjengelig.
+type LookupFunc func(ctx context.Context, id int64) (*RepoState, error)
+
+// Detect sammenligner forrige snapshot med repoene vi fant i denne kjøringen.
+// Repos som har forsvunnet fra org-listingen slås opp med lookup for å skille
+// overføring til en annen eier fra sletting. lookup kan være nil.
+func Detect(ctx context.Context, org string, previous, current []RepoState, lookup LookupFunc) []Event {
+	prevByID := make(map[int64]RepoState, len(previous))
+	for _, p := range previous {
+		prevByID[p.ID] = p
+	}
+
+	var events []Event
+	seen := make(map[int64]bool, len(current))
+
+	for _, curr := range current {
+		seen[curr.ID] = true
+		prev, existed := prevByID[curr.ID]
+		if !existed {
+			events = append(events, Event{RepoID: curr.ID, Type: EventCreated, NewFullName: curr.FullName})
+			continue
+		}
+		events = append(events, compare(prev, curr)...)
+	}
+
+	for _, prev := range previous {
+		if seen[prev.ID] {
+			continue
+		}
+
+		var found *RepoState
+		if lookup != nil {
+			var err error
+			found, err = lookup(ctx, prev.ID)
+			if err != nil {
+				// Ukjent status – bedre å ikke melde noe enn å melde feil hendelse
+				slog.Warn("Klarte ikke slå opp forsvunnet repo", "repo", prev.FullName, "id", prev.ID, "error", err)
+				continue
+			}
+		}
+
+		if found == nil {
+			events = append(events, Event{RepoID: prev.ID, Type: EventDeletedOrInaccessible, OldFullName: prev.FullName})
+			continue
+		}
+		if !strings.EqualFold(ownerOf(found.FullName), org) {
+			events = append(events, Event{RepoID: prev.ID, Type: EventTransferred, OldFullName: prev.FullName, NewFullName: found.FullName})
+			continue
+		}
+		events = append(events, compare(prev, *found)...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].RepoID < events[j].RepoID })
+	return events
+}
+
+func compare(prev, curr RepoState) []Event {
+	var events []Event
+	if !strings.EqualFold(prev.FullName, curr.FullName) {
+		typ := EventRenamed
+		if !strings.EqualFold(ownerOf(prev.FullName), ownerOf(curr.FullName)) {
+			typ = EventTransferred
+		}
+		events = append(events, Event{RepoID: curr.ID, Type: typ, OldFullName: prev.FullName, NewFullName: curr.FullName})
+	}
+	if !prev.Archived && curr.Archived {
+		events = append(events, Event{RepoID: curr.ID, Type: EventArchived, OldFullName: prev.FullName, NewFullName: curr.FullName})
+	}
+	if prev.Archived && !curr.Archived {
+		events = append(events, Event{RepoID: curr.ID, Type: EventUnarchived, OldFullName: prev.FullName, NewFullName: curr.FullName})
+	}
+	return events
+}
+
+func ownerOf(fullName string) string {
+	owner, _, _ := strings.Cut(fullName, "/")
+	return owner
+}
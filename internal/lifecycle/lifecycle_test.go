@@ -0,0 +1,71 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/lifecycle"
+)
+
+func TestLifecycle(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Lifecycle – hendelser mellom snapshots")
+}
+
+var _ = Describe("Detect", func() {
+	ctx := context.Background()
+
+	previous := []lifecycle.RepoState{
+		{ID: 1, FullName: "navikt/gammelt-navn"},
+		{ID: 2, FullName: "navikt/skal-arkiveres"},
+		{ID: 3, FullName: "navikt/var-arkivert", Archived: true},
+		{ID: 4, FullName: "navikt/flyttet"},
+		{ID: 5, FullName: "navikt/slettet"},
+		{ID: 6, FullName: "navikt/uendret"},
+	}
+
+	current := []lifecycle.RepoState{
+		{ID: 1, FullName: "navikt/nytt-navn"},
+		{ID: 2, FullName: "navikt/skal-arkiveres", Archived: true},
+		{ID: 3, FullName: "navikt/var-arkivert"},
+		{ID: 6, FullName: "navikt/uendret"},
+		{ID: 7, FullName: "navikt/helt-nytt"},
+	}
+
+	lookup := func(_ context.Context, id int64) (*lifecycle.RepoState, error) {
+		if id == 4 {
+			return &lifecycle.RepoState{ID: 4, FullName: "annen-org/flyttet"}, nil
+		}
+		return nil, nil
+	}
+
+	It("finner alle typer hendelser", func() {
+		events := lifecycle.Detect(ctx, "navikt", previous, current, lookup)
+		Expect(events).To(Equal([]lifecycle.Event{
+			{RepoID: 1, Type: lifecycle.EventRenamed, OldFullName: "navikt/gammelt-navn", NewFullName: "navikt/nytt-navn"},
+			{RepoID: 2, Type: lifecycle.EventArchived, OldFullName: "navikt/skal-arkiveres", NewFullName: "navikt/skal-arkiveres"},
+			{RepoID: 3, Type: lifecycle.EventUnarchived, OldFullName: "navikt/var-arkivert", NewFullName: "navikt/var-arkivert"},
+			{RepoID: 4, Type: lifecycle.EventTransferred, OldFullName: "navikt/flyttet", NewFullName: "annen-org/flyttet"},
+			{RepoID: 5, Type: lifecycle.EventDeletedOrInaccessible, OldFullName: "navikt/slettet"},
+			{RepoID: 7, Type: lifecycle.EventCreated, NewFullName: "navikt/helt-nytt"},
+		}))
+	})
+
+	It("melder forsvunne repos som slettet når oppslag ikke er tilgjengelig", func() {
+		events := lifecycle.Detect(ctx, "navikt", previous[3:5], nil, nil)
+		Expect(events).To(HaveLen(2))
+		Expect(events[0].Type).To(Equal(lifecycle.EventDeletedOrInaccessible))
+		Expect(events[1].Type).To(Equal(lifecycle.EventDeletedOrInaccessible))
+	})
+
+	It("melder ingenting når oppslaget feiler", func() {
+		failing := func(_ context.Context, _ int64) (*lifecycle.RepoState, error) {
+			return nil, errors.New("nettverksfeil")
+		}
+		Expect(lifecycle.Detect(ctx, "navikt", previous[4:5], nil, failing)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,105 @@
+package linguist
+
+// Språktyper etter samme modell som GitHub linguist, men med config skilt ut fra data.
+const (
+	TypeProgramming = "programming"
+	TypeMarkup      = "markup"
+	TypeData        = "data"
+	TypeConfig      = "config"
+)
+
+// Språk som ikke er programmering. Alt som ikke står her regnes som programming.
+var languageTypes = map[string]string{
+	// Markup og stil
+	"HTML":             TypeMarkup,
+	"CSS":              TypeMarkup,
+	"SCSS":             TypeMarkup,
+	"Sass":             TypeMarkup,
+	"Less":             TypeMarkup,
+	"Stylus":           TypeMarkup,
+	"Markdown":         TypeMarkup,
+	"MDX":              TypeMarkup,
+	"AsciiDoc":         TypeMarkup,
+	"reStructuredText": TypeMarkup,
+	"TeX":              TypeMarkup,
+	"XSLT":             TypeMarkup,
+	"Vue":              TypeMarkup,
+	"Svelte":           TypeMarkup,
+	"Handlebars":       TypeMarkup,
+	"Mustache":         TypeMarkup,
+	"FreeMarker":       TypeMarkup,
+	"Jinja":            TypeMarkup,
+	"Pug":              TypeMarkup,
+	"EJS":              TypeMarkup,
+	"Jupyter Notebook": TypeMarkup,
+
+	// Data
+	"JSON":             TypeData,
+	"JSON5":            TypeData,
+	"CSV":              TypeData,
+	"TSV":              TypeData,
+	"XML":              TypeData,
+	"SQL":              TypeData,
+	"GraphQL":          TypeData,
+	"Protocol Buffer":  TypeData,
+	"Avro IDL":         TypeData,
+	"Thrift":           TypeData,
+	"SVG":              TypeData,
+	"Gettext Catalog":  TypeData,
+	"OpenAPI Spec v3":  TypeData,
+	"Roff":             TypeData,
+	"Text":             TypeData,
+	"Public Key":       TypeData,
+	"Ignore List":      TypeData,
+	"Git Attributes":   TypeData,
+	"Git Config":       TypeData,
+	"EditorConfig":     TypeData,
+	"Browserslist":     TypeData,
+	"Checksums":        TypeData,
+	"Jest Snapshot":    TypeData,
+	"Raw token data":   TypeData,
+	"Unity3D Asset":    TypeData,
+	"Wavefront Object": TypeData,
+
+	// Konfigurasjon og bygg
+	"YAML":            TypeConfig,
+	"TOML":            TypeConfig,
+	"INI":             TypeConfig,
+	"Java Properties": TypeConfig,
+	"HCL":             TypeConfig,
+	"Dockerfile":      TypeConfig,
+	"Makefile":        TypeConfig,
+	"CMake":           TypeConfig,
+	"Nix":             TypeConfig,
+	"Jsonnet":         TypeConfig,
+	"Starlark":        TypeConfig,
+	"Nginx":           TypeConfig,
+	"Procfile":        TypeConfig,
+	"Dotenv":          TypeConfig,
+	"Bicep":           TypeConfig,
+	"Smarty":          TypeConfig,
+}
+
+// Classify returnerer språktypen for et GitHub-språknavn.
+func Classify(language string) string {
+	if t, ok := languageTypes[language]; ok {
+		return t
+	}
+	return TypeProgramming
+}
+
+// Shares regner ut hvert språks andel (0–1) av totalt antall bytes.
+func Shares(langs map[string]int) map[string]float64 {
+	total := 0
+	for _, size := range langs {
+		total += size
+	}
+
+	shares := make(map[string]float64, len(langs))
+	for lang, size := range langs {
+		if total > 0 {
+			shares[lang] = float64(size) / float64(total)
+		}
+	}
+	return shares
+}
@@ -0,0 +1,42 @@
+package linguist_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/linguist"
+)
+
+func TestLinguist(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Linguist – språkklassifisering")
+}
+
+var _ = Describe("Classify", func() {
+	DescribeTable("klassifiserer språk",
+		func(language, expected string) {
+			Expect(linguist.Classify(language)).To(Equal(expected))
+		},
+		Entry("Go er programmering", "Go", linguist.TypeProgramming),
+		Entry("ukjente språk regnes som programmering", "Zig", linguist.TypeProgramming),
+		Entry("HTML er markup", "HTML", linguist.TypeMarkup),
+		Entry("CSS er markup", "CSS", linguist.TypeMarkup),
+		Entry("JSON er data", "JSON", linguist.TypeData),
+		Entry("Dockerfile er config", "Dockerfile", linguist.TypeConfig),
+		Entry("HCL er config", "HCL", linguist.TypeConfig),
+	)
+})
+
+var _ = Describe("Shares", func() {
+	It("regner ut andel av totale bytes", func() {
+		shares := linguist.Shares(map[string]int{"Go": 750, "HTML": 250})
+		Expect(shares["Go"]).To(BeNumerically("~", 0.75, 1e-9))
+		Expect(shares["HTML"]).To(BeNumerically("~", 0.25, 1e-9))
+	})
+
+	It("gir null andel når det ikke finnes bytes", func() {
+		Expect(linguist.Shares(map[string]int{"Go": 0})["Go"]).To(BeZero())
+	})
+})
@@ -0,0 +1,144 @@
+package linter
+
+import (
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/jonmartinstorm/reposnusern/internal/catalog"
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+)
+
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Location er linjene et funn gjelder, 1-basert og inklusiv.
+type Location struct {
+	StartLine int
+	EndLine   int
+}
+
+// Document er det en regel får se: AST-en og features/stages utledet fra den.
+type Document struct {
+	AST      *parser.Dockerfile
+	Features parser.DockerfileFeatures
+	Stages   []parser.DockerStageMeta
+}
+
+// Rule er én registrert sjekk. ID-en er stabil og lagres sammen med funnene,
+// så den må aldri gjenbrukes for en annen sjekk.
+type Rule struct {
+	ID                string
+	Severity          Severity
+	Description       string
+	DisabledByDefault bool // støyende regler som må skrus på eksplisitt
+	Check             func(doc *Document) []Location
+}
+
+type Finding struct {
+	RuleID      string
+	Severity    Severity
+	Description string
+	StartLine   int
+	EndLine     int
+}
+
+var registry = map[string]Rule{}
+
+func register(rule Rule) {
+	if _, exists := registry[rule.ID]; exists {
+		panic("linter: regel registrert to ganger: " + rule.ID)
+	}
+	registry[rule.ID] = rule
+}
+
+// Rules returnerer alle registrerte regler sortert på ID.
+func Rules() []Rule {
+	rules := make([]Rule, 0, len(registry))
+	for _, r := range registry {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+type Linter struct {
+	rules   []Rule
+	catalog *catalog.Catalog
+}
+
+// New lager en linter med standardreglene, pluss de i enable og minus de i disable.
+// Ukjente regel-ID-er logges og ignoreres. cat avgjør hvem base image kjører som når
+// Dockerfilen mangler USER; med nil er det ukjent.
+func New(enable, disable []string, cat *catalog.Catalog) *Linter {
+	enabled := map[string]bool{}
+	for _, r := range registry {
+		enabled[r.ID] = !r.DisabledByDefault
+	}
+	for _, id := range enable {
+		setRule(enabled, id, true)
+	}
+	for _, id := range disable {
+		setRule(enabled, id, false)
+	}
+
+	l := &Linter{catalog: cat}
+	for _, r := range Rules() {
+		if enabled[r.ID] {
+			l.rules = append(l.rules, r)
+		}
+	}
+	return l
+}
+
+func setRule(enabled map[string]bool, id string, on bool) {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	if id == "" {
+		return
+	}
+	if _, ok := registry[id]; !ok {
+		slog.Warn("Ukjent Dockerfile-regel i konfigurasjonen", "rule", id)
+		return
+	}
+	enabled[id] = on
+}
+
+// Lint kjører alle aktive regler på en Dockerfile. Funnene er sortert på linje og regel.
+func (l *Linter) Lint(content string) []Finding {
+	ast := parser.ParseDockerfileAST(content)
+	features, stages := parser.AnalyzeDockerfileAST(ast)
+	if l.catalog != nil {
+		l.catalog.ResolveRunsAsRoot(&features, stages)
+	}
+	doc := &Document{
+		AST:      ast,
+		Features: features,
+		Stages:   stages,
+	}
+
+	var findings []Finding
+	for _, rule := range l.rules {
+		for _, loc := range rule.Check(doc) {
+			findings = append(findings, Finding{
+				RuleID:      rule.ID,
+				Severity:    rule.Severity,
+				Description: rule.Description,
+				StartLine:   loc.StartLine,
+				EndLine:     loc.EndLine,
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].StartLine != findings[j].StartLine {
+			return findings[i].StartLine < findings[j].StartLine
+		}
+		return findings[i].RuleID < findings[j].RuleID
+	})
+	return findings
+}
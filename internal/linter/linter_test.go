@@ -0,0 +1,110 @@
+package linter_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/catalog"
+	"github.com/jonmartinstorm/reposnusern/internal/linter"
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+)
+
+func TestLinter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dockerfile-linter")
+}
+
+func ruleIDs(findings []linter.Finding) []string {
+	var ids []string
+	for _, f := range findings {
+		ids = append(ids, f.RuleID)
+	}
+	return ids
+}
+
+var _ = Describe("Linter", func() {
+	It("gir funn med regel-ID, alvorlighet og linjenumre", func() {
+		findings := linter.New(nil, nil, nil).Lint(`FROM debian
+ENV API_TOKEN=abc
+RUN apt-get update && \
+    apt-get install -y gcc && \
+    curl -sL https://example.com/install.sh | bash
+USER app`)
+
+		Expect(findings).To(ContainElement(linter.Finding{
+			RuleID:      "DF001",
+			Severity:    linter.SeverityWarning,
+			Description: "Base image bruker latest eller mangler tag",
+			StartLine:   1,
+			EndLine:     1,
+		}))
+		Expect(findings).To(ContainElement(HaveField("RuleID", "DF004")))
+
+		var run []linter.Finding
+		for _, f := range findings {
+			if f.StartLine == 3 {
+				run = append(run, f)
+			}
+		}
+		Expect(ruleIDs(run)).To(Equal([]string{"DF007", "DF008", "DF009", "DF010"}))
+		Expect(run[0].EndLine).To(Equal(5))
+		Expect(ruleIDs(findings)).NotTo(ContainElement("DF002"))
+	})
+
+	It("ser bare på siste stage for root og byggeverktøy", func() {
+		findings := linter.New(nil, nil, catalog.Default()).Lint(`FROM golang:1.22 AS build
+RUN apt-get install -y --no-install-recommends make && rm -rf /var/lib/apt/lists/*
+
+FROM gcr.io/distroless/static:nonroot
+COPY --from=build /app /app`)
+
+		// distroless :nonroot kjører som nonroot selv uten USER
+		Expect(findings).To(BeEmpty())
+	})
+
+	It("flagger root bare når base image er kjent for å kjøre som root", func() {
+		Expect(linter.New(nil, nil, catalog.Default()).Lint("FROM node:20\nCMD [\"node\", \"app.js\"]")).To(ContainElement(linter.Finding{
+			RuleID:      "DF002",
+			Severity:    linter.SeverityWarning,
+			Description: "Siste stage kjører som root",
+			StartLine:   1,
+			EndLine:     1,
+		}))
+		Expect(ruleIDs(linter.New(nil, nil, catalog.Default()).Lint("FROM node:20\nUSER node"))).NotTo(ContainElement("DF002"))
+		Expect(ruleIDs(linter.New(nil, nil, catalog.Default()).Lint("FROM example.com/ukjent/app:1.0"))).NotTo(ContainElement("DF002"))
+		Expect(ruleIDs(linter.New(nil, nil, nil).Lint("FROM node:20"))).NotTo(ContainElement("DF002"))
+	})
+
+	It("ser bare på variabelnavnet for hemmeligheter i ENV og ARG, som parseren", func() {
+		content := "FROM alpine:3.20\nENV APP_NAME my-secret-service\nARG API_KEY\nUSER app"
+		findings := linter.New(nil, nil, nil).Lint(content)
+		Expect(findings).To(ContainElement(HaveField("StartLine", 3)))
+		Expect(findings).NotTo(ContainElement(HaveField("StartLine", 2)))
+
+		features, _ := parser.ParseDockerfile("FROM alpine:3.20\nENV APP_NAME my-secret-service")
+		Expect(features.HasSecretsInEnvOrArg).To(BeFalse())
+	})
+
+	It("kan skru regler av og på", func() {
+		content := "FROM alpine\nUSER app"
+
+		Expect(ruleIDs(linter.New(nil, nil, nil).Lint(content))).To(Equal([]string{"DF001"}))
+		Expect(linter.New(nil, []string{"df001"}, nil).Lint(content)).To(BeEmpty())
+		Expect(ruleIDs(linter.New([]string{"DF012"}, nil, nil).Lint(content))).To(Equal([]string{"DF001", "DF012"}))
+		Expect(linter.New(nil, []string{"DF999"}, nil).Lint(content)).To(HaveLen(1))
+	})
+
+	It("har unike og sorterte regel-ID-er med beskrivelse", func() {
+		rules := linter.Rules()
+		Expect(rules).NotTo(BeEmpty())
+		for i, r := range rules {
+			Expect(r.Description).NotTo(BeEmpty())
+			Expect(r.Severity).To(BeElementOf(linter.SeverityInfo, linter.SeverityWarning, linter.SeverityError))
+			if i > 0 {
+				Expect(r.ID > rules[i-1].ID).To(BeTrue())
+			}
+		}
+	})
+})
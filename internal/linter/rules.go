@@ -0,0 +1,229 @@
+package linter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+)
+
+var pipeToShellPattern = regexp.MustCompile(`\b(curl|wget)\b[^|;&]*\|\s*(sudo\s+)?(ba|z|da)?sh\b`)
+
+func init() {
+	register(Rule{
+		ID:          "DF001",
+		Severity:    SeverityWarning,
+		Description: "Base image bruker latest eller mangler tag",
+		Check: func(doc *Document) []Location {
+			var locs []Location
+			for _, s := range doc.Stages {
+				if s.BaseStage == "" && s.BaseTag == "latest" && !s.IsDigestPinned {
+					locs = append(locs, Location{StartLine: s.Line, EndLine: s.Line})
+				}
+			}
+			return locs
+		},
+	})
+
+	register(Rule{
+		ID:          "DF002",
+		Severity:    SeverityWarning,
+		Description: "Siste stage kjører som root",
+		Check: func(doc *Document) []Location {
+			final, ok := finalStage(doc)
+			if !ok || final.RunsAsRoot == nil || !*final.RunsAsRoot {
+				return nil
+			}
+			// Pek på USER root hvis den finnes, ellers på FROM
+			users := filter(stageInstructions(doc, final.StageIndex), "user")
+			if len(users) > 0 {
+				return []Location{at(users[len(users)-1])}
+			}
+			return []Location{{StartLine: final.Line, EndLine: final.Line}}
+		},
+	})
+
+	register(Rule{
+		ID:          "DF003",
+		Severity:    SeverityInfo,
+		Description: "ADD brukt i stedet for COPY",
+		Check: func(doc *Document) []Location {
+			return locations(filter(doc.AST.Instructions, "add"))
+		},
+	})
+
+	register(Rule{
+		ID:          "DF004",
+		Severity:    SeverityError,
+		Description: "Hemmelighet satt via ENV eller ARG",
+		Check: func(doc *Document) []Location {
+			return matching(filter(doc.AST.Instructions, "env", "arg"), parser.SetsSecret)
+		},
+	})
+
+	register(Rule{
+		ID:          "DF005",
+		Severity:    SeverityError,
+		Description: "Kopierer sensitive filer (.ssh, id_rsa, secrets) inn i imaget",
+		Check: func(doc *Document) []Location {
+			return matching(filter(doc.AST.Instructions, "copy", "add"), func(inst parser.Instruction) bool {
+				args := strings.ToLower(strings.Join(inst.Args, " "))
+				return strings.Contains(args, ".ssh") || strings.Contains(args, "id_rsa") || strings.Contains(args, "secrets")
+			})
+		},
+	})
+
+	register(Rule{
+		ID:          "DF006",
+		Severity:    SeverityWarning,
+		Description: "chmod 777 gjør filer skrivbare for alle",
+		Check: func(doc *Document) []Location {
+			return matchingScript(doc, parser.WorldWritablePattern.MatchString)
+		},
+	})
+
+	register(Rule{
+		ID:          "DF007",
+		Severity:    SeverityInfo,
+		Description: "apt-get install uten å rydde /var/lib/apt/lists i samme RUN",
+		Check: func(doc *Document) []Location {
+			return matchingScript(doc, func(script string) bool {
+				return strings.Contains(script, "apt-get install") &&
+					!strings.Contains(script, "/var/lib/apt/lists") &&
+					!strings.Contains(script, "apt-get clean")
+			})
+		},
+	})
+
+	register(Rule{
+		ID:          "DF008",
+		Severity:    SeverityInfo,
+		Description: "apt-get install uten --no-install-recommends",
+		Check: func(doc *Document) []Location {
+			return matchingScript(doc, func(script string) bool {
+				return strings.Contains(script, "apt-get install") && !strings.Contains(script, "--no-install-recommends")
+			})
+		},
+	})
+
+	register(Rule{
+		ID:          "DF009",
+		Severity:    SeverityWarning,
+		Description: "Byggeverktøy (gcc, make, build-essential) i runtime-imaget",
+		Check: func(doc *Document) []Location {
+			final, ok := finalStage(doc)
+			if !ok || !final.InstallsBuildTools {
+				return nil
+			}
+			locs := matching(filter(stageInstructions(doc, final.StageIndex), "run"), func(inst parser.Instruction) bool {
+				return parser.BuildToolsPattern.MatchString(strings.ToLower(inst.Text()))
+			})
+			if len(locs) == 0 {
+				// Arvet fra stagen den bygger på
+				locs = []Location{{StartLine: final.Line, EndLine: final.Line}}
+			}
+			return locs
+		},
+	})
+
+	register(Rule{
+		ID:          "DF010",
+		Severity:    SeverityWarning,
+		Description: "Laster ned og kjører script direkte (curl | sh)",
+		Check: func(doc *Document) []Location {
+			return matchingScript(doc, pipeToShellPattern.MatchString)
+		},
+	})
+
+	register(Rule{
+		ID:          "DF011",
+		Severity:    SeverityInfo,
+		Description: "Base image kunne ikke løses opp fra ARG-er",
+		Check: func(doc *Document) []Location {
+			var locs []Location
+			for _, s := range doc.Stages {
+				if s.BaseImage == parser.UnresolvedImage {
+					locs = append(locs, Location{StartLine: s.Line, EndLine: s.Line})
+				}
+			}
+			return locs
+		},
+	})
+
+	register(Rule{
+		ID:                "DF012",
+		Severity:          SeverityInfo,
+		Description:       "Mangler HEALTHCHECK",
+		DisabledByDefault: true, // Kubernetes bruker egne prober, så dette er ofte støy
+		Check: func(doc *Document) []Location {
+			final, ok := finalStage(doc)
+			if !ok || doc.Features.HasHealthcheck {
+				return nil
+			}
+			return []Location{{StartLine: final.Line, EndLine: final.Line}}
+		},
+	})
+}
+
+func finalStage(doc *Document) (parser.DockerStageMeta, bool) {
+	if len(doc.Stages) == 0 {
+		return parser.DockerStageMeta{}, false
+	}
+	return doc.Stages[len(doc.Stages)-1], true
+}
+
+// stageInstructions returnerer instruksjonene i stagen med gitt indeks, uten selve FROM.
+func stageInstructions(doc *Document, stageIndex int) []parser.Instruction {
+	var result []parser.Instruction
+	current := -1
+	for _, inst := range doc.AST.Instructions {
+		if inst.Cmd == "from" {
+			if len(inst.Args) > 0 {
+				current++
+			}
+			continue
+		}
+		if current == stageIndex {
+			result = append(result, inst)
+		}
+	}
+	return result
+}
+
+func filter(instructions []parser.Instruction, cmds ...string) []parser.Instruction {
+	var result []parser.Instruction
+	for _, inst := range instructions {
+		for _, cmd := range cmds {
+			if inst.Cmd == cmd {
+				result = append(result, inst)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func matching(instructions []parser.Instruction, pred func(parser.Instruction) bool) []Location {
+	var locs []Location
+	for _, inst := range instructions {
+		if pred(inst) {
+			locs = append(locs, at(inst))
+		}
+	}
+	return locs
+}
+
+// matchingScript sjekker skriptet i hver RUN, inkludert heredocs, med små bokstaver.
+func matchingScript(doc *Document, pred func(script string) bool) []Location {
+	return matching(filter(doc.AST.Instructions, "run"), func(inst parser.Instruction) bool {
+		return pred(strings.ToLower(inst.Text()))
+	})
+}
+
+func locations(instructions []parser.Instruction) []Location {
+	return matching(instructions, func(parser.Instruction) bool { return true })
+}
+
+func at(inst parser.Instruction) Location {
+	return Location{StartLine: inst.StartLine, EndLine: inst.EndLine}
+}
@@ -1,10 +1,227 @@
 package models
 
+import "time"
+
 type FileEntry struct {
 	Path    string `json:"path"`
 	Content string `json:"content"`
 }
 
+// SkippedFile er en ønsket fil vi ikke fikk lagret innholdet til, med årsak
+// (binary, truncated, too_large eller fetch_failed).
+type SkippedFile struct {
+	Path     string `json:"path"`
+	Reason   string `json:"reason"`
+	ByteSize int64  `json:"byte_size"`
+}
+
+// DockerfileFinding er ett funn fra Dockerfile-linteren.
+type DockerfileFinding struct {
+	Path        string `json:"path"`
+	RuleID      string `json:"rule_id"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+}
+
+// WorkflowFinding er ett funn fra sikkerhetssjekkene av GitHub Actions-workflows.
+type WorkflowFinding struct {
+	Path        string `json:"path"`
+	RuleID      string `json:"rule_id"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	Job         string `json:"job"`
+	Line        int    `json:"line"`
+}
+
+// UpdateBotConfig er én oppføring i konfigen til Dependabot eller Renovate.
+// Ecosystem bruker Dependabots navn, "*" betyr alle økosystemer.
+type UpdateBotConfig struct {
+	Tool      string   `json:"tool"` // dependabot eller renovate
+	Path      string   `json:"path"`
+	Ecosystem string   `json:"ecosystem"`
+	Directory string   `json:"directory"`
+	Schedule  string   `json:"schedule"`
+	Groups    []string `json:"groups"`
+	AutoMerge bool     `json:"auto_merge"`
+}
+
+// EcosystemCoverage sier om et økosystem vi fant manifester for dekkes av en oppdateringsbot.
+type EcosystemCoverage struct {
+	Ecosystem string   `json:"ecosystem"`
+	Manifests []string `json:"manifests"`
+	CoveredBy []string `json:"covered_by"` // tom når ingen bot dekker økosystemet
+}
+
+// CodeownersRule er én linje i CODEOWNERS. Error er satt for linjer GitHub hopper over.
+type CodeownersRule struct {
+	Line          int      `json:"line"`
+	Pattern       string   `json:"pattern"`
+	Owners        []string `json:"owners"`
+	UnknownOwners []string `json:"unknown_owners"` // team og brukere som ikke finnes i org
+	Error         string   `json:"error"`
+}
+
+// CodeownersPath er eierskapet til en mappe på toppnivå eller en hentet fil.
+type CodeownersPath struct {
+	Path     string   `json:"path"`
+	IsDir    bool     `json:"is_dir"`
+	Owners   []string `json:"owners"`
+	RuleLine int      `json:"rule_line"` // 0 når ingen regel matcher
+}
+
+// CodeownersReport er CODEOWNERS-filen i repoet og hvor mye den dekker.
+// File er tom når repoet ikke har CODEOWNERS.
+type CodeownersReport struct {
+	File         string           `json:"file"`
+	Rules        []CodeownersRule `json:"rules"`
+	Paths        []CodeownersPath `json:"paths"`
+	OwnedPercent float64          `json:"owned_percent"`
+}
+
+// FrameworkDetection er et rammeverk eller en runtime vi mener repoet bruker.
+// Confidence er 0–1, og Evidence sier hvilke signaler som ga treff.
+type FrameworkDetection struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"` // "framework" eller "runtime"
+	Version    string   `json:"version"`
+	Confidence float64  `json:"confidence"`
+	Evidence   []string `json:"evidence"`
+}
+
+// RuntimeVersion er en runtime- eller toolchain-versjon slik den er satt i én fil.
+// Version er normalisert (major for Java og Node, major.minor for Go og Python),
+// mens Raw er verdien slik den står, f.eks. ">=18.0.0" eller "eclipse-temurin:21-jre".
+type RuntimeVersion struct {
+	Runtime string `json:"runtime"`
+	Version string `json:"version"`
+	Raw     string `json:"raw"`
+	Source  string `json:"source"`  // filen versjonen står i
+	Setting string `json:"setting"` // hva som setter den, f.eks. "toolchain", "engines.node" eller "actions/setup-node"
+}
+
+// Dependency er en avhengighet deklarert i et manifest, i motsetning til SBOM-en
+// som også har transitive pakker. Name har samme form som i SBOM-en, f.eks. "group:artifact" for Maven.
+type Dependency struct {
+	Ecosystem  string `json:"ecosystem"` // purl-type: golang, npm, maven eller pypi
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"` // versjonskravet slik det står, f.eks. "^18.2.0" eller ">=0.110"
+	Scope      string `json:"scope"`      // runtime, dev eller test
+	Kind       string `json:"kind"`       // direct, parent, managed eller plugin
+	Source     string `json:"source"`     // manifestet avhengigheten står i
+}
+
+// SecretFinding er en hemmelighet funnet i en hentet fil. Verdien er fjernet fra innholdet
+// og lagres aldri; Hash er SHA-256 av den.
+type SecretFinding struct {
+	Path        string `json:"path"`
+	RuleID      string `json:"rule_id"`
+	Description string `json:"description"`
+	Line        int    `json:"line"`
+	Hash        string `json:"hash"`
+}
+
+// DeployManifest er deploy-fakta for én ressurs i et NAIS-, Kubernetes- eller Helm-manifest.
+// Pekere er nil når feltet ikke er satt i manifestet.
+type DeployManifest struct {
+	Path                   string   `json:"path"`
+	Kind                   string   `json:"kind"` // "nais-application", "deployment", "helm-values" osv.
+	Name                   string   `json:"name"`
+	Namespace              string   `json:"namespace"`
+	Images                 []string `json:"images"`
+	ReplicasMin            *int     `json:"replicas_min"`
+	ReplicasMax            *int     `json:"replicas_max"`
+	CPURequest             string   `json:"cpu_request"`
+	CPULimit               string   `json:"cpu_limit"`
+	MemoryRequest          string   `json:"memory_request"`
+	MemoryLimit            string   `json:"memory_limit"`
+	IngressHosts           []string `json:"ingress_hosts"`
+	InboundAccess          []string `json:"inbound_access"`
+	OutboundAccess         []string `json:"outbound_access"`
+	LivenessProbe          string   `json:"liveness_probe"` // HTTP-sti eller probetype, tom hvis ingen
+	ReadinessProbe         string   `json:"readiness_probe"`
+	RunAsNonRoot           *bool    `json:"run_as_non_root"`
+	ReadOnlyRootFilesystem *bool    `json:"read_only_root_filesystem"`
+}
+
+// IaCModule er én versjonsbinding i infrastrukturkode: Terraform-versjon, provider, modul eller backend.
+type IaCModule struct {
+	Path       string `json:"path"`
+	Tool       string `json:"tool"` // "terraform", "pulumi" eller "bicep"
+	Kind       string `json:"kind"` // "required_version", "provider", "locked_provider", "module", "backend" eller "project"
+	Name       string `json:"name"`
+	Source     string `json:"source"`
+	SourceType string `json:"source_type"` // for moduler: "local", "registry", "git" osv.
+	Version    string `json:"version"`     // versjonskrav, låst versjon eller git-ref
+	Pinned     *bool  `json:"pinned"`      // bare satt for moduler med ekstern kilde
+}
+
+// ComposeService er én tjeneste i en Docker Compose-fil. Image er tolket som FROM i Dockerfiles.
+type ComposeService struct {
+	Path           string          `json:"path"`
+	Service        string          `json:"service"`
+	Image          string          `json:"image"` // tom når tjenesten bare bygges lokalt
+	Tag            string          `json:"tag"`
+	RawImage       string          `json:"raw_image"` // image-uttrykket når det ikke kunne løses opp
+	Registry       string          `json:"registry"`
+	IsDigestPinned bool            `json:"is_digest_pinned"`
+	Digest         string          `json:"digest"`
+	BuildContext   string          `json:"build_context"`
+	Dockerfile     string          `json:"dockerfile"`
+	Ports          []string        `json:"ports"`
+	Privileged     bool            `json:"privileged"`
+	NetworkMode    string          `json:"network_mode"`
+	EnvFiles       []string        `json:"env_files"`
+	Volumes        []ComposeVolume `json:"volumes"`
+}
+
+// ComposeVolume er et volum, en bind mount eller en secret montert inn i en Compose-tjeneste.
+type ComposeVolume struct {
+	Type     string `json:"type"` // "bind", "volume", "tmpfs" eller "secret"
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	ReadOnly bool   `json:"read_only"`
+}
+
+// ReadmeMetrics er struktur- og kvalitetsmål for README-en i repoet.
+type ReadmeMetrics struct {
+	Length          int      `json:"length"` // antall tegn
+	WordCount       int      `json:"word_count"`
+	Headings        []string `json:"headings"`
+	HasInstallation bool     `json:"has_installation"`
+	HasUsage        bool     `json:"has_usage"`
+	HasContact      bool     `json:"has_contact"`
+	HasOwnership    bool     `json:"has_ownership"`
+	Badges          []string `json:"badges"` // f.eks. "github-actions", "shields.io", "codecov"
+	InternalLinks   int      `json:"internal_links"`
+	ExternalLinks   int      `json:"external_links"`
+	CodeBlocks      int      `json:"code_blocks"`
+	Language        string   `json:"language"`         // "no", "en", "mixed" eller "unknown"
+	TemplateMarkers []string `json:"template_markers"` // maltekst som aldri ble fylt ut
+}
+
+// FileFingerprint er normalisert hash og likhetsfingeravtrykk for en Dockerfile eller workflow.
+type FileFingerprint struct {
+	Path        string   `json:"path"`
+	FileType    string   `json:"file_type"`    // "dockerfile" eller "workflow"
+	ContentHash string   `json:"content_hash"` // sha256 av normalisert innhold
+	SimHash     string   `json:"simhash"`      // 64 bits som hex
+	LineCount   int      `json:"line_count"`   // antall linjer etter normalisering
+	LineHashes  []uint64 `json:"-"`            // brukes bare til gruppering i samme kjøring
+}
+
+// BaseImageInfo er registry-oppslaget for én base image-referanse i repoet.
+type BaseImageInfo struct {
+	Reference     string     `json:"reference"` // image:tag slik det er brukt i FROM
+	Registry      string     `json:"registry"`
+	PinnedDigest  string     `json:"pinned_digest"`
+	CurrentDigest string     `json:"current_digest"`
+	ImageCreated  *time.Time `json:"image_created"`
+	DigestDiffers bool       `json:"digest_differs"` // pinnet digest er ikke lenger det taggen peker på
+	Error         string     `json:"error"`
+}
+
 type License struct {
 	SpdxID string `json:"spdx_id"`
 }
@@ -32,14 +249,49 @@ type RepoMeta struct {
 	License      *License        `json:"license"`
 	Readme       string          `json:"readme"`
 	Security     map[string]bool `json:"security"`
+
+	DefaultBranch string      `json:"default_branch"`
+	Lineage       RepoLineage `json:"lineage"`
+}
+
+// RepoLineage beskriver hvor et repo kommer fra: upstream-repoet for forks og
+// malen for repos generert fra template. AheadBy/BehindBy er nil når vi ikke fikk sammenlignet.
+type RepoLineage struct {
+	ParentFullName      string `json:"parent_full_name"`
+	ParentInOrg         bool   `json:"parent_in_org"`
+	ParentDefaultBranch string `json:"parent_default_branch"`
+	TemplateFullName    string `json:"template_full_name"`
+	TemplateInOrg       bool   `json:"template_in_org"`
+	AheadBy             *int   `json:"ahead_by"`
+	BehindBy            *int   `json:"behind_by"`
 }
 
 type RepoEntry struct {
-	Repo      RepoMeta               `json:"repo"`
-	Languages map[string]int         `json:"languages"`
-	Files     map[string][]FileEntry `json:"files"`
-	CIConfig  []FileEntry            `json:"ci_config"`
-	SBOM      map[string]interface{} `json:"sbom"`
+	Repo         RepoMeta               `json:"repo"`
+	Languages    map[string]int         `json:"languages"`
+	Files        map[string][]FileEntry `json:"files"`
+	CIConfig     []FileEntry            `json:"ci_config"`
+	SBOM         map[string]interface{} `json:"sbom"`
+	SBOMSource   string                 `json:"sbom_source"` // "github" eller "local", tom uten SBOM
+	SkippedFiles []SkippedFile          `json:"skipped_files"`
+	RootFiles    []string               `json:"root_files"` // alle filnavn på toppnivå, også de vi ikke henter
+	RootDirs     []string               `json:"root_dirs"`
+
+	DockerfileFindings []DockerfileFinding  `json:"dockerfile_findings"`
+	BaseImages         []BaseImageInfo      `json:"base_images"`
+	WorkflowFindings   []WorkflowFinding    `json:"workflow_findings"`
+	UpdateBots         []UpdateBotConfig    `json:"update_bots"`
+	EcosystemCoverage  []EcosystemCoverage  `json:"ecosystem_coverage"`
+	Codeowners         CodeownersReport     `json:"codeowners"`
+	Frameworks         []FrameworkDetection `json:"frameworks"`
+	RuntimeVersions    []RuntimeVersion     `json:"runtime_versions"`
+	Dependencies       []Dependency         `json:"dependencies"`
+	DeployManifests    []DeployManifest     `json:"deploy_manifests"`
+	IaCModules         []IaCModule          `json:"iac_modules"`
+	ComposeServices    []ComposeService     `json:"compose_services"`
+	ReadmeMetrics      *ReadmeMetrics       `json:"readme_metrics"`
+	FileFingerprints   []FileFingerprint    `json:"file_fingerprints"`
+	SecretFindings     []SecretFinding      `json:"secret_findings"`
 }
 
 type OrgRepos struct {
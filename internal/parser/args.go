@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// UnresolvedImage brukes som base image når FROM refererer til ARG-er uten kjent verdi.
+const UnresolvedImage = "unresolved"
+
+// $VAR, ${VAR}, ${VAR:-default} og ${VAR:+alternativ}
+var argReferencePattern = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)(?:(:[-+])([^}]*))?\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// expandArgs erstatter ARG-referanser i s med verdier fra vars.
+// Returnerer false hvis minst én referanse mangler verdi og ikke har en default.
+func expandArgs(s string, vars map[string]string) (string, bool) {
+	resolved := true
+	expanded := argReferencePattern.ReplaceAllStringFunc(s, func(match string) string {
+		m := argReferencePattern.FindStringSubmatch(match)
+		name, op, word := m[1], m[2], m[3]
+		if name == "" {
+			name = m[4]
+		}
+		value, ok := vars[name]
+
+		switch op {
+		case ":-":
+			if !ok || value == "" {
+				w, wordResolved := expandArgs(word, vars)
+				resolved = resolved && wordResolved
+				return w
+			}
+		case ":+":
+			if !ok || value == "" {
+				return ""
+			}
+			w, wordResolved := expandArgs(word, vars)
+			resolved = resolved && wordResolved
+			return w
+		}
+
+		if !ok {
+			resolved = false
+			return match
+		}
+		return value
+	})
+	return expanded, resolved
+}
+
+// ExpandImage løser opp ${VAR:-default} i en bildereferanse uten kjente variabler,
+// slik docker compose gjør når variabelen ikke er satt. Returnerer false hvis noe står uløst.
+func ExpandImage(raw string) (string, bool) {
+	return expandArgs(raw, nil)
+}
+
+// declareArgs legger ARG-ene fra én instruksjon inn i scope.
+// ARG uten verdi arver fra inherit (globale ARG-er deklarert på nytt i en stage).
+func declareArgs(inst Instruction, scope, inherit map[string]string) {
+	for _, arg := range inst.Args {
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !hasValue {
+			if v, ok := inherit[name]; ok {
+				scope[name] = v
+			}
+			continue
+		}
+		value = strings.Trim(value, `"'`)
+		if expanded, ok := expandArgs(value, scope); ok {
+			scope[name] = expanded
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package parser_test
+
+import (
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ARG-oppløsning i FROM", func() {
+	DescribeTable("løser opp base image fra ARG-er",
+		func(content string, expected parser.DockerStageMeta) {
+			_, stages := parser.ParseDockerfile(content)
+			Expect(stages).NotTo(BeEmpty())
+			Expect(stages[0]).To(Equal(expected))
+		},
+
+		Entry("global ARG med ${VAR}",
+			"ARG BASE=eclipse-temurin:21\nFROM ${BASE}",
+			parser.DockerStageMeta{Line: 2, BaseImage: "eclipse-temurin", BaseTag: "21", Registry: "docker.io", IsFinal: true},
+		),
+		Entry("$VAR uten klammer og ARG som bruker en annen ARG",
+			"ARG REGISTRY=ghcr.io/navikt\nARG IMAGE=$REGISTRY/app\nARG TAG=\"1.0\"\nFROM $IMAGE:$TAG",
+			parser.DockerStageMeta{Line: 4, BaseImage: "ghcr.io/navikt/app", BaseTag: "1.0", Registry: "ghcr.io", IsFinal: true},
+		),
+		Entry("${VAR:-default} når ARG mangler verdi",
+			"ARG JAVA_VERSION\nFROM eclipse-temurin:${JAVA_VERSION:-17}-jre",
+			parser.DockerStageMeta{Line: 2, BaseImage: "eclipse-temurin", BaseTag: "17-jre", Registry: "docker.io", IsFinal: true},
+		),
+		Entry("ukjent ARG gir unresolved med rått uttrykk",
+			"ARG BASE\nFROM ${BASE}",
+			parser.DockerStageMeta{Line: 2, BaseImage: parser.UnresolvedImage, RawImage: "${BASE}", IsFinal: true},
+		),
+	)
+
+	It("lar ikke stage-ARG påvirke senere FROM, men bruker dem i stagen", func() {
+		features, stages := parser.ParseDockerfile(`ARG BASE=alpine:3.20
+FROM ${BASE} AS build
+ARG BASE=debian:12
+ARG PKG=curl
+RUN apk add ${PKG}
+FROM ${BASE}`)
+
+		Expect(stages).To(HaveLen(2))
+		Expect(stages[1].BaseImage).To(Equal("alpine"))
+		Expect(stages[1].BaseTag).To(Equal("3.20"))
+		Expect(features.InstallsCurlOrWget).To(BeTrue())
+	})
+
+	It("kjenner igjen FROM som løses opp til et alias", func() {
+		_, stages := parser.ParseDockerfile("ARG STAGE=build\nFROM golang:1.22 AS build\nFROM ${STAGE}")
+		Expect(stages).To(HaveLen(2))
+		Expect(stages[1].BaseStage).To(Equal("build"))
+		Expect(stages[1].BaseImage).To(BeEmpty())
+	})
+})
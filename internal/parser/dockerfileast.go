@@ -0,0 +1,202 @@
+package parser
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Dockerfile er en Dockerfile parset til instruksjoner, omtrent slik BuildKit leser den.
+type Dockerfile struct {
+	Directives   map[string]string // parser-direktiver øverst i filen, f.eks. escape og syntax
+	EscapeToken  byte
+	Instructions []Instruction
+}
+
+// Instruction er én instruksjon etter at linjefortsettelser er slått sammen
+// og kommentarlinjer er fjernet.
+type Instruction struct {
+	Cmd       string    // instruksjonsnavn i små bokstaver, f.eks. "run"
+	Flags     []string  // flagg foran argumentene, f.eks. "--from=builder"
+	Args      []string  // argumentene; for exec-form er dette elementene i JSON-arrayet
+	Raw       string    // argumentteksten slik den står, uten flagg
+	ExecForm  bool      // true når argumentene er et JSON-array (["cmd", "arg"])
+	Heredocs  []Heredoc // innebygde heredoc-blokker (RUN <<EOF ... EOF)
+	StartLine int       // første linje i filen (1-basert)
+	EndLine   int       // siste linje, inkludert fortsettelser og heredocs
+}
+
+type Heredoc struct {
+	Name    string
+	Content string
+}
+
+var (
+	directivePattern = regexp.MustCompile(`^#\s*([a-zA-Z][a-zA-Z0-9]*)\s*=\s*(.+?)\s*$`)
+	heredocPattern   = regexp.MustCompile(`<<(-?)(["']?)([A-Za-z_][A-Za-z0-9_]*)(["']?)`)
+)
+
+// Instruksjoner der BuildKit tillater heredocs.
+var heredocInstructions = map[string]bool{"run": true, "copy": true, "add": true}
+
+// ParseDockerfileAST deler en Dockerfile i instruksjoner med flagg, argumenter og linjenumre.
+// Den håndterer parser-direktiver (# escape=`), linjefortsettelser og heredocs.
+func ParseDockerfileAST(content string) *Dockerfile {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	df := &Dockerfile{
+		Directives:  map[string]string{},
+		EscapeToken: '\\',
+	}
+
+	i := parseDirectives(lines, df)
+
+	for i < len(lines) {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			i++
+			continue
+		}
+
+		start := i
+		logical, next := joinContinuation(lines, i, df.EscapeToken)
+		i = next
+
+		inst := newInstruction(logical)
+		inst.StartLine = start + 1
+		inst.EndLine = i
+
+		if heredocInstructions[inst.Cmd] {
+			i = readHeredocs(lines, i, &inst)
+		}
+
+		df.Instructions = append(df.Instructions, inst)
+	}
+
+	return df
+}
+
+// parseDirectives leser parser-direktiver, som bare er gyldige før første instruksjon,
+// tomme linje eller vanlige kommentar. Returnerer indeksen til første linje etter direktivene.
+func parseDirectives(lines []string, df *Dockerfile) int {
+	for i, line := range lines {
+		m := directivePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			return i
+		}
+		key := strings.ToLower(m[1])
+		if _, seen := df.Directives[key]; seen {
+			return i
+		}
+		df.Directives[key] = m[2]
+		if key == "escape" && (m[2] == "`" || m[2] == "\\") {
+			df.EscapeToken = m[2][0]
+		}
+	}
+	return len(lines)
+}
+
+// joinContinuation slår sammen linjer som slutter med escape-tegnet.
+// Kommentarer og tomme linjer midt i en fortsettelse hoppes over, slik BuildKit gjør.
+func joinContinuation(lines []string, i int, escape byte) (string, int) {
+	var parts []string
+	for i < len(lines) {
+		line := strings.TrimRight(lines[i], " \t")
+		i++
+
+		trimmed := strings.TrimSpace(line)
+		if len(parts) > 0 && (trimmed == "" || strings.HasPrefix(trimmed, "#")) {
+			continue
+		}
+
+		if strings.HasSuffix(line, string(escape)) {
+			parts = append(parts, strings.TrimSuffix(line, string(escape)))
+			continue
+		}
+		parts = append(parts, line)
+		break
+	}
+	return strings.TrimSpace(strings.Join(parts, " ")), i
+}
+
+func newInstruction(logical string) Instruction {
+	cmd, rest, _ := strings.Cut(logical, " ")
+	inst := Instruction{Cmd: strings.ToLower(strings.TrimSpace(cmd))}
+	rest = strings.TrimSpace(rest)
+
+	// Flagg som --from=, --chown=, --mount= står først
+	for strings.HasPrefix(rest, "--") {
+		flag, remainder, _ := strings.Cut(rest, " ")
+		inst.Flags = append(inst.Flags, flag)
+		rest = strings.TrimSpace(remainder)
+	}
+	inst.Raw = rest
+
+	if strings.HasPrefix(rest, "[") {
+		var exec []string
+		if err := json.Unmarshal([]byte(rest), &exec); err == nil {
+			inst.Args = exec
+			inst.ExecForm = true
+			return inst
+		}
+	}
+	inst.Args = strings.Fields(rest)
+	return inst
+}
+
+// readHeredocs leser heredoc-blokkene instruksjonen åpner, i rekkefølge.
+// Returnerer indeksen til første linje etter siste avslutningsmarkør.
+func readHeredocs(lines []string, i int, inst *Instruction) int {
+	for _, m := range heredocPattern.FindAllStringSubmatchIndex(inst.Raw, -1) {
+		// <<< er en here-string i shell, ikke en heredoc
+		if m[0] > 0 && inst.Raw[m[0]-1] == '<' {
+			continue
+		}
+		stripTabs := inst.Raw[m[2]:m[3]] == "-"
+		name := inst.Raw[m[6]:m[7]]
+
+		var body []string
+		for i < len(lines) {
+			line := lines[i]
+			i++
+			check := line
+			if stripTabs {
+				check = strings.TrimLeft(line, "\t")
+			}
+			if strings.TrimRight(check, " \t") == name {
+				break
+			}
+			body = append(body, check)
+		}
+		inst.Heredocs = append(inst.Heredocs, Heredoc{Name: name, Content: strings.Join(body, "\n")})
+		inst.EndLine = i
+	}
+	return i
+}
+
+// Text returnerer argumentene og eventuelle heredoc-innhold som én streng,
+// nyttig for å lete etter kommandoer i RUN.
+func (inst Instruction) Text() string {
+	if len(inst.Heredocs) == 0 {
+		return inst.Raw
+	}
+	parts := []string{inst.Raw}
+	for _, h := range inst.Heredocs {
+		parts = append(parts, h.Content)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Flag returnerer verdien til et flagg som --from=builder, og om det finnes.
+func (inst Instruction) Flag(name string) (string, bool) {
+	prefix := "--" + name
+	for _, f := range inst.Flags {
+		if f == prefix {
+			return "", true
+		}
+		if strings.HasPrefix(f, prefix+"=") {
+			return strings.TrimPrefix(f, prefix+"="), true
+		}
+	}
+	return "", false
+}
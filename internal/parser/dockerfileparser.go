@@ -1,12 +1,16 @@
 package parser
 
 import (
+	"regexp"
 	"strings"
 )
 
 type DockerfileFeatures struct {
 	BaseImage            string
 	BaseTag              string
+	RawImage             string // FROM-uttrykket når base image ikke kunne løses opp
+	Registry             string
+	IsDigestPinned       bool
 	UsesLatestTag        bool
 	HasUserInstruction   bool
 	HasCopySensitive     bool
@@ -22,116 +26,282 @@ type DockerfileFeatures struct {
 	HasAptGetClean       bool
 	WorldWritable        bool
 	HasSecretsInEnvOrArg bool
+
+	// Gjelder bare siste stage, som er imaget som faktisk kjøres
+	FinalStageIndex         int
+	FinalRunsAsRoot         *bool // nil når base image bestemmer brukeren
+	FinalInstallsBuildTools bool
 }
 
+// DockerStageMeta beskriver én FROM-stage. USER, installasjoner og entrypoint
+// inkluderer det stagen arver fra BaseStage.
 type DockerStageMeta struct {
-	StageIndex int
-	BaseImage  string
-	BaseTag    string
+	StageIndex     int
+	Alias          string
+	BaseImage      string
+	BaseTag        string
+	RawImage       string // FROM-uttrykket når base image ikke kunne løses opp
+	Registry       string
+	IsDigestPinned bool
+	Digest         string // digest fra FROM når imaget er pinnet
+	BaseStage      string // alias for en tidligere stage når FROM bygger videre på den
+	Line           int    // linjen FROM står på
+
+	User               string
+	RunsAsRoot         *bool // nil når stagen ikke har USER og base image bestemmer brukeren
+	HasPackageInstalls bool
+	InstallsBuildTools bool
+	InstallsCurlOrWget bool
+	CopyFrom           []string // stages eller images fra COPY --from
+	ExposedPorts       []string
+	Entrypoint         string
+	Cmd                string
+	IsFinal            bool
 }
 
+var (
+	packageInstallPattern = regexp.MustCompile(`\b(apt-get install|apt install|apk add|yum install|dnf install|microdnf install)\b`)
+	curlWgetPattern       = regexp.MustCompile(`\b(curl|wget)\b`)
+)
+
+// Mønstrene deles med linteren, så features og funn bruker samme definisjon. De matches mot tekst i små bokstaver.
+var (
+	BuildToolsPattern    = regexp.MustCompile(`(^|[\s;&|])(gcc|g\+\+|make|build-essential)($|[\s;&|])`)
+	WorldWritablePattern = regexp.MustCompile(`\bchmod\s+(-r\s+)?0?777\b`)
+	SecretNamePattern    = regexp.MustCompile(`password|passwd|token|secret|api_?key`)
+)
+
 func ParseDockerfile(content string) (DockerfileFeatures, []DockerStageMeta) {
-	lines := strings.Split(content, "\n")
+	return AnalyzeDockerfileAST(ParseDockerfileAST(content))
+}
 
+// AnalyzeDockerfileAST utleder features og stages fra en Dockerfile som allerede er parset.
+func AnalyzeDockerfileAST(df *Dockerfile) (DockerfileFeatures, []DockerStageMeta) {
 	var features DockerfileFeatures
 	var stages []DockerStageMeta
-	knownAliases := map[string]bool{}
-	stageIndex := 0
-
-	for _, rawLine := range lines {
-		line := strings.TrimSpace(strings.ToLower(rawLine))
-
-		// === FROM parsing ===
-		if strings.HasPrefix(strings.ToLower(line), "from ") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				image := parts[1]
-				imageLower := strings.ToLower(image)
-
-				// FROM ... AS alias
-				if len(parts) >= 4 && strings.ToLower(parts[2]) == "as" {
-					alias := parts[3]
-					knownAliases[alias] = true
-				}
+	stageByAlias := map[string]int{}
 
-				// Skip if the "image" is an alias
-				if knownAliases[imageLower] || strings.HasPrefix(image, "${") {
-					continue
-				}
+	// ARG før første FROM er globale og kan brukes i FROM-linjer.
+	// ARG inne i en stage gjelder bare resten av den stagen.
+	globalArgs := map[string]string{}
+	var stageArgs map[string]string
 
-				baseImage := image
-				baseTag := "latest"
-				if strings.Contains(image, ":") {
-					split := strings.SplitN(image, ":", 2)
-					baseImage = split[0]
-					baseTag = split[1]
-				}
+	for _, inst := range df.Instructions {
+		var stage *DockerStageMeta
+		if len(stages) > 0 {
+			stage = &stages[len(stages)-1]
+		}
 
-				if baseTag == "latest" {
-					features.UsesLatestTag = true
-				}
+		switch inst.Cmd {
+		case "from":
+			stageArgs = map[string]string{}
+			if len(inst.Args) == 0 {
+				continue
+			}
+			next := DockerStageMeta{StageIndex: len(stages), Line: inst.StartLine}
 
-				// Sett første base-image i DockerfileFeatures
-				if features.BaseImage == "" {
-					features.BaseImage = baseImage
-					features.BaseTag = baseTag
-				}
+			// FROM ... AS alias
+			if len(inst.Args) >= 3 && strings.EqualFold(inst.Args[1], "as") {
+				next.Alias = strings.ToLower(inst.Args[2])
+			}
+
+			rawImage := inst.Args[0]
+			expanded, resolved := expandArgs(rawImage, globalArgs)
+			image := strings.ToLower(expanded)
 
-				stages = append(stages, DockerStageMeta{
-					StageIndex: stageIndex,
-					BaseImage:  baseImage,
-					BaseTag:    baseTag,
-				})
-				stageIndex++
+			if _, isStage := stageByAlias[image]; resolved && isStage {
+				next.BaseStage = image
+			} else {
+				resolveBaseImage(&next, rawImage, expanded, resolved, &features)
 			}
-			continue
-		}
 
-		// === Feature flags ===
-		switch {
-		case strings.HasPrefix(line, "user "):
+			if next.Alias != "" {
+				stageByAlias[next.Alias] = next.StageIndex
+			}
+			stages = append(stages, next)
+
+		case "user":
 			features.HasUserInstruction = true
-		case strings.HasPrefix(line, "label "):
+			if stage != nil {
+				stage.User, _ = expandArgs(strings.TrimSpace(inst.Raw), stageArgs)
+			}
+		case "label":
 			features.HasLabelMetadata = true
-		case strings.HasPrefix(line, "expose "):
+		case "expose":
 			features.HasExpose = true
-		case strings.HasPrefix(line, "entrypoint"), strings.HasPrefix(line, "cmd"):
+			if stage != nil {
+				stage.ExposedPorts = append(stage.ExposedPorts, inst.Args...)
+			}
+		case "entrypoint", "cmd":
 			features.HasEntrypointOrCmd = true
-		case strings.HasPrefix(line, "healthcheck"):
+			if stage != nil && inst.Cmd == "entrypoint" {
+				stage.Entrypoint = strings.Join(inst.Args, " ")
+			} else if stage != nil {
+				stage.Cmd = strings.Join(inst.Args, " ")
+			}
+		case "healthcheck":
 			features.HasHealthcheck = true
-		case strings.HasPrefix(line, "copy "), strings.HasPrefix(line, "add "):
-			if strings.Contains(line, "add ") {
+			// Healthchecks som kaller curl/wget krever at verktøyet finnes i imaget
+			if curlWgetPattern.MatchString(strings.ToLower(inst.Raw)) {
+				features.InstallsCurlOrWget = true
+				if stage != nil {
+					stage.InstallsCurlOrWget = true
+				}
+			}
+		case "copy", "add":
+			if inst.Cmd == "add" {
 				features.UsesAddInstruction = true
 			}
-			if strings.Contains(line, ".ssh") || strings.Contains(line, "id_rsa") || strings.Contains(line, "secrets") {
+			sources := strings.ToLower(strings.Join(inst.Args, " "))
+			if strings.Contains(sources, ".ssh") || strings.Contains(sources, "id_rsa") || strings.Contains(sources, "secrets") {
 				features.HasCopySensitive = true
 			}
-		}
+			if from, ok := inst.Flag("from"); ok && stage != nil {
+				stage.CopyFrom = append(stage.CopyFrom, strings.ToLower(from))
+			}
+		case "env", "arg":
+			if inst.Cmd == "arg" {
+				if stageArgs == nil {
+					declareArgs(inst, globalArgs, nil)
+				} else {
+					declareArgs(inst, stageArgs, globalArgs)
+				}
+			}
+			if SetsSecret(inst) {
+				features.HasSecretsInEnvOrArg = true
+			}
+		case "run":
+			script, _ := expandArgs(inst.Text(), stageArgs)
+			script = strings.ToLower(script)
+			installs := packageInstallPattern.MatchString(script)
+			curlOrWget := curlWgetPattern.MatchString(script)
+			buildTools := BuildToolsPattern.MatchString(script)
 
-		if strings.Contains(line, "apt-get install") ||
-			strings.Contains(line, "apk add") ||
-			strings.Contains(line, "yum install") ||
-			strings.Contains(line, "dnf install") {
-			features.HasPackageInstalls = true
-		}
-		if strings.Contains(line, "curl") || strings.Contains(line, "wget") {
-			features.InstallsCurlOrWget = true
+			features.HasPackageInstalls = features.HasPackageInstalls || installs
+			features.InstallsCurlOrWget = features.InstallsCurlOrWget || curlOrWget
+			features.InstallsBuildTools = features.InstallsBuildTools || buildTools
+			if stage != nil {
+				stage.HasPackageInstalls = stage.HasPackageInstalls || installs
+				stage.InstallsCurlOrWget = stage.InstallsCurlOrWget || curlOrWget
+				stage.InstallsBuildTools = stage.InstallsBuildTools || buildTools
+			}
+			if strings.Contains(script, "apt-get clean") {
+				features.HasAptGetClean = true
+			}
+			if WorldWritablePattern.MatchString(script) {
+				features.WorldWritable = true
+			}
 		}
-		if strings.Contains(line, "gcc") || strings.Contains(line, "make") || strings.Contains(line, "build-essential") {
-			features.InstallsBuildTools = true
+	}
+
+	inheritFromBaseStages(stages, stageByAlias)
+
+	features.UsesMultistage = len(stages) > 1
+	if len(stages) > 0 {
+		final := &stages[len(stages)-1]
+		final.IsFinal = true
+		features.FinalStageIndex = final.StageIndex
+		features.FinalRunsAsRoot = final.RunsAsRoot
+		features.FinalInstallsBuildTools = final.InstallsBuildTools
+	}
+	return features, stages
+}
+
+// resolveBaseImage fyller inn image-feltene for en stage som bygger på et eksternt image.
+// Første eksterne image blir også base image for hele filen.
+func resolveBaseImage(stage *DockerStageMeta, rawImage, expanded string, resolved bool, features *DockerfileFeatures) {
+	stage.BaseImage = strings.ToLower(expanded)
+	if !resolved {
+		stage.BaseImage = UnresolvedImage
+		stage.RawImage = rawImage
+	} else if ref, err := ParseImageRef(expanded); err == nil {
+		stage.BaseImage = ref.Name
+		stage.BaseTag = ref.Tag
+		stage.Registry = ref.Registry
+		stage.IsDigestPinned = ref.IsDigestPinned()
+		stage.Digest = ref.Digest
+		// Uten tag og digest bruker Docker "latest"
+		if ref.Tag == "" && !ref.IsDigestPinned() {
+			stage.BaseTag = "latest"
 		}
-		if strings.Contains(line, "apt-get clean") {
-			features.HasAptGetClean = true
+	}
+
+	if stage.BaseTag == "latest" && !stage.IsDigestPinned {
+		features.UsesLatestTag = true
+	}
+
+	// Sett første base-image i DockerfileFeatures
+	if features.BaseImage == "" {
+		features.BaseImage = stage.BaseImage
+		features.BaseTag = stage.BaseTag
+		features.RawImage = stage.RawImage
+		features.Registry = stage.Registry
+		features.IsDigestPinned = stage.IsDigestPinned
+	}
+}
+
+// inheritFromBaseStages lar stages som bygger på en tidligere stage arve USER,
+// installasjoner og entrypoint, og setter RunsAsRoot ut fra effektiv bruker.
+// Uten USER er det base image som bestemmer, og da lar vi RunsAsRoot være ukjent.
+func inheritFromBaseStages(stages []DockerStageMeta, stageByAlias map[string]int) {
+	for i := range stages {
+		stage := &stages[i]
+		if stage.User != "" {
+			root := isRootUser(stage.User)
+			stage.RunsAsRoot = &root
+		} else if stage.BaseImage == "scratch" {
+			// scratch har ingen brukere, så prosessen kjører som uid 0
+			root := true
+			stage.RunsAsRoot = &root
 		}
-		if strings.Contains(line, "chmod 777") {
-			features.WorldWritable = true
+
+		if idx, ok := stageByAlias[stage.BaseStage]; ok && stage.BaseStage != "" && idx < i {
+			parent := stages[idx]
+			if stage.User == "" {
+				stage.User = parent.User
+				stage.RunsAsRoot = parent.RunsAsRoot
+			}
+			if stage.Entrypoint == "" {
+				stage.Entrypoint = parent.Entrypoint
+			}
+			if stage.Cmd == "" {
+				stage.Cmd = parent.Cmd
+			}
+			stage.HasPackageInstalls = stage.HasPackageInstalls || parent.HasPackageInstalls
+			stage.InstallsBuildTools = stage.InstallsBuildTools || parent.InstallsBuildTools
+			stage.InstallsCurlOrWget = stage.InstallsCurlOrWget || parent.InstallsCurlOrWget
 		}
-		if (strings.Contains(line, "env ") || strings.Contains(line, "arg ")) &&
-			(strings.Contains(line, "password") || strings.Contains(line, "token") || strings.Contains(line, "secret")) {
-			features.HasSecretsInEnvOrArg = true
+	}
+}
+
+func isRootUser(user string) bool {
+	name, _, _ := strings.Cut(strings.TrimSpace(user), ":")
+	return name == "root" || name == "0"
+}
+
+// SetsSecret sier om en ENV- eller ARG-instruksjon setter en variabel med navn som ligner en hemmelighet.
+func SetsSecret(inst Instruction) bool {
+	for _, name := range variableNames(inst) {
+		if SecretNamePattern.MatchString(strings.ToLower(name)) {
+			return true
 		}
 	}
+	return false
+}
 
-	features.UsesMultistage = len(stages) > 1
-	return features, stages
+// variableNames returnerer navnene en ENV- eller ARG-instruksjon setter,
+// både for "ENV A=1 B=2" og den gamle formen "ENV A 1".
+func variableNames(inst Instruction) []string {
+	if len(inst.Args) == 0 {
+		return nil
+	}
+	if inst.Cmd == "env" && !strings.Contains(inst.Args[0], "=") {
+		return inst.Args[:1]
+	}
+	var names []string
+	for _, arg := range inst.Args {
+		name, _, _ := strings.Cut(arg, "=")
+		names = append(names, name)
+	}
+	return names
 }
@@ -14,6 +14,8 @@ func TestParser(t *testing.T) {
 }
 
 var _ = Describe("ParseDockerfile", func() {
+	runsAsRoot := false
+
 	DescribeTable("Dockerfile parsing produces correct features",
 		func(content string, expected parser.DockerfileFeatures) {
 			result, _ := parser.ParseDockerfile(content)
@@ -31,6 +33,7 @@ ENTRYPOINT ["./start.sh"]`,
 			parser.DockerfileFeatures{
 				BaseImage:            "ubuntu",
 				BaseTag:              "latest",
+				Registry:             "docker.io",
 				UsesLatestTag:        true,
 				HasUserInstruction:   true,
 				HasCopySensitive:     false,
@@ -46,6 +49,7 @@ ENTRYPOINT ["./start.sh"]`,
 				HasAptGetClean:       false,
 				WorldWritable:        false,
 				HasSecretsInEnvOrArg: true,
+				FinalRunsAsRoot:      &runsAsRoot,
 			},
 		),
 
@@ -57,6 +61,7 @@ COPY --from=builder /app /app`,
 			parser.DockerfileFeatures{
 				BaseImage:            "golang",
 				BaseTag:              "1.19",
+				Registry:             "docker.io",
 				UsesLatestTag:        true,
 				HasUserInstruction:   false,
 				HasCopySensitive:     true,
@@ -72,6 +77,7 @@ COPY --from=builder /app /app`,
 				HasAptGetClean:       false,
 				WorldWritable:        false,
 				HasSecretsInEnvOrArg: false,
+				FinalStageIndex:      1,
 			},
 		),
 
@@ -79,21 +85,23 @@ COPY --from=builder /app /app`,
 			`FROM debian
 RUN apt-get update && apt-get install -y gcc make curl && apt-get clean`,
 			parser.DockerfileFeatures{
-				BaseImage:            "debian",
-				BaseTag:              "latest",
-				UsesLatestTag:        true,
-				HasPackageInstalls:   true,
-				UsesMultistage:       false,
-				HasHealthcheck:       false,
-				UsesAddInstruction:   false,
-				HasLabelMetadata:     false,
-				HasExpose:            false,
-				HasEntrypointOrCmd:   false,
-				InstallsCurlOrWget:   true,
-				InstallsBuildTools:   true,
-				HasAptGetClean:       true,
-				WorldWritable:        false,
-				HasSecretsInEnvOrArg: false,
+				BaseImage:               "debian",
+				BaseTag:                 "latest",
+				Registry:                "docker.io",
+				UsesLatestTag:           true,
+				HasPackageInstalls:      true,
+				UsesMultistage:          false,
+				HasHealthcheck:          false,
+				UsesAddInstruction:      false,
+				HasLabelMetadata:        false,
+				HasExpose:               false,
+				HasEntrypointOrCmd:      false,
+				InstallsCurlOrWget:      true,
+				InstallsBuildTools:      true,
+				HasAptGetClean:          true,
+				WorldWritable:           false,
+				HasSecretsInEnvOrArg:    false,
+				FinalInstallsBuildTools: true,
 			},
 		),
 
@@ -103,6 +111,7 @@ ARG SECRET_TOKEN`,
 			parser.DockerfileFeatures{
 				BaseImage:            "alpine",
 				BaseTag:              "latest",
+				Registry:             "docker.io",
 				UsesLatestTag:        true,
 				HasSecretsInEnvOrArg: true,
 			},
@@ -114,6 +123,19 @@ ENV DB_PASSWORD=supersecret`,
 			parser.DockerfileFeatures{
 				BaseImage:            "alpine",
 				BaseTag:              "latest",
+				Registry:             "docker.io",
+				UsesLatestTag:        true,
+				HasSecretsInEnvOrArg: true,
+			},
+		),
+
+		Entry("ARG api key triggers detection",
+			`FROM alpine
+ARG API_KEY`,
+			parser.DockerfileFeatures{
+				BaseImage:            "alpine",
+				BaseTag:              "latest",
+				Registry:             "docker.io",
 				UsesLatestTag:        true,
 				HasSecretsInEnvOrArg: true,
 			},
@@ -125,6 +147,7 @@ RUN chmod 777 /data/file`,
 			parser.DockerfileFeatures{
 				BaseImage:     "busybox",
 				BaseTag:       "latest",
+				Registry:      "docker.io",
 				UsesLatestTag: true,
 				WorldWritable: true,
 			},
@@ -138,6 +161,7 @@ HEALTHCHECK CMD curl -f http://localhost || exit 1`,
 			parser.DockerfileFeatures{
 				BaseImage:          "alpine",
 				BaseTag:            "latest",
+				Registry:           "docker.io",
 				UsesLatestTag:      true,
 				HasLabelMetadata:   true,
 				HasExpose:          true,
@@ -152,9 +176,150 @@ ADD file.tar.gz /opt/`,
 			parser.DockerfileFeatures{
 				BaseImage:          "debian",
 				BaseTag:            "latest",
+				Registry:           "docker.io",
 				UsesLatestTag:      true,
 				UsesAddInstruction: true,
 			},
 		),
 	)
 })
+
+var _ = Describe("ParseDockerfile blind spots", func() {
+	It("finner installasjoner i linjefortsettelser", func() {
+		result, _ := parser.ParseDockerfile("FROM debian:12\nRUN apt-get update && \\\n    apt-get install -y \\\n      curl\n")
+		Expect(result.HasPackageInstalls).To(BeTrue())
+		Expect(result.InstallsCurlOrWget).To(BeTrue())
+	})
+
+	It("ignorerer kommentarer som nevner curl", func() {
+		result, _ := parser.ParseDockerfile("FROM alpine:3.20\n# vi trenger ikke curl her\nRUN echo ok")
+		Expect(result.InstallsCurlOrWget).To(BeFalse())
+	})
+
+	It("leser innholdet i heredocs", func() {
+		result, _ := parser.ParseDockerfile("FROM debian:12\nRUN <<EOF\napt-get update\napt-get install -y wget\nEOF\nUSER app")
+		Expect(result.HasPackageInstalls).To(BeTrue())
+		Expect(result.InstallsCurlOrWget).To(BeTrue())
+		Expect(result.HasUserInstruction).To(BeTrue())
+	})
+
+	It("respekterer escape-direktivet", func() {
+		result, _ := parser.ParseDockerfile("# escape=`\nFROM mcr.microsoft.com/windows/servercore:ltsc2022\nRUN choco install -y make `\n    && dnf install -y git\nCOPY C:\\app\\ C:\\app\\")
+		Expect(result.BaseTag).To(Equal("ltsc2022"))
+		Expect(result.HasPackageInstalls).To(BeTrue())
+		Expect(result.InstallsBuildTools).To(BeTrue())
+	})
+})
+
+var _ = Describe("ParseDockerfileAST", func() {
+	It("gir instruksjoner med flagg, argumenter og linjenumre", func() {
+		df := parser.ParseDockerfileAST(`# syntax=docker/dockerfile:1
+FROM golang:1.22 AS build
+
+# kommentar
+RUN --mount=type=cache,target=/root/.cache \
+    go build \
+    # kommentar midt i fortsettelsen
+    -o /app .
+COPY --from=build --chown=app:app /app /app
+CMD ["/app", "--port", "8080"]`)
+
+		Expect(df.Directives).To(HaveKeyWithValue("syntax", "docker/dockerfile:1"))
+		Expect(df.Instructions).To(HaveLen(4))
+
+		run := df.Instructions[1]
+		Expect(run.Cmd).To(Equal("run"))
+		Expect(run.Flags).To(Equal([]string{"--mount=type=cache,target=/root/.cache"}))
+		Expect(run.Args).To(Equal([]string{"go", "build", "-o", "/app", "."}))
+		Expect(run.StartLine).To(Equal(5))
+		Expect(run.EndLine).To(Equal(8))
+
+		copyInst := df.Instructions[2]
+		from, ok := copyInst.Flag("from")
+		Expect(ok).To(BeTrue())
+		Expect(from).To(Equal("build"))
+		Expect(copyInst.Args).To(Equal([]string{"/app", "/app"}))
+
+		cmd := df.Instructions[3]
+		Expect(cmd.ExecForm).To(BeTrue())
+		Expect(cmd.Args).To(Equal([]string{"/app", "--port", "8080"}))
+	})
+
+	It("leser flere heredocs og <<- med innrykk", func() {
+		df := parser.ParseDockerfileAST("FROM alpine\nCOPY <<-EOT /a <<\"END\" /b\n\tfoo\n\tEOT\nbar\nEND\nUSER app")
+
+		Expect(df.Instructions).To(HaveLen(3))
+		copyInst := df.Instructions[1]
+		Expect(copyInst.Heredocs).To(Equal([]parser.Heredoc{
+			{Name: "EOT", Content: "foo"},
+			{Name: "END", Content: "bar"},
+		}))
+		Expect(copyInst.StartLine).To(Equal(2))
+		Expect(copyInst.EndLine).To(Equal(6))
+		Expect(df.Instructions[2].StartLine).To(Equal(7))
+	})
+
+	It("bruker backtick som fortsettelse med escape-direktiv", func() {
+		df := parser.ParseDockerfileAST("# escape=`\nFROM windows\nRUN dir `\n  C:\\")
+		Expect(df.EscapeToken).To(Equal(byte('`')))
+		Expect(df.Instructions[1].Args).To(Equal([]string{"dir", "C:\\"}))
+	})
+})
+
+var _ = Describe("ParseDockerfile per stage", func() {
+	It("analyserer hver stage for seg og markerer siste stage", func() {
+		features, stages := parser.ParseDockerfile(`FROM golang:1.22 AS build
+RUN apt-get install -y gcc make
+USER builder
+RUN go build -o /app .
+
+FROM gcr.io/distroless/static:nonroot AS runtime
+COPY --from=build /app /app
+EXPOSE 8080 9090/udp
+ENTRYPOINT ["/app"]`)
+
+		Expect(features.HasUserInstruction).To(BeTrue())
+		Expect(features.InstallsBuildTools).To(BeTrue())
+		Expect(features.FinalStageIndex).To(Equal(1))
+		// Uten USER er det base image som bestemmer, og distroless :nonroot kjører ikke som root
+		Expect(features.FinalRunsAsRoot).To(BeNil())
+		Expect(features.FinalInstallsBuildTools).To(BeFalse())
+
+		Expect(stages).To(HaveLen(2))
+		Expect(stages[0].Alias).To(Equal("build"))
+		Expect(stages[0].User).To(Equal("builder"))
+		Expect(stages[0].RunsAsRoot).To(HaveValue(BeFalse()))
+		Expect(stages[0].InstallsBuildTools).To(BeTrue())
+		Expect(stages[0].IsFinal).To(BeFalse())
+
+		Expect(stages[1].CopyFrom).To(Equal([]string{"build"}))
+		Expect(stages[1].ExposedPorts).To(Equal([]string{"8080", "9090/udp"}))
+		Expect(stages[1].Entrypoint).To(Equal("/app"))
+		Expect(stages[1].IsFinal).To(BeTrue())
+	})
+
+	It("arver bruker og installasjoner fra stagen den bygger på", func() {
+		features, stages := parser.ParseDockerfile(`FROM debian:12 AS base
+RUN apt-get install -y build-essential
+USER 1000:1000
+
+FROM base
+CMD ["./run"]`)
+
+		Expect(stages[1].BaseStage).To(Equal("base"))
+		Expect(stages[1].User).To(Equal("1000:1000"))
+		Expect(stages[1].Cmd).To(Equal("./run"))
+		Expect(features.FinalRunsAsRoot).To(HaveValue(BeFalse()))
+		Expect(features.FinalInstallsBuildTools).To(BeTrue())
+	})
+
+	It("regner USER root som root", func() {
+		features, _ := parser.ParseDockerfile("FROM alpine:3.20\nUSER app\nUSER root:root")
+		Expect(features.FinalRunsAsRoot).To(HaveValue(BeTrue()))
+	})
+
+	It("regner scratch uten USER som root", func() {
+		features, _ := parser.ParseDockerfile("FROM golang:1.22 AS build\nFROM scratch\nCOPY --from=build /app /app")
+		Expect(features.FinalRunsAsRoot).To(HaveValue(BeTrue()))
+	})
+})
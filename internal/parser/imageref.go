@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+const DockerHubRegistry = "docker.io"
+
+// ImageRef er en OCI-bildereferanse delt opp i sine deler, f.eks.
+// "ghcr.io/navikt/app:1.2@sha256:..." eller "ubuntu" (docker.io/library/ubuntu).
+type ImageRef struct {
+	Name       string // navnet slik det står i FROM, uten tag og digest
+	Registry   string // normalisert registry, f.eks. docker.io eller registry.local:5000
+	Namespace  string // alt mellom registry og repository, "library" for offisielle Docker Hub-images
+	Repository string
+	Tag        string
+	Digest     string // f.eks. sha256:abc...
+}
+
+// Docker Hub har flere vertsnavn som alle betyr det samme
+var dockerHubAliases = map[string]bool{
+	"docker.io":            true,
+	"index.docker.io":      true,
+	"registry-1.docker.io": true,
+}
+
+// ParseImageRef deler en bildereferanse i registry, namespace, repository, tag og digest.
+// Referanser uten registry normaliseres til Docker Hub, og enkeltnavn får namespace "library".
+func ParseImageRef(ref string) (ImageRef, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ImageRef{}, fmt.Errorf("tom bildereferanse")
+	}
+
+	var result ImageRef
+	name := ref
+	if before, digest, ok := strings.Cut(ref, "@"); ok {
+		if !strings.Contains(digest, ":") {
+			return ImageRef{}, fmt.Errorf("ugyldig digest i %q", ref)
+		}
+		name = before
+		result.Digest = digest
+	}
+
+	// Tag er det som står etter siste ":" så lenge det kommer etter siste "/",
+	// ellers er ":" en port i registry-navnet
+	if idx := strings.LastIndex(name, ":"); idx > strings.LastIndex(name, "/") {
+		result.Tag = name[idx+1:]
+		name = name[:idx]
+		if result.Tag == "" {
+			return ImageRef{}, fmt.Errorf("tom tag i %q", ref)
+		}
+	}
+
+	name = strings.ToLower(name)
+	result.Name = name
+
+	components := strings.Split(name, "/")
+	for _, c := range components {
+		if c == "" {
+			return ImageRef{}, fmt.Errorf("ugyldig bildenavn %q", ref)
+		}
+	}
+
+	result.Registry = DockerHubRegistry
+	if len(components) > 1 && isRegistryHost(components[0]) {
+		if !dockerHubAliases[components[0]] {
+			result.Registry = components[0]
+		}
+		components = components[1:]
+	}
+
+	result.Repository = components[len(components)-1]
+	result.Namespace = strings.Join(components[:len(components)-1], "/")
+	if result.Registry == DockerHubRegistry && result.Namespace == "" {
+		result.Namespace = "library"
+	}
+
+	return result, nil
+}
+
+// Første komponent er et registry hvis den ser ut som et vertsnavn
+func isRegistryHost(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
+
+// IsDigestPinned er true når referansen peker på en bestemt digest og ikke bare en flyttbar tag.
+func (r ImageRef) IsDigestPinned() bool {
+	return r.Digest != ""
+}
+
+// FullName gir det kanoniske navnet, f.eks. docker.io/library/ubuntu.
+func (r ImageRef) FullName() string {
+	parts := []string{r.Registry}
+	if r.Namespace != "" {
+		parts = append(parts, r.Namespace)
+	}
+	return strings.Join(append(parts, r.Repository), "/")
+}
@@ -0,0 +1,60 @@
+package parser_test
+
+import (
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseImageRef", func() {
+	DescribeTable("deler referansen i registry, namespace, repository, tag og digest",
+		func(ref string, expected parser.ImageRef) {
+			result, err := parser.ParseImageRef(ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(expected))
+		},
+
+		Entry("offisielt Docker Hub-image", "ubuntu:22.04", parser.ImageRef{
+			Name: "ubuntu", Registry: "docker.io", Namespace: "library", Repository: "ubuntu", Tag: "22.04",
+		}),
+		Entry("Docker Hub med namespace", "bitnami/redis", parser.ImageRef{
+			Name: "bitnami/redis", Registry: "docker.io", Namespace: "bitnami", Repository: "redis",
+		}),
+		Entry("index.docker.io normaliseres", "index.docker.io/library/alpine:3.20", parser.ImageRef{
+			Name: "index.docker.io/library/alpine", Registry: "docker.io", Namespace: "library", Repository: "alpine", Tag: "3.20",
+		}),
+		Entry("registry med port og uten tag", "registry.local:5000/app", parser.ImageRef{
+			Name: "registry.local:5000/app", Registry: "registry.local:5000", Repository: "app",
+		}),
+		Entry("digest uten tag", "gcr.io/distroless/static@sha256:abc123", parser.ImageRef{
+			Name: "gcr.io/distroless/static", Registry: "gcr.io", Namespace: "distroless", Repository: "static", Digest: "sha256:abc123",
+		}),
+		Entry("tag og digest med dypt namespace", "europe-north1-docker.pkg.dev/nais-io/nais/images/app:v1@sha256:def", parser.ImageRef{
+			Name: "europe-north1-docker.pkg.dev/nais-io/nais/images/app", Registry: "europe-north1-docker.pkg.dev",
+			Namespace: "nais-io/nais/images", Repository: "app", Tag: "v1", Digest: "sha256:def",
+		}),
+		Entry("localhost er et registry", "localhost/app:dev", parser.ImageRef{
+			Name: "localhost/app", Registry: "localhost", Repository: "app", Tag: "dev",
+		}),
+	)
+
+	It("avviser ugyldige referanser", func() {
+		for _, ref := range []string{"", "app:", "app@abc", "org//app"} {
+			_, err := parser.ParseImageRef(ref)
+			Expect(err).To(HaveOccurred(), ref)
+		}
+	})
+
+	It("gir pinning og registry videre til stages", func() {
+		features, stages := parser.ParseDockerfile(`FROM registry.local:5000/app AS base
+FROM gcr.io/distroless/static@sha256:abc123
+COPY --from=base /app /app`)
+
+		Expect(stages).To(Equal([]parser.DockerStageMeta{
+			{StageIndex: 0, Line: 1, Alias: "base", BaseImage: "registry.local:5000/app", BaseTag: "latest", Registry: "registry.local:5000"},
+			{StageIndex: 1, Line: 2, BaseImage: "gcr.io/distroless/static", Registry: "gcr.io", IsDigestPinned: true, Digest: "sha256:abc123", CopyFrom: []string{"base"}, IsFinal: true},
+		}))
+		Expect(features.Registry).To(Equal("registry.local:5000"))
+		Expect(features.UsesLatestTag).To(BeTrue())
+	})
+})
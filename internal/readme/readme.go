@@ -0,0 +1,316 @@
+package readme
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+const (
+	LanguageNorwegian = "no"
+	LanguageEnglish   = "en"
+	LanguageMixed     = "mixed"
+	LanguageUnknown   = "unknown"
+)
+
+// Minste antall stoppord før vi gjetter språk, og hvor mye ett språk må dominere
+const (
+	minStopwords   = 5
+	languageMargin = 1.5
+)
+
+var (
+	fencePattern      = regexp.MustCompile("^\\s*(```|~~~)")
+	atxHeading        = regexp.MustCompile(`^\s{0,3}#{1,6}\s+(.+?)\s*#*\s*$`)
+	setextUnderline   = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	htmlHeading       = regexp.MustCompile(`(?is)<h[1-6][^>]*>(.*?)</h[1-6]>`)
+	htmlTag           = regexp.MustCompile(`<[^>]+>`)
+	linkedImage       = regexp.MustCompile(`\[!\[[^\]]*\]\(([^)\s]+)[^)]*\)\]\(([^)\s]+)[^)]*\)`)
+	imagePattern      = regexp.MustCompile(`!\[[^\]]*\]\(([^)\s]+)[^)]*\)`)
+	htmlImage         = regexp.MustCompile(`(?i)<img[^>]+src=["']([^"']+)["']`)
+	linkPattern       = regexp.MustCompile(`\[[^\]]*\]\(([^)\s]+)[^)]*\)`)
+	referenceLink     = regexp.MustCompile(`(?m)^\s{0,3}\[[^\]]+\]:\s*(\S+)`)
+	autoLink          = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	htmlLink          = regexp.MustCompile(`(?i)<a[^>]+href=["']([^"']+)["']`)
+	inlineCode        = regexp.MustCompile("`[^`\n]*`")
+	wordPattern       = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}'’-]*`)
+	placeholderMarker = regexp.MustCompile(`(?i)<(your|app|project|repo|repository|team|service)[-_ ]?(name|navn)>|\{\{\s*[\w.-]+\s*\}\}|\[(project|app|repo) name\]`)
+	todoMarker        = regexp.MustCompile(`\b(TODO|TBD|FIXME)\b`)
+)
+
+// Nøkkelord i overskrifter, norsk og engelsk, i små bokstaver
+var headingKeywords = map[string][]string{
+	"installation": {"install", "oppsett", "setup", "set up", "getting started", "kom i gang", "komme i gang", "bygg", "build", "lokal utvikling", "local development"},
+	"usage":        {"usage", "bruk", "how to use", "example", "eksempel", "kjør", "running", "run ", "api"},
+	"contact":      {"contact", "kontakt", "henvendelse", "spørsmål", "questions", "support", "slack", "hjelp", "help"},
+	"ownership":    {"owner", "eier", "maintainer", "vedlikehold", "team", "ansvarlig", "codeowners", "forvalt"},
+}
+
+var (
+	norwegianStopwords = wordSet("og i på er det som en et til med av ikke har kan vi du skal eller fra den dette å hvis når også må blir være ved hvordan dere")
+	englishStopwords   = wordSet("the and is to of in this that with you are be it can on if how use your we from or not by an will")
+)
+
+// Kjente vertsnavn for badges; bilder herfra telles som badges også uten lenke rundt
+var badgeHosts = map[string]string{
+	"img.shields.io":             "shields.io",
+	"shields.io":                 "shields.io",
+	"codecov.io":                 "codecov",
+	"sonarcloud.io":              "sonarcloud",
+	"app.codacy.com":             "codacy",
+	"api.codeclimate.com":        "codeclimate",
+	"snyk.io":                    "snyk",
+	"badge.fury.io":              "npm",
+	"goreportcard.com":           "goreportcard",
+	"pkg.go.dev":                 "pkg.go.dev",
+	"api.securityscorecards.dev": "scorecard",
+}
+
+// Tekst fra generatorer og maler som ofte blir stående når README aldri er skrevet
+var templatePhrases = []struct {
+	id     string
+	phrase string
+}{
+	{"create-react-app", "this project was bootstrapped with [create react app]"},
+	{"nextjs", "this is a [next.js](https://nextjs.org) project bootstrapped with"},
+	{"vite", "this template provides a minimal setup to get react working in vite"},
+	{"angular-cli", "this project was generated with [angular cli]"},
+	{"spring-initializr", "the following guides illustrate how to use some features concretely"},
+	{"gitlab-template", "suggestions for a good readme"},
+	{"lorem-ipsum", "lorem ipsum"},
+	{"erstatt-tekst", "erstatt denne teksten"},
+	{"describe-project", "add your project description"},
+}
+
+// Analyze regner ut struktur og kvalitet for README-en. fullName brukes til å kjenne igjen
+// absolutte lenker til samme repo som interne. Returnerer nil uten README.
+func Analyze(content, fullName string) *models.ReadmeMetrics {
+	if strings.TrimSpace(content) == "" {
+		return nil
+	}
+
+	m := &models.ReadmeMetrics{Length: len([]rune(content))}
+	prose, codeBlocks := stripCode(content)
+	m.CodeBlocks = codeBlocks
+	m.WordCount = len(wordPattern.FindAllString(prose, -1))
+
+	m.Headings = headings(prose)
+	for _, h := range m.Headings {
+		lower := strings.ToLower(h)
+		m.HasInstallation = m.HasInstallation || containsAny(lower, headingKeywords["installation"])
+		m.HasUsage = m.HasUsage || containsAny(lower, headingKeywords["usage"])
+		m.HasContact = m.HasContact || containsAny(lower, headingKeywords["contact"])
+		m.HasOwnership = m.HasOwnership || containsAny(lower, headingKeywords["ownership"])
+	}
+
+	var links []string
+	rest := linkedImage.ReplaceAllStringFunc(prose, func(match string) string {
+		sub := linkedImage.FindStringSubmatch(match)
+		m.Badges = appendUnique(m.Badges, badgeKind(sub[1]))
+		links = append(links, sub[2])
+		return " "
+	})
+	rest = imagePattern.ReplaceAllStringFunc(rest, func(match string) string {
+		if kind, ok := knownBadge(imagePattern.FindStringSubmatch(match)[1]); ok {
+			m.Badges = appendUnique(m.Badges, kind)
+		}
+		return " "
+	})
+	for _, sub := range htmlImage.FindAllStringSubmatch(rest, -1) {
+		if kind, ok := knownBadge(sub[1]); ok {
+			m.Badges = appendUnique(m.Badges, kind)
+		}
+	}
+	for _, pattern := range []*regexp.Regexp{linkPattern, referenceLink, autoLink, htmlLink} {
+		for _, sub := range pattern.FindAllStringSubmatch(rest, -1) {
+			links = append(links, sub[1])
+		}
+	}
+	for _, link := range links {
+		if isInternal(link, fullName) {
+			m.InternalLinks++
+		} else {
+			m.ExternalLinks++
+		}
+	}
+
+	m.Language = detectLanguage(prose)
+	m.TemplateMarkers = templateMarkers(content, prose, fullName)
+	return m
+}
+
+// stripCode fjerner kodeblokker og inline-kode, så overskrifter, lenker og ord i kode ikke telles.
+func stripCode(content string) (string, int) {
+	var b strings.Builder
+	blocks := 0
+	fence := ""
+	for _, line := range strings.Split(content, "\n") {
+		if m := fencePattern.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+				blocks++
+				continue
+			case fence == m[1]:
+				fence = ""
+				continue
+			}
+		}
+		if fence != "" {
+			continue
+		}
+		b.WriteString(inlineCode.ReplaceAllString(line, " "))
+		b.WriteByte('\n')
+	}
+	return b.String(), blocks
+}
+
+func headings(prose string) []string {
+	var result []string
+	lines := strings.Split(prose, "\n")
+	for i, line := range lines {
+		if m := atxHeading.FindStringSubmatch(line); m != nil {
+			result = append(result, cleanHeading(m[1]))
+			continue
+		}
+		// Setext: tekst understreket med === eller ---, men ikke en linje med --- etter en tom linje
+		if i > 0 && setextUnderline.MatchString(line) && strings.TrimSpace(lines[i-1]) != "" &&
+			atxHeading.FindStringSubmatch(lines[i-1]) == nil && !strings.HasPrefix(strings.TrimSpace(lines[i-1]), "-") {
+			result = append(result, cleanHeading(lines[i-1]))
+		}
+	}
+	for _, m := range htmlHeading.FindAllStringSubmatch(prose, -1) {
+		if h := cleanHeading(htmlTag.ReplaceAllString(m[1], "")); h != "" {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+func cleanHeading(h string) string {
+	return strings.TrimSpace(strings.Trim(strings.TrimSpace(h), "*_`"))
+}
+
+// detectLanguage teller vanlige norske og engelske småord og velger språket som dominerer.
+func detectLanguage(prose string) string {
+	no, en := 0, 0
+	for _, w := range wordPattern.FindAllString(strings.ToLower(prose), -1) {
+		if norwegianStopwords[w] {
+			no++
+		}
+		if englishStopwords[w] {
+			en++
+		}
+	}
+	switch {
+	case no+en < minStopwords:
+		return LanguageUnknown
+	case float64(no) >= languageMargin*float64(en):
+		return LanguageNorwegian
+	case float64(en) >= languageMargin*float64(no):
+		return LanguageEnglish
+	}
+	return LanguageMixed
+}
+
+// templateMarkers finner maltekst som aldri ble fylt ut: kjente generatorer, plassholdere, TODO-er,
+// og README-en GitHub lager ved opprettelse (bare tittel og ev. beskrivelse).
+func templateMarkers(content, prose, fullName string) []string {
+	var result []string
+	lower := strings.ToLower(content)
+	for _, t := range templatePhrases {
+		if strings.Contains(lower, t.phrase) {
+			result = append(result, t.id)
+		}
+	}
+	if placeholderMarker.MatchString(prose) {
+		result = append(result, "placeholder")
+	}
+	if todoMarker.MatchString(prose) {
+		result = append(result, "todo")
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	repoName := fullName[strings.LastIndex(fullName, "/")+1:]
+	if len(lines) <= 2 && strings.EqualFold(strings.TrimSpace(strings.TrimLeft(lines[0], "#")), repoName) {
+		result = append(result, "github-init")
+	}
+	return result
+}
+
+// knownBadge kjenner igjen badge-bilder på vertsnavn eller GitHub Actions sin badge.svg
+func knownBadge(src string) (string, bool) {
+	u, err := url.Parse(src)
+	if err != nil {
+		return "", false
+	}
+	if strings.HasSuffix(u.Path, "/badge.svg") && strings.Contains(u.Path, "/actions/workflows/") {
+		return "github-actions", true
+	}
+	if kind, ok := badgeHosts[strings.ToLower(u.Hostname())]; ok {
+		return kind, true
+	}
+	if strings.Contains(strings.ToLower(u.Path), "badge") {
+		return strings.ToLower(u.Hostname()), u.Hostname() != ""
+	}
+	return "", false
+}
+
+// badgeKind gir typen for et bilde inne i en lenke; ukjente verter gir vertsnavnet
+func badgeKind(src string) string {
+	if kind, ok := knownBadge(src); ok {
+		return kind
+	}
+	if u, err := url.Parse(src); err == nil && u.Hostname() != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	return "local"
+}
+
+// isInternal er true for relative lenker, ankere og absolutte lenker til samme repo på GitHub
+func isInternal(link, fullName string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return true
+	}
+	repoPath := strings.ToLower("/" + fullName)
+	p := strings.ToLower(u.Path)
+	return strings.EqualFold(u.Hostname(), "github.com") && fullName != "" &&
+		(p == repoPath || strings.HasPrefix(p, repoPath+"/"))
+}
+
+func containsAny(s string, keywords []string) bool {
+	for _, k := range keywords {
+		if strings.Contains(s, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
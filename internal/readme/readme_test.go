@@ -0,0 +1,83 @@
+package readme_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/readme"
+)
+
+func TestReadme(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "README-kvalitet")
+}
+
+var _ = Describe("Analyze", func() {
+	It("finner overskrifter, badges, lenker, kodeblokker og norsk språk", func() {
+		content := `# minside-api
+
+[![Build](https://github.com/navikt/minside-api/actions/workflows/build.yml/badge.svg)](https://github.com/navikt/minside-api/actions)
+![Coverage](https://codecov.io/gh/navikt/minside-api/branch/main/graph/badge.svg)
+
+API-et som brukes av Min side. Det henter data fra flere kilder og er ment for interne konsumenter.
+
+## Kom i gang
+
+Kjør appen lokalt med:
+
+` + "```bash\n./gradlew run\n# Ikke en overskrift\n```" + `
+
+Se også [arkitektur](docs/arkitektur.md), [kildekoden](https://github.com/navikt/minside-api/tree/main/src) og [NAIS](https://doc.nais.io).
+
+## Bruk
+
+Kall ` + "`GET /api`" + ` med token.
+
+Henvendelser
+------------
+
+Spørsmål kan stilles som issues, eller på Slack i <https://nav-it.slack.com/archives/minside>.
+
+<h2>Team</h2>
+`
+		m := readme.Analyze(content, "navikt/minside-api")
+		Expect(m).NotTo(BeNil())
+		Expect(m.Length).To(Equal(len([]rune(content))))
+		Expect(m.Headings).To(Equal([]string{"minside-api", "Kom i gang", "Bruk", "Henvendelser", "Team"}))
+		Expect(m.HasInstallation).To(BeTrue())
+		Expect(m.HasUsage).To(BeTrue())
+		Expect(m.HasContact).To(BeTrue())
+		Expect(m.HasOwnership).To(BeTrue())
+		Expect(m.Badges).To(Equal([]string{"github-actions", "codecov"}))
+		Expect(m.InternalLinks).To(Equal(3))
+		Expect(m.ExternalLinks).To(Equal(2))
+		Expect(m.CodeBlocks).To(Equal(1))
+		Expect(m.Language).To(Equal(readme.LanguageNorwegian))
+		Expect(m.TemplateMarkers).To(BeEmpty())
+	})
+
+	It("kjenner igjen engelsk og maltekst fra generatorer", func() {
+		m := readme.Analyze(`This project was bootstrapped with [Create React App](https://github.com/facebook/create-react-app).
+
+## Available Scripts
+
+In the project directory, you can run the app in development mode. TODO: describe the <app-name> here.
+`, "navikt/frontend")
+		Expect(m.Language).To(Equal(readme.LanguageEnglish))
+		Expect(m.TemplateMarkers).To(Equal([]string{"create-react-app", "placeholder", "todo"}))
+		Expect(m.HasInstallation).To(BeFalse())
+	})
+
+	It("flagger README-en GitHub lager ved opprettelse", func() {
+		m := readme.Analyze("# frontend\nFrontend for ting\n", "navikt/frontend")
+		Expect(m.TemplateMarkers).To(Equal([]string{"github-init"}))
+		Expect(m.WordCount).To(Equal(4))
+		Expect(m.Language).To(Equal(readme.LanguageUnknown))
+	})
+
+	It("gir nil uten README", func() {
+		Expect(readme.Analyze("  \n", "navikt/x")).To(BeNil())
+	})
+})
@@ -0,0 +1,455 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+)
+
+// Manifest-typer vi ber om. Index/manifest list først, så vi får digesten
+// som tilsvarer taggen og ikke én bestemt plattform.
+var manifestAccept = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+const maxBodyBytes = 4 << 20
+
+// Credential er innlogging for ett registry. Username/Password brukes til basic auth
+// og mot token-tjenesten ved bearer auth. Token alene sendes direkte som bearer.
+type Credential struct {
+	Username string
+	Password string
+	Token    string
+}
+
+// ImageInfo er det registryet vet om en referanse akkurat nå.
+type ImageInfo struct {
+	Digest  string
+	Created *time.Time
+}
+
+type Client struct {
+	HTTP        *http.Client
+	Credentials map[string]Credential // per registry-vert, f.eks. "ghcr.io"
+	PlainHTTP   map[string]bool       // verter uten TLS, f.eks. en lokal registry:2
+
+	mu     sync.Mutex
+	cache  map[string]*cacheEntry
+	tokens map[string]string // bearer-token per registry og repository
+}
+
+// cacheEntry holder svaret for én referanse. mu sørger for at samtidige oppslag
+// av samme referanse venter på hverandre i stedet for å spørre registryet flere ganger.
+type cacheEntry struct {
+	mu   sync.Mutex
+	done bool
+	info *ImageInfo
+	err  error
+}
+
+// statusError er et svar fra registryet utenfor 2xx.
+type statusError struct {
+	StatusCode int
+	URL        string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("registry svarte %d for %s", e.StatusCode, e.URL)
+}
+
+func NewClient(credentials map[string]Credential, plainHTTP []string) *Client {
+	plain := map[string]bool{}
+	for _, host := range plainHTTP {
+		plain[host] = true
+	}
+	return &Client{
+		HTTP:        &http.Client{Timeout: 30 * time.Second},
+		Credentials: credentials,
+		PlainHTTP:   plain,
+	}
+}
+
+// Resolve slår opp gjeldende digest og opprettelsesdato for en referanse.
+// Vellykkede oppslag og 404 caches per referanse så lenge klienten lever. Andre feil,
+// som timeout, 429 og 5xx, caches ikke, så neste oppslag prøver på nytt.
+func (c *Client) Resolve(ctx context.Context, ref parser.ImageRef) (*ImageInfo, error) {
+	// Med tag slår vi opp taggen, så en pinnet digest i tillegg gir samme svar
+	key := ref.FullName() + ":" + ref.Tag
+	if ref.Tag == "" {
+		key = ref.FullName() + "@" + ref.Digest
+	}
+
+	c.mu.Lock()
+	if c.cache == nil {
+		c.cache = map[string]*cacheEntry{}
+	}
+	entry, ok := c.cache[key]
+	if !ok {
+		entry = &cacheEntry{}
+		c.cache[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.done {
+		return entry.info, entry.err
+	}
+
+	info, err := c.resolve(ctx, ref)
+	if err == nil || isNotFound(err) {
+		entry.done = true
+		entry.info, entry.err = info, err
+	}
+	return info, err
+}
+
+func isNotFound(err error) bool {
+	var status *statusError
+	return errors.As(err, &status) && status.StatusCode == http.StatusNotFound
+}
+
+func (c *Client) resolve(ctx context.Context, ref parser.ImageRef) (*ImageInfo, error) {
+	// Tag gir gjeldende digest. Uten tag kan vi bare slå opp den pinnede digesten.
+	reference := ref.Tag
+	if reference == "" && ref.Digest != "" {
+		reference = ref.Digest
+	} else if reference == "" {
+		reference = "latest"
+	}
+
+	body, digest, mediaType, err := c.getManifest(ctx, ref, reference)
+	if err != nil {
+		return nil, err
+	}
+	info := &ImageInfo{Digest: digest}
+
+	// For å finne opprettelsesdato må vi fra index til plattform-manifest til config-blob
+	manifest := body
+	if isIndex(mediaType, body) {
+		platformDigest, err := pickPlatform(body)
+		if err != nil {
+			return info, nil
+		}
+		if manifest, _, _, err = c.getManifest(ctx, ref, platformDigest); err != nil {
+			slog.Debug("Fant ikke plattform-manifest", "image", ref.FullName(), "error", err)
+			return info, nil
+		}
+	}
+
+	var m struct {
+		Config struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(manifest, &m); err != nil || m.Config.Digest == "" {
+		return info, nil
+	}
+
+	blob, _, err := c.get(ctx, ref, "blobs/"+m.Config.Digest, "")
+	if err != nil {
+		slog.Debug("Fant ikke config-blob", "image", ref.FullName(), "error", err)
+		return info, nil
+	}
+	var config struct {
+		Created *time.Time `json:"created"`
+	}
+	if err := json.Unmarshal(blob, &config); err == nil {
+		info.Created = config.Created
+	}
+	return info, nil
+}
+
+func (c *Client) getManifest(ctx context.Context, ref parser.ImageRef, reference string) ([]byte, string, string, error) {
+	body, header, err := c.get(ctx, ref, "manifests/"+reference, manifestAccept)
+	if err != nil {
+		return nil, "", "", err
+	}
+	digest := header.Get("Docker-Content-Digest")
+	if digest == "" {
+		sum := sha256.Sum256(body)
+		digest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return body, digest, header.Get("Content-Type"), nil
+}
+
+// get henter en ressurs under /v2/<repo>/ og håndterer 401 med basic eller bearer auth.
+func (c *Client) get(ctx context.Context, ref parser.ImageRef, resource, accept string) ([]byte, http.Header, error) {
+	host := apiHost(ref.Registry)
+	repo := repositoryPath(ref)
+	scheme := "https"
+	if c.PlainHTTP[ref.Registry] {
+		scheme = "http"
+	}
+	target := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, host, repo, resource)
+
+	tokenKey := ref.Registry + "/" + repo
+	resp, err := c.do(ctx, target, accept, c.authHeader(ref.Registry, tokenKey))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		closeBody(resp)
+
+		auth, err := c.answerChallenge(ctx, ref.Registry, tokenKey, challenge)
+		if err != nil {
+			return nil, nil, err
+		}
+		if resp, err = c.do(ctx, target, accept, auth); err != nil {
+			return nil, nil, err
+		}
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, &statusError{StatusCode: resp.StatusCode, URL: target}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, resp.Header, nil
+}
+
+func (c *Client) do(ctx context.Context, target, accept, auth string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	return c.HTTP.Do(req)
+}
+
+// authHeader gir header vi kan sende uten å vente på en 401: et bearer-token vi
+// allerede har, eller en statisk token fra konfigurasjonen.
+func (c *Client) authHeader(registry, tokenKey string) string {
+	c.mu.Lock()
+	token := c.tokens[tokenKey]
+	c.mu.Unlock()
+	if token != "" {
+		return "Bearer " + token
+	}
+	if cred := c.Credentials[registry]; cred.Token != "" {
+		return "Bearer " + cred.Token
+	}
+	return ""
+}
+
+func (c *Client) answerChallenge(ctx context.Context, registry, tokenKey, challenge string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	cred := c.Credentials[registry]
+
+	switch scheme {
+	case "basic":
+		if cred.Username == "" {
+			return "", errors.New("registry krever basic auth, men mangler brukernavn")
+		}
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(cred.Username, cred.Password)
+		return req.Header.Get("Authorization"), nil
+
+	case "bearer":
+		token, err := c.fetchToken(ctx, params, cred)
+		if err != nil {
+			return "", err
+		}
+		c.mu.Lock()
+		if c.tokens == nil {
+			c.tokens = map[string]string{}
+		}
+		c.tokens[tokenKey] = token
+		c.mu.Unlock()
+		return "Bearer " + token, nil
+	}
+	return "", fmt.Errorf("ukjent auth-metode fra registry: %q", challenge)
+}
+
+// fetchToken henter et bearer-token fra token-tjenesten i challengen,
+// anonymt eller med brukernavn og passord.
+func (c *Client) fetchToken(ctx context.Context, params map[string]string, cred Credential) (string, error) {
+	realm := params["realm"]
+	if realm == "" {
+		return "", errors.New("bearer-challenge mangler realm")
+	}
+	u, err := url.Parse(realm)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	if s := params["service"]; s != "" {
+		q.Set("service", s)
+	}
+	if s := params["scope"]; s != "" {
+		q.Set("scope", s)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if cred.Username != "" {
+		req.SetBasicAuth(cred.Username, cred.Password)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer closeBody(resp)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token-tjenesten svarte %d", resp.StatusCode)
+	}
+
+	var tok struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxBodyBytes)).Decode(&tok); err != nil {
+		return "", err
+	}
+	if tok.Token != "" {
+		return tok.Token, nil
+	}
+	if tok.AccessToken != "" {
+		return tok.AccessToken, nil
+	}
+	return "", errors.New("token-tjenesten returnerte ingen token")
+}
+
+// parseChallenge leser `Bearer realm="...",service="...",scope="..."`.
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(rest, "=")
+		key = strings.TrimSpace(strings.TrimLeft(key, ", "))
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(key)] = value
+	}
+	return strings.ToLower(scheme), params
+}
+
+func isIndex(mediaType string, body []byte) bool {
+	if strings.Contains(mediaType, "index") || strings.Contains(mediaType, "manifest.list") {
+		return true
+	}
+	var probe struct {
+		Manifests []json.RawMessage `json:"manifests"`
+	}
+	return json.Unmarshal(body, &probe) == nil && len(probe.Manifests) > 0
+}
+
+// pickPlatform velger linux/amd64 fra en index, eller første manifest hvis den mangler.
+func pickPlatform(body []byte) (string, error) {
+	var index struct {
+		Manifests []struct {
+			Digest   string `json:"digest"`
+			Platform struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	if err := json.Unmarshal(body, &index); err != nil {
+		return "", err
+	}
+	if len(index.Manifests) == 0 {
+		return "", errors.New("tom index")
+	}
+	for _, m := range index.Manifests {
+		if m.Platform.OS == "linux" && m.Platform.Architecture == "amd64" {
+			return m.Digest, nil
+		}
+	}
+	return index.Manifests[0].Digest, nil
+}
+
+// Docker Hub sitt API ligger på en annen vert enn navnet brukt i referanser
+func apiHost(registry string) string {
+	if registry == parser.DockerHubRegistry {
+		return "registry-1.docker.io"
+	}
+	return registry
+}
+
+func repositoryPath(ref parser.ImageRef) string {
+	if ref.Namespace == "" {
+		return ref.Repository
+	}
+	return ref.Namespace + "/" + ref.Repository
+}
+
+func closeBody(resp *http.Response) {
+	if err := resp.Body.Close(); err != nil {
+		slog.Warn("Klarte ikke å lukke body fra registry", "error", err)
+	}
+}
+
+// ParseCredentials leser `vert=bruker:passord` eller `vert=token`, kommaseparert.
+// Passord eller token som starter med @ leses fra filen det peker på, f.eks.
+// `_json_key:@/secrets/gcp.json`, siden en JSON-nøkkel selv inneholder komma.
+// Ugyldige elementer logges og hoppes over.
+func ParseCredentials(value string) map[string]Credential {
+	creds := map[string]Credential{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		host, secret, ok := strings.Cut(item, "=")
+		if !ok || host == "" || secret == "" {
+			slog.Warn("Ugyldig registry-innlogging i konfigurasjonen", "element", host)
+			continue
+		}
+		if user, password, isBasic := strings.Cut(secret, ":"); isBasic {
+			if password, ok = readSecret(host, password); ok {
+				creds[host] = Credential{Username: user, Password: password}
+			}
+		} else if token, ok := readSecret(host, secret); ok {
+			creds[host] = Credential{Token: token}
+		}
+	}
+	return creds
+}
+
+// readSecret gir verdien som den er, eller innholdet i filen når den starter med @.
+func readSecret(host, value string) (string, bool) {
+	path, fromFile := strings.CutPrefix(value, "@")
+	if !fromFile {
+		return value, true
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		slog.Warn("Klarte ikke lese registry-innlogging fra fil", "registry", host, "error", err)
+		return "", false
+	}
+	return strings.TrimSpace(string(content)), true
+}
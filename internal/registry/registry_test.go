@@ -0,0 +1,243 @@
+package registry_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+	"github.com/jonmartinstorm/reposnusern/internal/registry"
+)
+
+func TestRegistry(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "OCI registry-klient")
+}
+
+// fakeRegistry oppfører seg som en registry:2 med ett image, team/app:1.0,
+// publisert som index med to plattformer.
+func fakeRegistry(auth string, requests *int32) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer GinkgoRecover()
+		atomic.AddInt32(requests, 1)
+
+		if r.URL.Path == "/token" {
+			user, pass, ok := r.BasicAuth()
+			if auth == "bearer-basic" && (!ok || user != "ci" || pass != "hemmelig") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			Expect(r.URL.Query().Get("scope")).To(Equal("repository:team/app:pull"))
+			fmt.Fprint(w, `{"token": "abc"}`)
+			return
+		}
+
+		switch auth {
+		case "basic":
+			if user, pass, ok := r.BasicAuth(); !ok || user != "ci" || pass != "hemmelig" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		case "bearer", "bearer-basic":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.Header().Set("WWW-Authenticate",
+					fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:team/app:pull"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		switch r.URL.Path {
+		case "/v2/team/app/manifests/1.0":
+			Expect(r.Header.Get("Accept")).To(ContainSubstring("application/vnd.oci.image.index.v1+json"))
+			w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+			w.Header().Set("Docker-Content-Digest", "sha256:index")
+			fmt.Fprint(w, `{"manifests": [
+				{"digest": "sha256:arm", "platform": {"os": "linux", "architecture": "arm64"}},
+				{"digest": "sha256:amd", "platform": {"os": "linux", "architecture": "amd64"}}
+			]}`)
+		case "/v2/team/app/manifests/sha256:amd":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			fmt.Fprint(w, `{"config": {"digest": "sha256:cfg"}}`)
+		case "/v2/team/app/blobs/sha256:cfg":
+			fmt.Fprint(w, `{"created": "2024-05-01T10:00:00Z"}`)
+		case "/v2/team/travel/manifests/1.0":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/v2/team/begrenset/manifests/1.0":
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func refFor(server *httptest.Server, image string) parser.ImageRef {
+	ref, err := parser.ParseImageRef(strings.TrimPrefix(server.URL, "http://") + "/" + image)
+	Expect(err).NotTo(HaveOccurred())
+	return ref
+}
+
+var _ = Describe("Client.Resolve", func() {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	DescribeTable("henter digest og opprettelsesdato",
+		func(auth string, creds func(host string) map[string]registry.Credential) {
+			var requests int32
+			server := fakeRegistry(auth, &requests)
+			defer server.Close()
+
+			ref := refFor(server, "team/app:1.0")
+			client := registry.NewClient(creds(ref.Registry), []string{ref.Registry})
+
+			info, err := client.Resolve(context.Background(), ref)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Digest).To(Equal("sha256:index"))
+			Expect(info.Created).NotTo(BeNil())
+			Expect(info.Created.Equal(created)).To(BeTrue())
+		},
+		Entry("anonymt", "none", func(string) map[string]registry.Credential { return nil }),
+		Entry("basic auth", "basic", func(host string) map[string]registry.Credential {
+			return map[string]registry.Credential{host: {Username: "ci", Password: "hemmelig"}}
+		}),
+		Entry("anonymt bearer-token", "bearer", func(string) map[string]registry.Credential { return nil }),
+		Entry("bearer-token med innlogging", "bearer-basic", func(host string) map[string]registry.Credential {
+			return map[string]registry.Credential{host: {Username: "ci", Password: "hemmelig"}}
+		}),
+		Entry("statisk bearer-token", "bearer", func(host string) map[string]registry.Credential {
+			return map[string]registry.Credential{host: {Token: "abc"}}
+		}),
+	)
+
+	It("cacher svar per referanse og 404", func() {
+		var requests int32
+		server := fakeRegistry("none", &requests)
+		defer server.Close()
+
+		ref := refFor(server, "team/app:1.0")
+		client := registry.NewClient(nil, []string{ref.Registry})
+
+		_, err := client.Resolve(context.Background(), ref)
+		Expect(err).NotTo(HaveOccurred())
+		first := atomic.LoadInt32(&requests)
+		_, err = client.Resolve(context.Background(), ref)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(atomic.LoadInt32(&requests)).To(Equal(first))
+
+		missing := refFor(server, "team/finnes-ikke:1.0")
+		_, err = client.Resolve(context.Background(), missing)
+		Expect(err).To(HaveOccurred())
+		afterMissing := atomic.LoadInt32(&requests)
+		_, err = client.Resolve(context.Background(), missing)
+		Expect(err).To(HaveOccurred())
+		Expect(atomic.LoadInt32(&requests)).To(Equal(afterMissing))
+	})
+
+	It("deler cache med og uten digest når taggen er satt", func() {
+		var requests int32
+		server := fakeRegistry("none", &requests)
+		defer server.Close()
+
+		client := registry.NewClient(nil, []string{refFor(server, "team/app:1.0").Registry})
+		_, err := client.Resolve(context.Background(), refFor(server, "team/app:1.0"))
+		Expect(err).NotTo(HaveOccurred())
+		first := atomic.LoadInt32(&requests)
+
+		info, err := client.Resolve(context.Background(), refFor(server, "team/app:1.0@sha256:gammel"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Digest).To(Equal("sha256:index"))
+		Expect(atomic.LoadInt32(&requests)).To(Equal(first))
+	})
+
+	DescribeTable("cacher ikke forbigående feil",
+		func(image string) {
+			var requests int32
+			server := fakeRegistry("none", &requests)
+			defer server.Close()
+
+			ref := refFor(server, image)
+			client := registry.NewClient(nil, []string{ref.Registry})
+
+			_, err := client.Resolve(context.Background(), ref)
+			Expect(err).To(HaveOccurred())
+			first := atomic.LoadInt32(&requests)
+			_, err = client.Resolve(context.Background(), ref)
+			Expect(err).To(HaveOccurred())
+			Expect(atomic.LoadInt32(&requests)).To(BeNumerically(">", first))
+		},
+		Entry("503", "team/travel:1.0"),
+		Entry("429", "team/begrenset:1.0"),
+	)
+
+	It("cacher ikke timeout", func() {
+		var requests int32
+		var slow atomic.Bool
+		slow.Store(true)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			if slow.Load() {
+				time.Sleep(200 * time.Millisecond)
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:treg")
+			fmt.Fprint(w, `{}`)
+		}))
+		defer server.Close()
+
+		ref := refFor(server, "team/treg:1.0")
+		client := registry.NewClient(nil, []string{ref.Registry})
+		client.HTTP.Timeout = 50 * time.Millisecond
+
+		_, err := client.Resolve(context.Background(), ref)
+		Expect(err).To(HaveOccurred())
+
+		slow.Store(false)
+		info, err := client.Resolve(context.Background(), ref)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Digest).To(Equal("sha256:treg"))
+	})
+
+	It("feiler uten innlogging når registryet krever basic auth", func() {
+		var requests int32
+		server := fakeRegistry("basic", &requests)
+		defer server.Close()
+
+		ref := refFor(server, "team/app:1.0")
+		_, err := registry.NewClient(nil, []string{ref.Registry}).Resolve(context.Background(), ref)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("ParseCredentials", func() {
+	It("skiller mellom basic og token", func() {
+		creds := registry.ParseCredentials("ghcr.io=ghp_abc, localhost:5000=ci:hemme:lig,ugyldig")
+		Expect(creds).To(Equal(map[string]registry.Credential{
+			"ghcr.io":        {Token: "ghp_abc"},
+			"localhost:5000": {Username: "ci", Password: "hemme:lig"},
+		}))
+	})
+
+	It("leser passord og token fra fil når verdien starter med @", func() {
+		dir := GinkgoT().TempDir()
+		key := `{"type": "service_account", "private_key": "a,b"}`
+		Expect(os.WriteFile(filepath.Join(dir, "gcp.json"), []byte(key+"\n"), 0o600)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "token"), []byte("ghp_abc"), 0o600)).To(Succeed())
+
+		creds := registry.ParseCredentials(fmt.Sprintf("europe-north1-docker.pkg.dev=_json_key:@%s/gcp.json,ghcr.io=@%s/token,quay.io=@%s/finnes-ikke", dir, dir, dir))
+		Expect(creds).To(Equal(map[string]registry.Credential{
+			"europe-north1-docker.pkg.dev": {Username: "_json_key", Password: key},
+			"ghcr.io":                      {Token: "ghp_abc"},
+		}))
+	})
+})
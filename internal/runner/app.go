@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"log/slog"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/jonmartinstorm/reposnusern/internal/config"
+	"github.com/jonmartinstorm/reposnusern/internal/lifecycle"
 	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/similarity"
 	_ "github.com/lib/pq"
 	"golang.org/x/sync/errgroup"
 )
@@ -26,6 +29,24 @@ type Fetcher interface {
 	FetchRepoGraphQL(ctx context.Context, baseRepo models.RepoMeta) (*models.RepoEntry, error)
 }
 
+// LifecycleStore er valgfri for writere som kan lese forrige snapshot og lagre livsløpshendelser.
+// Arkiverte repos som hoppes over lagres for seg, så de er med i forrige snapshot neste gang.
+type LifecycleStore interface {
+	PreviousRepoStates(ctx context.Context, snapshotDate time.Time) ([]lifecycle.RepoState, error)
+	ImportSkippedArchivedRepos(ctx context.Context, repos []lifecycle.RepoState, snapshotDate time.Time) error
+	ImportLifecycleEvents(ctx context.Context, events []lifecycle.Event, snapshotDate time.Time) error
+}
+
+// LineageStore er valgfri for writere som kan lagre slektskap mellom filer på tvers av repoene.
+type LineageStore interface {
+	ImportFileLineage(ctx context.Context, members []similarity.Member, snapshotDate time.Time) error
+}
+
+// RepoLookup er valgfri for fetchere som kan slå opp repos på ID utenfor org-listingen.
+type RepoLookup interface {
+	LookupRepoByID(ctx context.Context, id int64) (*models.RepoMeta, error)
+}
+
 type App struct {
 	Cfg     config.Config
 	Writer  DBWriter
@@ -48,8 +69,17 @@ func (a *App) Run(ctx context.Context) error {
 
 	page := 1
 	var repoIndex int64
+	var listed []lifecycle.RepoState
+	skippedArchived := map[int64]bool{}
+	completeListing := true
+
+	// Fingeravtrykkene samles fra alle goroutinene og grupperes når alle repos er hentet
+	var fingerprintsMu sync.Mutex
+	var fingerprints []similarity.File
 
 	sem := make(chan struct{}, a.Cfg.Parallelism)
+	// errgroup-konteksten kanselleres når Wait returnerer, så etterarbeid bruker den opprinnelige
+	runCtx := ctx
 	g, ctx := errgroup.WithContext(ctx)
 
 loop:
@@ -64,9 +94,11 @@ loop:
 
 		for _, repo := range repos {
 			repo := repo
+			listed = append(listed, lifecycle.RepoState{ID: repo.ID, FullName: repo.FullName, Archived: repo.Archived})
 
 			if a.Cfg.SkipArchived && repo.Archived {
 				slog.Debug("Skipper arkivert repo", "repo", repo.FullName)
+				skippedArchived[repo.ID] = true
 				continue
 			}
 
@@ -74,6 +106,7 @@ loop:
 			currentIndex := atomic.LoadInt64(&repoIndex)
 			if a.Cfg.Debug && currentIndex >= MaxDebugRepos {
 				slog.Info("Debug-modus: nådd maks antall repos", "antall", MaxDebugRepos)
+				completeListing = false
 				break loop
 			}
 			sem <- struct{}{}
@@ -95,6 +128,12 @@ loop:
 					return fmt.Errorf("import repo: %w", err)
 				}
 
+				fingerprintsMu.Lock()
+				for _, fp := range entry.FileFingerprints {
+					fingerprints = append(fingerprints, similarity.File{RepoID: entry.Repo.ID, Fingerprint: fp})
+				}
+				fingerprintsMu.Unlock()
+
 				if idx%25 == 0 {
 					runtime.GC()
 				}
@@ -110,11 +149,96 @@ loop:
 		return err
 	}
 
+	// Uten komplett liste ville alle repos vi ikke rakk se ut som slettet
+	if completeListing {
+		a.recordLifecycleEvents(runCtx, snapshotTime, listed, skippedArchived)
+	}
+
+	a.recordFileLineage(runCtx, snapshotTime, fingerprints)
+
 	logMemoryStats()
 	slog.Info("Ferdig med alle repos!", "varighet", time.Since(snapshotTime).String())
 	return nil
 }
 
+// recordLifecycleEvents sammenligner repo-listen fra denne kjøringen med forrige snapshot
+// og lagrer hendelser som rename, overføring, arkivering og sletting.
+func (a *App) recordLifecycleEvents(ctx context.Context, snapshotTime time.Time, listed []lifecycle.RepoState, skippedArchived map[int64]bool) {
+	store, ok := a.Writer.(LifecycleStore)
+	if !ok {
+		return
+	}
+
+	var skipped []lifecycle.RepoState
+	for _, repo := range listed {
+		if skippedArchived[repo.ID] {
+			skipped = append(skipped, repo)
+		}
+	}
+	if err := store.ImportSkippedArchivedRepos(ctx, skipped, snapshotTime); err != nil {
+		slog.Error("Klarte ikke lagre arkiverte repos", "error", err)
+	}
+
+	previous, err := store.PreviousRepoStates(ctx, snapshotTime)
+	if err != nil {
+		slog.Warn("Klarte ikke hente forrige snapshot", "error", err)
+		return
+	}
+	if len(previous) == 0 {
+		slog.Info("Ingen tidligere snapshot – hopper over livsløpshendelser")
+		return
+	}
+
+	known := make(map[int64]bool, len(previous))
+	for _, p := range previous {
+		known[p.ID] = true
+	}
+
+	// Arkiverte repos som ikke var med i forrige snapshot, f.eks. fordi de ble arkivert før
+	// hoppede repos ble lagret, er ikke nye, bare usynlige for oss
+	var current []lifecycle.RepoState
+	for _, repo := range listed {
+		if skippedArchived[repo.ID] && !known[repo.ID] {
+			continue
+		}
+		current = append(current, repo)
+	}
+
+	var lookup lifecycle.LookupFunc
+	if finder, ok := a.Fetcher.(RepoLookup); ok {
+		lookup = func(ctx context.Context, id int64) (*lifecycle.RepoState, error) {
+			repo, err := finder.LookupRepoByID(ctx, id)
+			if err != nil || repo == nil {
+				return nil, err
+			}
+			return &lifecycle.RepoState{ID: repo.ID, FullName: repo.FullName, Archived: repo.Archived}, nil
+		}
+	}
+
+	events := lifecycle.Detect(ctx, a.Cfg.Org, previous, current, lookup)
+	slog.Info("Livsløpshendelser funnet", "antall", len(events))
+
+	if err := store.ImportLifecycleEvents(ctx, events, snapshotTime); err != nil {
+		slog.Error("Klarte ikke lagre livsløpshendelser", "error", err)
+	}
+}
+
+// recordFileLineage grupperer nesten like Dockerfiles og workflows på tvers av repoene
+// og lagrer hvor langt hver fil har drevet fra klyngens vanligste variant.
+func (a *App) recordFileLineage(ctx context.Context, snapshotTime time.Time, fingerprints []similarity.File) {
+	store, ok := a.Writer.(LineageStore)
+	if !ok {
+		return
+	}
+
+	members := similarity.Cluster(fingerprints)
+	slog.Info("Filer med felles slekt funnet", "filer", len(fingerprints), "i_klynger", len(members))
+
+	if err := store.ImportFileLineage(ctx, members, snapshotTime); err != nil {
+		slog.Error("Klarte ikke lagre slektskap mellom filer", "error", err)
+	}
+}
+
 func logMemoryStats() {
 	var m runtime.MemStats
 	runtime.ReadMemStats(&m)
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/jonmartinstorm/reposnusern/internal/config"
+	"github.com/jonmartinstorm/reposnusern/internal/lifecycle"
 	"github.com/jonmartinstorm/reposnusern/internal/mocks"
 	"github.com/jonmartinstorm/reposnusern/internal/models"
 	"github.com/jonmartinstorm/reposnusern/internal/runner"
@@ -89,3 +91,75 @@ var _ = Describe("App.Run", func() {
 		Expect(writer.Calls).To(HaveLen(10))
 	})
 })
+
+// snapshotStore er en writer i minnet der hver kjøring regnes som ett snapshot
+type snapshotStore struct {
+	previous []lifecycle.RepoState
+	current  []lifecycle.RepoState
+	events   []lifecycle.Event
+}
+
+func (s *snapshotStore) ImportRepo(_ context.Context, entry models.RepoEntry, _ time.Time) error {
+	s.current = append(s.current, lifecycle.RepoState{ID: entry.Repo.ID, FullName: entry.Repo.FullName, Archived: entry.Repo.Archived})
+	return nil
+}
+
+func (s *snapshotStore) PreviousRepoStates(context.Context, time.Time) ([]lifecycle.RepoState, error) {
+	return s.previous, nil
+}
+
+func (s *snapshotStore) ImportSkippedArchivedRepos(_ context.Context, repos []lifecycle.RepoState, _ time.Time) error {
+	s.current = append(s.current, repos...)
+	return nil
+}
+
+func (s *snapshotStore) ImportLifecycleEvents(_ context.Context, events []lifecycle.Event, _ time.Time) error {
+	s.events = events
+	return nil
+}
+
+func (s *snapshotStore) nextSnapshot() {
+	s.previous, s.current, s.events = s.current, nil, nil
+}
+
+type listFetcher struct {
+	repos []models.RepoMeta
+}
+
+func (f *listFetcher) GetReposPage(_ context.Context, _ config.Config, page int) ([]models.RepoMeta, error) {
+	if page > 1 {
+		return nil, nil
+	}
+	return f.repos, nil
+}
+
+func (f *listFetcher) FetchRepoGraphQL(_ context.Context, repo models.RepoMeta) (*models.RepoEntry, error) {
+	return &models.RepoEntry{Repo: repo}, nil
+}
+
+var _ = Describe("Livsløpshendelser", func() {
+	It("melder gjenåpning av repos som ble hoppet over mens de var arkivert", func() {
+		cfg := config.Config{Org: "testorg", SkipArchived: true, Parallelism: 1}
+		store := &snapshotStore{}
+		fetcher := &listFetcher{}
+		app := runner.NewApp(cfg, store, fetcher)
+
+		fetcher.repos = []models.RepoMeta{{ID: 1, FullName: "testorg/app"}}
+		Expect(app.Run(context.Background())).To(Succeed())
+		store.nextSnapshot()
+
+		fetcher.repos = []models.RepoMeta{{ID: 1, FullName: "testorg/app", Archived: true}}
+		Expect(app.Run(context.Background())).To(Succeed())
+		Expect(store.events).To(Equal([]lifecycle.Event{
+			{RepoID: 1, Type: lifecycle.EventArchived, OldFullName: "testorg/app", NewFullName: "testorg/app"},
+		}))
+		Expect(store.current).To(Equal([]lifecycle.RepoState{{ID: 1, FullName: "testorg/app", Archived: true}}))
+		store.nextSnapshot()
+
+		fetcher.repos = []models.RepoMeta{{ID: 1, FullName: "testorg/app"}}
+		Expect(app.Run(context.Background())).To(Succeed())
+		Expect(store.events).To(Equal([]lifecycle.Event{
+			{RepoID: 1, Type: lifecycle.EventUnarchived, OldFullName: "testorg/app", NewFullName: "testorg/app"},
+		}))
+	})
+})
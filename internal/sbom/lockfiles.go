@@ -0,0 +1,260 @@
+package sbom
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+func parseGoSum(content string) []Package {
+	var pkgs []Package
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		// Linjer med /go.mod-suffiks er bare hash av go.mod, ikke selve modulen
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		pkgs = append(pkgs, Package{
+			Name:    fields[0],
+			Version: fields[1],
+			PURL:    "pkg:golang/" + fields[0] + "@" + fields[1],
+		})
+	}
+	return pkgs
+}
+
+func parsePackageLock(content string) []Package {
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+			Link    bool   `json:"link"`
+		} `json:"packages"`
+		Dependencies map[string]npmLockDep `json:"dependencies"`
+	}
+	if err := json.Unmarshal([]byte(content), &lock); err != nil {
+		return nil
+	}
+
+	var pkgs []Package
+	// lockfileVersion 2 og 3
+	if len(lock.Packages) > 0 {
+		for key, p := range lock.Packages {
+			if key == "" || p.Link || p.Version == "" {
+				continue
+			}
+			name := key[strings.LastIndex(key, "node_modules/")+len("node_modules/"):]
+			pkgs = append(pkgs, npmPackage(name, p.Version))
+		}
+		sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].PURL < pkgs[j].PURL })
+		return pkgs
+	}
+
+	// lockfileVersion 1
+	var walk func(deps map[string]npmLockDep)
+	walk = func(deps map[string]npmLockDep) {
+		for name, d := range deps {
+			if d.Version != "" {
+				pkgs = append(pkgs, npmPackage(name, d.Version))
+			}
+			walk(d.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].PURL < pkgs[j].PURL })
+	return pkgs
+}
+
+type npmLockDep struct {
+	Version      string                `json:"version"`
+	Dependencies map[string]npmLockDep `json:"dependencies"`
+}
+
+func parseYarnLock(content string) []Package {
+	var pkgs []Package
+	var current string
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Ny blokk: `"@babel/core@^7.0.0", "@babel/core@^7.1.0":` (v1) eller `"@babel/core@npm:^7.0.0":` (berry)
+		if !strings.HasPrefix(line, " ") {
+			spec := strings.TrimSuffix(strings.TrimSpace(line), ":")
+			spec = strings.Trim(strings.Split(spec, ",")[0], `" `)
+			current = yarnPackageName(spec)
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if current == "" || !strings.HasPrefix(trimmed, "version") {
+			continue
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(trimmed, "version"))
+		version = strings.Trim(strings.TrimPrefix(version, ":"), `" `)
+		if version != "" && !strings.HasPrefix(version, "0.0.0-use.local") {
+			pkgs = append(pkgs, npmPackage(current, version))
+		}
+		current = ""
+	}
+	return pkgs
+}
+
+func yarnPackageName(spec string) string {
+	if spec == "" || spec == "__metadata" {
+		return ""
+	}
+	// Første tegn kan være @ for scoped pakker
+	if idx := strings.LastIndex(spec[1:], "@"); idx >= 0 {
+		return spec[:idx+1]
+	}
+	return spec
+}
+
+func npmPackage(name, version string) Package {
+	return Package{
+		Name:    name,
+		Version: version,
+		PURL:    "pkg:npm/" + strings.Replace(name, "@", "%40", 1) + "@" + version,
+	}
+}
+
+type pomProject struct {
+	GroupID    string        `xml:"groupId"`
+	Version    string        `xml:"version"`
+	Parent     pomDependency `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Dependencies []pomDependency `xml:"dependencies>dependency"`
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+var pomPropertyPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+func parsePom(content string) []Package {
+	var project pomProject
+	if err := xml.Unmarshal([]byte(content), &project); err != nil {
+		return nil
+	}
+
+	props := map[string]string{
+		"project.version":        project.Version,
+		"project.groupId":        project.GroupID,
+		"project.parent.version": project.Parent.Version,
+	}
+	if props["project.version"] == "" {
+		props["project.version"] = project.Parent.Version
+	}
+	for _, p := range project.Properties.Entries {
+		props[p.XMLName.Local] = strings.TrimSpace(p.Value)
+	}
+
+	var pkgs []Package
+	for _, d := range project.Dependencies {
+		group := resolvePomProperties(d.GroupID, props)
+		version := resolvePomProperties(d.Version, props)
+		if group == "" || d.ArtifactID == "" {
+			continue
+		}
+		pkgs = append(pkgs, mavenPackage(group, d.ArtifactID, version))
+	}
+	return pkgs
+}
+
+func resolvePomProperties(value string, props map[string]string) string {
+	value = strings.TrimSpace(value)
+	return pomPropertyPattern.ReplaceAllStringFunc(value, func(m string) string {
+		if v, ok := props[m[2:len(m)-1]]; ok && v != "" {
+			return v
+		}
+		return m
+	})
+}
+
+func parseGradleLockfile(content string) []Package {
+	var pkgs []Package
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "empty=") {
+			continue
+		}
+		coords := strings.SplitN(line, "=", 2)[0]
+		parts := strings.Split(coords, ":")
+		if len(parts) != 3 {
+			continue
+		}
+		pkgs = append(pkgs, mavenPackage(parts[0], parts[1], parts[2]))
+	}
+	return pkgs
+}
+
+func mavenPackage(group, artifact, version string) Package {
+	purl := "pkg:maven/" + group + "/" + artifact
+	if version != "" && !strings.Contains(version, "${") {
+		purl += "@" + url.PathEscape(version)
+	}
+	return Package{
+		Name:    group + ":" + artifact,
+		Version: version,
+		PURL:    purl,
+	}
+}
+
+func parsePoetryLock(content string) []Package {
+	var pkgs []Package
+	var name, version string
+	inPackage := false
+
+	flush := func() {
+		if inPackage && name != "" {
+			normalized := strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+			pkgs = append(pkgs, Package{
+				Name:    name,
+				Version: version,
+				PURL:    "pkg:pypi/" + normalized + "@" + version,
+			})
+		}
+		name, version = "", ""
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			flush()
+			inPackage = line == "[[package]]"
+			continue
+		}
+		if !inPackage {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.TrimSpace(key) {
+		case "name":
+			name = value
+		case "version":
+			version = value
+		}
+	}
+	flush()
+	return pkgs
+}
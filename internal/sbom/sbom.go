@@ -0,0 +1,172 @@
+package sbom
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+// Kilder for SBOM-data, lagres på hver pakke-rad.
+const (
+	SourceGitHub = "github"
+	SourceLocal  = "local"
+)
+
+// Package er en pakke funnet i en låsefil eller et manifest.
+type Package struct {
+	Name    string
+	Version string
+	PURL    string
+}
+
+type lockfileParser func(content string) []Package
+
+// Filene vi kan bygge en lokal SBOM fra, nøklet på filnavn i små bokstaver.
+var lockfileParsers = map[string]lockfileParser{
+	"go.sum":            parseGoSum,
+	"package-lock.json": parsePackageLock,
+	"yarn.lock":         parseYarnLock,
+	"pom.xml":           parsePom,
+	"gradle.lockfile":   parseGradleLockfile,
+	"poetry.lock":       parsePoetryLock,
+}
+
+// IsLockfile sier om et filnavn er en fil BuildLocal kan lese pakker fra.
+func IsLockfile(name string) bool {
+	name = strings.ToLower(name)
+	if _, ok := lockfileParsers[name]; ok {
+		return true
+	}
+	// Gradle lager også f.eks. buildscript-gradle.lockfile
+	return strings.HasSuffix(name, ".lockfile")
+}
+
+// BuildLocal bygger en SPDX-kompatibel SBOM fra hentede manifester og låsefiler.
+// Formatet speiler GitHubs dependency-graph/sbom, slik at writerne kan lese begge likt.
+// Returnerer nil hvis ingen pakker ble funnet.
+func BuildLocal(repoFullName string, files map[string][]models.FileEntry) map[string]interface{} {
+	seen := map[string]bool{}
+	var pkgs []Package
+
+	keys := make([]string, 0, len(files))
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		parse, ok := lockfileParsers[key]
+		if !ok && strings.HasSuffix(key, ".lockfile") {
+			parse = parseGradleLockfile
+		}
+		if parse == nil {
+			continue
+		}
+		for _, f := range files[key] {
+			for _, p := range parse(f.Content) {
+				if p.Name == "" || seen[p.PURL] {
+					continue
+				}
+				seen[p.PURL] = true
+				pkgs = append(pkgs, p)
+			}
+		}
+	}
+
+	if len(pkgs) == 0 {
+		return nil
+	}
+	slog.Debug("Bygget lokal SBOM", "repo", repoFullName, "pakker", len(pkgs))
+
+	spdxPackages := make([]interface{}, 0, len(pkgs))
+	for i, p := range pkgs {
+		pkg := map[string]interface{}{
+			"SPDXID":           fmt.Sprintf("SPDXRef-Package-%d", i+1),
+			"name":             p.Name,
+			"versionInfo":      p.Version,
+			"downloadLocation": "NOASSERTION",
+			"licenseConcluded": "NOASSERTION",
+		}
+		if p.PURL != "" {
+			pkg["externalRefs"] = []interface{}{
+				map[string]interface{}{
+					"referenceCategory": "PACKAGE-MANAGER",
+					"referenceType":     "purl",
+					"referenceLocator":  p.PURL,
+				},
+			}
+		}
+		spdxPackages = append(spdxPackages, pkg)
+	}
+
+	return map[string]interface{}{
+		"sbom": map[string]interface{}{
+			"SPDXID":      "SPDXRef-DOCUMENT",
+			"spdxVersion": "SPDX-2.3",
+			"dataLicense": "CC0-1.0",
+			"name":        repoFullName,
+			"creationInfo": map[string]interface{}{
+				"creators": []interface{}{"Tool: reposnusern"},
+			},
+			"packages": spdxPackages,
+		},
+	}
+}
+
+// Packages leser pakkene ut av en SBOM i GitHubs format, enten den kommer fra GitHub eller BuildLocal.
+func Packages(doc map[string]interface{}) []Package {
+	inner, ok := doc["sbom"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	list, _ := inner["packages"].([]interface{})
+
+	var pkgs []Package
+	for _, p := range list {
+		pkg, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := pkg["name"].(string)
+		version, _ := pkg["versionInfo"].(string)
+		result := Package{Name: name, Version: version}
+		refs, _ := pkg["externalRefs"].([]interface{})
+		for _, r := range refs {
+			if ref, ok := r.(map[string]interface{}); ok && ref["referenceType"] == "purl" {
+				result.PURL, _ = ref["referenceLocator"].(string)
+				break
+			}
+		}
+		pkgs = append(pkgs, result)
+	}
+	return pkgs
+}
+
+// ParsePURL deler en purl i type, navn og versjon, f.eks. "pkg:maven/org.example/app@1.0"
+// blir "maven", "org.example/app" og "1.0". Qualifiers og subpath tas ikke med.
+func ParsePURL(purl string) (purlType, name, version string, ok bool) {
+	rest, found := strings.CutPrefix(purl, "pkg:")
+	if !found {
+		return "", "", "", false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+	purlType, rest, found = strings.Cut(rest, "/")
+	if !found || rest == "" {
+		return "", "", "", false
+	}
+	if i := strings.LastIndex(rest, "@"); i > 0 {
+		rest, version = rest[:i], rest[i+1:]
+	}
+	if unescaped, err := url.PathUnescape(rest); err == nil {
+		rest = unescaped
+	}
+	if unescaped, err := url.PathUnescape(version); err == nil {
+		version = unescaped
+	}
+	return strings.ToLower(purlType), rest, version, true
+}
@@ -0,0 +1,161 @@
+package sbom_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/sbom"
+)
+
+func TestSBOM(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SBOM – lokal generering")
+}
+
+func purls(doc map[string]interface{}) []string {
+	var out []string
+	inner := doc["sbom"].(map[string]interface{})
+	for _, p := range inner["packages"].([]interface{}) {
+		pkg := p.(map[string]interface{})
+		refs := pkg["externalRefs"].([]interface{})
+		out = append(out, refs[0].(map[string]interface{})["referenceLocator"].(string))
+	}
+	return out
+}
+
+var _ = Describe("BuildLocal", func() {
+	It("returnerer nil når ingen låsefiler finnes", func() {
+		files := map[string][]models.FileEntry{
+			"dockerfile": {{Path: "Dockerfile", Content: "FROM alpine"}},
+		}
+		Expect(sbom.BuildLocal("org/repo", files)).To(BeNil())
+	})
+
+	DescribeTable("lager purl for hver pakke",
+		func(key, content string, expected []string) {
+			doc := sbom.BuildLocal("org/repo", map[string][]models.FileEntry{
+				key: {{Path: key, Content: content}},
+			})
+			Expect(doc).NotTo(BeNil())
+			Expect(purls(doc)).To(Equal(expected))
+		},
+
+		Entry("go.sum hopper over /go.mod-linjer", "go.sum",
+			`github.com/google/uuid v1.6.0 h1:abc=
+github.com/google/uuid v1.6.0/go.mod h1:def=
+golang.org/x/sync v0.14.0/go.mod h1:ghi=`,
+			[]string{"pkg:golang/github.com/google/uuid@v1.6.0"},
+		),
+
+		Entry("package-lock.json v3 med scoped pakke", "package-lock.json",
+			`{"lockfileVersion": 3, "packages": {
+  "": {"name": "app"},
+  "node_modules/react": {"version": "18.2.0"},
+  "node_modules/@babel/core": {"version": "7.24.0"},
+  "node_modules/@babel/core/node_modules/semver": {"version": "6.3.1"}
+}}`,
+			[]string{"pkg:npm/%40babel/core@7.24.0", "pkg:npm/react@18.2.0", "pkg:npm/semver@6.3.1"},
+		),
+
+		Entry("yarn.lock v1", "yarn.lock",
+			`# yarn lockfile v1
+
+"@types/node@*", "@types/node@^20.0.0":
+  version "20.11.5"
+  resolved "https://registry.yarnpkg.com/@types/node/-/node-20.11.5.tgz"
+
+lodash@^4.17.21:
+  version "4.17.21"
+`,
+			[]string{"pkg:npm/%40types/node@20.11.5", "pkg:npm/lodash@4.17.21"},
+		),
+
+		Entry("pom.xml med properties", "pom.xml",
+			`<project>
+  <properties><kotlin.version>2.0.0</kotlin.version></properties>
+  <dependencies>
+    <dependency>
+      <groupId>org.jetbrains.kotlin</groupId>
+      <artifactId>kotlin-stdlib</artifactId>
+      <version>${kotlin.version}</version>
+    </dependency>
+  </dependencies>
+</project>`,
+			[]string{"pkg:maven/org.jetbrains.kotlin/kotlin-stdlib@2.0.0"},
+		),
+
+		Entry("gradle.lockfile", "gradle.lockfile",
+			`# This is a Gradle generated file for dependency locking.
+io.ktor:ktor-server-core:2.3.9=compileClasspath,runtimeClasspath
+empty=annotationProcessor`,
+			[]string{"pkg:maven/io.ktor/ktor-server-core@2.3.9"},
+		),
+
+		Entry("poetry.lock", "poetry.lock",
+			`[[package]]
+name = "Flask_Cors"
+version = "4.0.0"
+
+[package.dependencies]
+Flask = ">=0.9"
+
+[[package]]
+name = "requests"
+version = "2.31.0"`,
+			[]string{"pkg:pypi/flask-cors@4.0.0", "pkg:pypi/requests@2.31.0"},
+		),
+	)
+
+	It("tåler ødelagte yarn.lock-filer", func() {
+		doc := sbom.BuildLocal("org/repo", map[string][]models.FileEntry{
+			"yarn.lock": {{Path: "yarn.lock", Content: `:
+  version "1.0.0"
+"":
+  version "2.0.0"
+
+lodash@^4.17.21:
+  version "4.17.21"
+`}},
+		})
+		Expect(purls(doc)).To(Equal([]string{"pkg:npm/lodash@4.17.21"}))
+	})
+
+	It("fjerner duplikater på tvers av filer", func() {
+		doc := sbom.BuildLocal("org/repo", map[string][]models.FileEntry{
+			"go.sum": {
+				{Path: "go.sum", Content: "github.com/lib/pq v1.10.9 h1:abc="},
+				{Path: "tools/go.sum", Content: "github.com/lib/pq v1.10.9 h1:abc="},
+			},
+		})
+		Expect(purls(doc)).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("IsLockfile", func() {
+	It("kjenner igjen låsefiler uavhengig av store bokstaver", func() {
+		Expect(sbom.IsLockfile("Poetry.lock")).To(BeTrue())
+		Expect(sbom.IsLockfile("buildscript-gradle.lockfile")).To(BeTrue())
+		Expect(sbom.IsLockfile("README.md")).To(BeFalse())
+	})
+})
+
+var _ = Describe("ParsePURL", func() {
+	DescribeTable("deler purl i type, navn og versjon",
+		func(purl, purlType, name, version string) {
+			t, n, v, ok := sbom.ParsePURL(purl)
+			Expect(ok).To(BeTrue())
+			Expect([]string{t, n, v}).To(Equal([]string{purlType, name, version}))
+		},
+		Entry("maven", "pkg:maven/org.springframework.boot/spring-boot@3.2.1", "maven", "org.springframework.boot/spring-boot", "3.2.1"),
+		Entry("scoped npm", "pkg:npm/%40babel/core@7.24.0", "npm", "@babel/core", "7.24.0"),
+		Entry("uten versjon, med qualifiers", "pkg:pypi/django?repository_url=x", "pypi", "django", ""),
+	)
+
+	It("avviser alt som ikke er en purl", func() {
+		_, _, _, ok := sbom.ParsePURL("https://example.com")
+		Expect(ok).To(BeFalse())
+	})
+})
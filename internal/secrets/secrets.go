@@ -0,0 +1,194 @@
+package secrets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+// ReadmePath er stien funn i README får, siden README ikke ligger som FileEntry
+const ReadmePath = "README.md"
+
+// Minste entropi (bits per tegn) for generiske treff som password=... og token: ...
+// Vanlige ord og plassholdere ligger godt under, tilfeldige nøkler over.
+const minGenericEntropy = 3.5
+
+type rule struct {
+	id          string
+	description string
+	pattern     *regexp.Regexp
+	// group er capture-gruppen som er selve hemmeligheten; 0 betyr hele treffet
+	group      int
+	minEntropy float64
+}
+
+var rules = []rule{
+	{
+		id:          "SEC001",
+		description: "GitHub-token",
+		pattern:     regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`),
+	},
+	{
+		id:          "SEC002",
+		description: "AWS access key ID",
+		pattern:     regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	},
+	{
+		id:          "SEC003",
+		description: "Privat nøkkel",
+		pattern:     regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----[\s\S]*?-----END (?:[A-Z]+ )*PRIVATE KEY-----`),
+	},
+	{
+		id:          "SEC004",
+		description: "Slack-token eller webhook",
+		pattern:     regexp.MustCompile(`\bxox[baprs]-[A-Za-z0-9-]{10,}|https://hooks\.slack\.com/services/T[A-Za-z0-9]+/B[A-Za-z0-9]+/[A-Za-z0-9]+`),
+	},
+	{
+		id:          "SEC005",
+		description: "Google API-nøkkel",
+		pattern:     regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),
+	},
+	{
+		id:          "SEC006",
+		description: "JSON Web Token",
+		pattern:     regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`),
+	},
+	{
+		id:          "SEC007",
+		description: "Passord i URL",
+		pattern:     regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^/\s:@"']+:([^/\s:@"'$<{}]{3,})@`),
+		group:       1,
+	},
+	{
+		id:          "SEC008",
+		description: "Hemmelig verdi med høy entropi",
+		pattern: regexp.MustCompile(`(?i)(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|client[_-]?secret)` +
+			`["']?\s*[:=]\s*["']?([A-Za-z0-9+/=_.-]{16,})`),
+		group:      1,
+		minEntropy: minGenericEntropy,
+	},
+}
+
+// Finding er én hemmelighet funnet i en fil. Verdien lagres aldri, bare en SHA-256 av den,
+// så samme hemmelighet kan kjennes igjen på tvers av repos og snapshots.
+type Finding struct {
+	RuleID      string
+	Description string
+	Line        int
+	Hash        string
+}
+
+type match struct {
+	start, end int
+	rule       rule
+}
+
+// Scan finner hemmeligheter i content og returnerer innholdet med hver hemmelighet
+// byttet ut med <redacted:REGEL>. Overlappende treff telles bare én gang, i regelrekkefølge.
+func Scan(content string) (string, []Finding) {
+	var matches []match
+	for _, r := range rules {
+		for _, idx := range r.pattern.FindAllStringSubmatchIndex(content, -1) {
+			start, end := idx[2*r.group], idx[2*r.group+1]
+			if start < 0 {
+				continue
+			}
+			if r.minEntropy > 0 && entropy(content[start:end]) < r.minEntropy {
+				continue
+			}
+			matches = append(matches, match{start: start, end: end, rule: r})
+		}
+	}
+	if len(matches) == 0 {
+		return content, nil
+	}
+	// Stabil sortering på start beholder regelrekkefølgen for treff på samme sted
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	var b strings.Builder
+	var findings []Finding
+	pos := 0
+	for _, m := range matches {
+		if m.start < pos {
+			continue
+		}
+		sum := sha256.Sum256([]byte(content[m.start:m.end]))
+		findings = append(findings, Finding{
+			RuleID:      m.rule.id,
+			Description: m.rule.description,
+			Line:        strings.Count(content[:m.start], "\n") + 1,
+			Hash:        hex.EncodeToString(sum[:]),
+		})
+		b.WriteString(content[pos:m.start])
+		b.WriteString(fmt.Sprintf("<redacted:%s>", m.rule.id))
+		pos = m.end
+	}
+	b.WriteString(content[pos:])
+	return b.String(), findings
+}
+
+// RedactEntry skanner alle filer i repoet og README, og bytter ut hemmelighetene i innholdet.
+// Den må kjøres før analysene, så hemmelighetene ikke havner i feltene som utledes fra filene.
+func RedactEntry(entry *models.RepoEntry) []models.SecretFinding {
+	var result []models.SecretFinding
+	record := func(path string, findings []Finding) {
+		for _, f := range findings {
+			result = append(result, models.SecretFinding{
+				Path:        path,
+				RuleID:      f.RuleID,
+				Description: f.Description,
+				Line:        f.Line,
+				Hash:        f.Hash,
+			})
+		}
+	}
+
+	keys := make([]string, 0, len(entry.Files))
+	for k := range entry.Files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		list := entry.Files[key]
+		for i := range list {
+			redacted, findings := Scan(list[i].Content)
+			list[i].Content = redacted
+			record(list[i].Path, findings)
+		}
+	}
+	for i := range entry.CIConfig {
+		redacted, findings := Scan(entry.CIConfig[i].Content)
+		entry.CIConfig[i].Content = redacted
+		record(entry.CIConfig[i].Path, findings)
+	}
+
+	redacted, findings := Scan(entry.Repo.Readme)
+	entry.Repo.Readme = redacted
+	record(ReadmePath, findings)
+
+	return result
+}
+
+// entropy er Shannon-entropien i bits per tegn.
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	var h float64
+	n := float64(len([]rune(s)))
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
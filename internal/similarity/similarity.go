@@ -0,0 +1,324 @@
+package similarity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+const (
+	FileTypeDockerfile = "dockerfile"
+	FileTypeWorkflow   = "workflow"
+)
+
+// Grensene for når to varianter regnes som samme slekt. SimHash-avstanden er et billig
+// forfilter; linjelikheten (Jaccard) avgjør.
+const (
+	MaxDistance   = 16
+	MinSimilarity = 0.5
+)
+
+// RepoNamePlaceholder erstatter repo-navnet, så kopier av samme mal bare skiller seg på ekte endringer
+const RepoNamePlaceholder = "<repo>"
+
+// Kortere repo-navn enn dette erstattes ikke, de treffer for mye annet (f.eks. "api")
+const minRepoNameLength = 4
+
+// Fingerprints regner ut normalisert hash og SimHash for alle Dockerfiles og workflows i repoet.
+func Fingerprints(entry *models.RepoEntry) []models.FileFingerprint {
+	var result []models.FileFingerprint
+
+	keys := make([]string, 0, len(entry.Files))
+	for k := range entry.Files {
+		if strings.HasPrefix(strings.ToLower(k), "dockerfile") {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, f := range entry.Files[k] {
+			if fp, ok := Fingerprint(FileTypeDockerfile, f.Path, f.Content, entry.Repo.Name); ok {
+				result = append(result, fp)
+			}
+		}
+	}
+	for _, f := range entry.CIConfig {
+		if fp, ok := Fingerprint(FileTypeWorkflow, f.Path, f.Content, entry.Repo.Name); ok {
+			result = append(result, fp)
+		}
+	}
+	return result
+}
+
+// Fingerprint normaliserer innholdet og gir hash og SimHash. Filer uten innhold etter normalisering hoppes over.
+func Fingerprint(fileType, path, content, repoName string) (models.FileFingerprint, bool) {
+	lines := Normalize(fileType, content, repoName)
+	if len(lines) == 0 {
+		return models.FileFingerprint{}, false
+	}
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	hashes := lineHashes(lines)
+	return models.FileFingerprint{
+		Path:        path,
+		FileType:    fileType,
+		ContentHash: hex.EncodeToString(sum[:]),
+		SimHash:     fmt.Sprintf("%016x", simHash(hashes)),
+		LineCount:   len(lines),
+		LineHashes:  hashes,
+	}, true
+}
+
+// Normalize fjerner det som ikke endrer hva filen gjør: kommentarer, tomme linjer, innrykk og
+// ekstra mellomrom. Dockerfile-instruksjoner skrives med store bokstaver og linjer som fortsetter
+// med \ slås sammen.
+func Normalize(fileType, content, repoName string) []string {
+	if len(repoName) >= minRepoNameLength {
+		content = strings.ReplaceAll(content, repoName, RepoNamePlaceholder)
+	}
+
+	var lines []string
+	var pending string
+	for _, raw := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		line := strings.Join(strings.Fields(raw), " ")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if fileType == FileTypeDockerfile {
+			if strings.HasSuffix(line, "\\") {
+				pending += strings.TrimSpace(strings.TrimSuffix(line, "\\")) + " "
+				continue
+			}
+			line = pending + line
+			pending = ""
+			if instr, rest, found := strings.Cut(line, " "); found {
+				line = strings.ToUpper(instr) + " " + rest
+			} else {
+				line = strings.ToUpper(line)
+			}
+		}
+		lines = append(lines, line)
+	}
+	if pending != "" {
+		lines = append(lines, strings.TrimSpace(pending))
+	}
+	return lines
+}
+
+func lineHashes(lines []string) []uint64 {
+	seen := make(map[uint64]bool, len(lines))
+	var result []uint64
+	for _, line := range lines {
+		h := fnv.New64a()
+		h.Write([]byte(line))
+		sum := h.Sum64()
+		if !seen[sum] {
+			seen[sum] = true
+			result = append(result, sum)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// simHash gir et 64-bits fingeravtrykk der like linjesett gir få ulike bits
+func simHash(hashes []uint64) uint64 {
+	var weights [64]int
+	for _, h := range hashes {
+		for i := 0; i < 64; i++ {
+			if h&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+	var result uint64
+	for i, w := range weights {
+		if w > 0 {
+			result |= 1 << uint(i)
+		}
+	}
+	return result
+}
+
+// jaccard regner linjelikhet mellom to sorterte, unike lister med linjehasher
+func jaccard(a, b []uint64) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	common, i, j := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			common++
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// File er én fil i org-en som skal grupperes.
+type File struct {
+	RepoID      int64
+	Fingerprint models.FileFingerprint
+}
+
+// Member er en fil som deler slekt med minst én annen fil i samme snapshot.
+type Member struct {
+	RepoID       int64
+	Path         string
+	FileType     string
+	ContentHash  string
+	ClusterID    string // hashen til klyngens vanligste variant, stabil så lenge den er vanligst
+	ClusterSize  int    // antall filer
+	ClusterRepos int
+	Variants     int // antall ulike normaliserte varianter i klyngen
+	IsCanonical  bool
+	Distance     int     // SimHash-avstand i bits til vanligste variant
+	Divergence   float64 // 0 = lik vanligste variant, 1 = ingen felles linjer
+}
+
+type variant struct {
+	fileType string
+	hash     string
+	simHash  uint64
+	lines    []uint64
+	files    []File
+	repos    map[int64]bool
+}
+
+type cluster struct {
+	leader   *variant
+	variants []*variant
+}
+
+// Cluster grupperer nesten like filer av samme type på tvers av repoene. Variantene behandles
+// fra den vanligste og ned, og hver variant legges i første klynge der den ligner nok på den
+// vanligste varianten. Slik blir lederen alltid klyngens vanligste variant, og klynger kan ikke
+// vokse i kjeder av små endringer. Filer uten slektninger utelates.
+func Cluster(files []File) []Member {
+	byHash := map[string]*variant{}
+	var variants []*variant
+	for _, f := range files {
+		key := f.Fingerprint.FileType + ":" + f.Fingerprint.ContentHash
+		v, ok := byHash[key]
+		if !ok {
+			sim, err := strconv.ParseUint(f.Fingerprint.SimHash, 16, 64)
+			if err != nil {
+				continue
+			}
+			v = &variant{
+				fileType: f.Fingerprint.FileType,
+				hash:     f.Fingerprint.ContentHash,
+				simHash:  sim,
+				lines:    f.Fingerprint.LineHashes,
+				repos:    map[int64]bool{},
+			}
+			byHash[key] = v
+			variants = append(variants, v)
+		}
+		v.files = append(v.files, f)
+		v.repos[f.RepoID] = true
+	}
+
+	sort.Slice(variants, func(i, j int) bool {
+		a, b := variants[i], variants[j]
+		if len(a.files) != len(b.files) {
+			return len(a.files) > len(b.files)
+		}
+		if len(a.repos) != len(b.repos) {
+			return len(a.repos) > len(b.repos)
+		}
+		return a.hash < b.hash
+	})
+
+	clustersByType := map[string][]*cluster{}
+	for _, v := range variants {
+		var home *cluster
+		for _, c := range clustersByType[v.fileType] {
+			if related(c.leader, v) {
+				home = c
+				break
+			}
+		}
+		if home == nil {
+			home = &cluster{leader: v}
+			clustersByType[v.fileType] = append(clustersByType[v.fileType], home)
+		}
+		home.variants = append(home.variants, v)
+	}
+
+	var result []Member
+	for _, clusters := range clustersByType {
+		for _, c := range clusters {
+			result = append(result, members(c)...)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.FileType != b.FileType {
+			return a.FileType < b.FileType
+		}
+		if a.ClusterID != b.ClusterID {
+			return a.ClusterID < b.ClusterID
+		}
+		if a.RepoID != b.RepoID {
+			return a.RepoID < b.RepoID
+		}
+		return a.Path < b.Path
+	})
+	return result
+}
+
+func related(leader, v *variant) bool {
+	return bits.OnesCount64(leader.simHash^v.simHash) <= MaxDistance && jaccard(leader.lines, v.lines) >= MinSimilarity
+}
+
+func members(c *cluster) []Member {
+	size := 0
+	repos := map[int64]bool{}
+	for _, v := range c.variants {
+		size += len(v.files)
+		for id := range v.repos {
+			repos[id] = true
+		}
+	}
+	if size < 2 {
+		return nil
+	}
+
+	var result []Member
+	for _, v := range c.variants {
+		distance := bits.OnesCount64(c.leader.simHash ^ v.simHash)
+		divergence := math.Round((1-jaccard(c.leader.lines, v.lines))*1000) / 1000
+		for _, f := range v.files {
+			result = append(result, Member{
+				RepoID:       f.RepoID,
+				Path:         f.Fingerprint.Path,
+				FileType:     v.fileType,
+				ContentHash:  v.hash,
+				ClusterID:    c.leader.hash,
+				ClusterSize:  size,
+				ClusterRepos: len(repos),
+				Variants:     len(c.variants),
+				IsCanonical:  v == c.leader,
+				Distance:     distance,
+				Divergence:   divergence,
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package similarity_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/similarity"
+)
+
+func TestSimilarity(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Likhet og slektskap mellom filer")
+}
+
+const template = `FROM gcr.io/distroless/java21-debian12
+WORKDIR /app
+ENV TZ="Europe/Oslo"
+ENV JAVA_OPTS="-XX:MaxRAMPercentage=75"
+COPY build/libs/app.jar app.jar
+COPY build/libs/lib/ lib/
+USER nonroot
+EXPOSE 8080
+LABEL org.opencontainers.image.source=https://github.com/navikt/%s
+CMD ["app.jar"]
+`
+
+func file(repoID int64, repoName, path, content string) similarity.File {
+	fp, ok := similarity.Fingerprint(similarity.FileTypeDockerfile, path, content, repoName)
+	Expect(ok).To(BeTrue())
+	return similarity.File{RepoID: repoID, Fingerprint: fp}
+}
+
+func fromTemplate(repoName string) string {
+	return fmt.Sprintf(template, repoName)
+}
+
+var _ = Describe("Normalize og Fingerprint", func() {
+	It("ignorerer kommentarer, mellomrom, store/små instruksjoner og repo-navnet", func() {
+		a, _ := similarity.Fingerprint(similarity.FileTypeDockerfile, "Dockerfile", fromTemplate("minside-api"), "minside-api")
+		copied := "# Bygget fra malen\n\n" + strings.Replace(fromTemplate("dagpenger"), "WORKDIR /app", "workdir   /app  ", 1)
+		b, _ := similarity.Fingerprint(similarity.FileTypeDockerfile, "Dockerfile", copied, "dagpenger")
+		Expect(a.ContentHash).To(Equal(b.ContentHash))
+		Expect(a.SimHash).To(Equal(b.SimHash))
+		Expect(a.SimHash).To(HaveLen(16))
+		Expect(a.LineCount).To(Equal(10))
+	})
+
+	It("slår sammen linjer som fortsetter med backslash", func() {
+		Expect(similarity.Normalize(similarity.FileTypeDockerfile, "run apt-get update && \\\n    apt-get install -y curl\n", "")).
+			To(Equal([]string{"RUN apt-get update && apt-get install -y curl"}))
+	})
+
+	It("tar med Dockerfiles og workflows fra repoet", func() {
+		entry := &models.RepoEntry{
+			Repo: models.RepoMeta{Name: "app"},
+			Files: map[string][]models.FileEntry{
+				"dockerfile": {{Path: "Dockerfile", Content: "FROM alpine"}},
+				"go.mod":     {{Path: "go.mod", Content: "module x"}},
+			},
+			CIConfig: []models.FileEntry{
+				{Path: ".github/workflows/build.yml", Content: "on: push\njobs: {}\n"},
+				{Path: ".github/workflows/tom.yml", Content: "# bare kommentar\n"},
+			},
+		}
+		fps := similarity.Fingerprints(entry)
+		Expect(fps).To(HaveLen(2))
+		Expect(fps[0].FileType).To(Equal(similarity.FileTypeDockerfile))
+		Expect(fps[1].FileType).To(Equal(similarity.FileTypeWorkflow))
+		Expect(fps[1].Path).To(Equal(".github/workflows/build.yml"))
+	})
+})
+
+var _ = Describe("Cluster", func() {
+	It("grupperer kopier av samme mal og måler avstand til vanligste variant", func() {
+		drifted := fromTemplate("vedtak") + "HEALTHCHECK CMD curl -f http://localhost:8080/isalive\n"
+		files := []similarity.File{
+			file(1, "minside-api", "Dockerfile", fromTemplate("minside-api")),
+			file(2, "dagpenger", "Dockerfile", fromTemplate("dagpenger")),
+			file(3, "vedtak", "Dockerfile", drifted),
+			file(4, "frontend", "Dockerfile", "FROM node:20\nWORKDIR /usr/src/app\nCOPY package*.json ./\nRUN npm ci\nCOPY . .\nCMD [\"npm\", \"start\"]\n"),
+		}
+
+		members := similarity.Cluster(files)
+		Expect(members).To(HaveLen(3))
+
+		canonical := files[0].Fingerprint.ContentHash
+		for _, m := range members {
+			Expect(m.ClusterID).To(Equal(canonical))
+			Expect(m.ClusterSize).To(Equal(3))
+			Expect(m.ClusterRepos).To(Equal(3))
+			Expect(m.Variants).To(Equal(2))
+		}
+		Expect(members[0].RepoID).To(Equal(int64(1)))
+		Expect(members[0].IsCanonical).To(BeTrue())
+		Expect(members[0].Divergence).To(BeZero())
+		Expect(members[2].RepoID).To(Equal(int64(3)))
+		Expect(members[2].IsCanonical).To(BeFalse())
+		Expect(members[2].Divergence).To(BeNumerically("~", 0.091, 0.001))
+	})
+
+	It("holder filtyper og urelaterte filer fra hverandre", func() {
+		wf, _ := similarity.Fingerprint(similarity.FileTypeWorkflow, ".github/workflows/x.yml", "FROM alpine\nRUN true\n", "")
+		files := []similarity.File{
+			file(1, "", "Dockerfile", "FROM alpine\nRUN true\n"),
+			{RepoID: 2, Fingerprint: wf},
+			file(3, "", "Dockerfile", "FROM golang:1.22\nRUN go build ./...\n"),
+		}
+		Expect(similarity.Cluster(files)).To(BeEmpty())
+	})
+})
@@ -14,27 +14,32 @@ import (
 const insertOrUpdateDockerfile = `-- name: InsertOrUpdateDockerfile :one
 INSERT INTO dockerfiles (
   repo_id, hentet_dato, full_name, path, content,
-  base_image, base_tag, uses_latest_tag,
+  base_image, base_tag, raw_image, registry, is_digest_pinned, uses_latest_tag,
   has_user_instruction, has_copy_sensitive, has_package_installs,
   uses_multistage, has_healthcheck, uses_add_instruction,
   has_label_metadata, has_expose, has_entrypoint_or_cmd,
   installs_curl_or_wget, installs_build_tools, has_apt_get_clean,
-  world_writable, has_secrets_in_env_or_arg
+  world_writable, has_secrets_in_env_or_arg,
+  final_stage_index, final_runs_as_root, final_installs_build_tools
 )
 VALUES (
   $1, $2, $3, $4, $5,
-  $6, $7, $8,
-  $9, $10, $11,
+  $6, $7, $8, $9, $10, $11,
   $12, $13, $14,
   $15, $16, $17,
   $18, $19, $20,
-  $21, $22
+  $21, $22, $23,
+  $24, $25,
+  $26, $27, $28
 )
 ON CONFLICT (repo_id, hentet_dato, path) DO UPDATE SET
   full_name = EXCLUDED.full_name,
   content = EXCLUDED.content,
   base_image = EXCLUDED.base_image,
   base_tag = EXCLUDED.base_tag,
+  raw_image = EXCLUDED.raw_image,
+  registry = EXCLUDED.registry,
+  is_digest_pinned = EXCLUDED.is_digest_pinned,
   uses_latest_tag = EXCLUDED.uses_latest_tag,
   has_user_instruction = EXCLUDED.has_user_instruction,
   has_copy_sensitive = EXCLUDED.has_copy_sensitive,
@@ -49,33 +54,42 @@ ON CONFLICT (repo_id, hentet_dato, path) DO UPDATE SET
   installs_build_tools = EXCLUDED.installs_build_tools,
   has_apt_get_clean = EXCLUDED.has_apt_get_clean,
   world_writable = EXCLUDED.world_writable,
-  has_secrets_in_env_or_arg = EXCLUDED.has_secrets_in_env_or_arg
+  has_secrets_in_env_or_arg = EXCLUDED.has_secrets_in_env_or_arg,
+  final_stage_index = EXCLUDED.final_stage_index,
+  final_runs_as_root = EXCLUDED.final_runs_as_root,
+  final_installs_build_tools = EXCLUDED.final_installs_build_tools
 RETURNING id
 `
 
 type InsertOrUpdateDockerfileParams struct {
-	RepoID               int64
-	HentetDato           time.Time
-	FullName             string
-	Path                 string
-	Content              string
-	BaseImage            sql.NullString
-	BaseTag              sql.NullString
-	UsesLatestTag        sql.NullBool
-	HasUserInstruction   sql.NullBool
-	HasCopySensitive     sql.NullBool
-	HasPackageInstalls   sql.NullBool
-	UsesMultistage       sql.NullBool
-	HasHealthcheck       sql.NullBool
-	UsesAddInstruction   sql.NullBool
-	HasLabelMetadata     sql.NullBool
-	HasExpose            sql.NullBool
-	HasEntrypointOrCmd   sql.NullBool
-	InstallsCurlOrWget   sql.NullBool
-	InstallsBuildTools   sql.NullBool
-	HasAptGetClean       sql.NullBool
-	WorldWritable        sql.NullBool
-	HasSecretsInEnvOrArg sql.NullBool
+	RepoID                  int64
+	HentetDato              time.Time
+	FullName                string
+	Path                    string
+	Content                 string
+	BaseImage               sql.NullString
+	BaseTag                 sql.NullString
+	RawImage                sql.NullString
+	Registry                sql.NullString
+	IsDigestPinned          sql.NullBool
+	UsesLatestTag           sql.NullBool
+	HasUserInstruction      sql.NullBool
+	HasCopySensitive        sql.NullBool
+	HasPackageInstalls      sql.NullBool
+	UsesMultistage          sql.NullBool
+	HasHealthcheck          sql.NullBool
+	UsesAddInstruction      sql.NullBool
+	HasLabelMetadata        sql.NullBool
+	HasExpose               sql.NullBool
+	HasEntrypointOrCmd      sql.NullBool
+	InstallsCurlOrWget      sql.NullBool
+	InstallsBuildTools      sql.NullBool
+	HasAptGetClean          sql.NullBool
+	WorldWritable           sql.NullBool
+	HasSecretsInEnvOrArg    sql.NullBool
+	FinalStageIndex         sql.NullInt32
+	FinalRunsAsRoot         sql.NullBool
+	FinalInstallsBuildTools sql.NullBool
 }
 
 func (q *Queries) InsertOrUpdateDockerfile(ctx context.Context, arg InsertOrUpdateDockerfileParams) (int32, error) {
@@ -87,6 +101,9 @@ func (q *Queries) InsertOrUpdateDockerfile(ctx context.Context, arg InsertOrUpda
 		arg.Content,
 		arg.BaseImage,
 		arg.BaseTag,
+		arg.RawImage,
+		arg.Registry,
+		arg.IsDigestPinned,
 		arg.UsesLatestTag,
 		arg.HasUserInstruction,
 		arg.HasCopySensitive,
@@ -102,8 +119,110 @@ func (q *Queries) InsertOrUpdateDockerfile(ctx context.Context, arg InsertOrUpda
 		arg.HasAptGetClean,
 		arg.WorldWritable,
 		arg.HasSecretsInEnvOrArg,
+		arg.FinalStageIndex,
+		arg.FinalRunsAsRoot,
+		arg.FinalInstallsBuildTools,
 	)
 	var id int32
 	err := row.Scan(&id)
 	return id, err
 }
+
+const insertOrUpdateDockerfileStage = `-- name: InsertOrUpdateDockerfileStage :exec
+INSERT INTO dockerfile_stages (
+  repo_id, hentet_dato, path, stage_index,
+  base_image, base_tag, raw_image, registry, is_digest_pinned,
+  alias, base_stage, user_name, runs_as_root,
+  has_package_installs, installs_build_tools, installs_curl_or_wget,
+  copy_from, exposed_ports, entrypoint, cmd, is_final,
+  image_family, image_version, eol_date, eol_status
+) VALUES (
+  $1, $2, $3, $4,
+  $5, $6, $7, $8, $9,
+  $10, $11, $12, $13,
+  $14, $15, $16,
+  $17, $18, $19, $20, $21,
+  $22, $23, $24, $25
+)
+ON CONFLICT (repo_id, hentet_dato, path, stage_index) DO UPDATE SET
+  base_image = EXCLUDED.base_image,
+  base_tag = EXCLUDED.base_tag,
+  raw_image = EXCLUDED.raw_image,
+  registry = EXCLUDED.registry,
+  is_digest_pinned = EXCLUDED.is_digest_pinned,
+  alias = EXCLUDED.alias,
+  base_stage = EXCLUDED.base_stage,
+  user_name = EXCLUDED.user_name,
+  runs_as_root = EXCLUDED.runs_as_root,
+  has_package_installs = EXCLUDED.has_package_installs,
+  installs_build_tools = EXCLUDED.installs_build_tools,
+  installs_curl_or_wget = EXCLUDED.installs_curl_or_wget,
+  copy_from = EXCLUDED.copy_from,
+  exposed_ports = EXCLUDED.exposed_ports,
+  entrypoint = EXCLUDED.entrypoint,
+  cmd = EXCLUDED.cmd,
+  is_final = EXCLUDED.is_final,
+  image_family = EXCLUDED.image_family,
+  image_version = EXCLUDED.image_version,
+  eol_date = EXCLUDED.eol_date,
+  eol_status = EXCLUDED.eol_status
+`
+
+type InsertOrUpdateDockerfileStageParams struct {
+	RepoID             int64
+	HentetDato         time.Time
+	Path               string
+	StageIndex         int32
+	BaseImage          string
+	BaseTag            string
+	RawImage           sql.NullString
+	Registry           string
+	IsDigestPinned     bool
+	Alias              sql.NullString
+	BaseStage          sql.NullString
+	UserName           sql.NullString
+	RunsAsRoot         sql.NullBool
+	HasPackageInstalls bool
+	InstallsBuildTools bool
+	InstallsCurlOrWget bool
+	CopyFrom           string
+	ExposedPorts       string
+	Entrypoint         sql.NullString
+	Cmd                sql.NullString
+	IsFinal            bool
+	ImageFamily        sql.NullString
+	ImageVersion       sql.NullString
+	EolDate            sql.NullTime
+	EolStatus          string
+}
+
+func (q *Queries) InsertOrUpdateDockerfileStage(ctx context.Context, arg InsertOrUpdateDockerfileStageParams) error {
+	_, err := q.db.ExecContext(ctx, insertOrUpdateDockerfileStage,
+		arg.RepoID,
+		arg.HentetDato,
+		arg.Path,
+		arg.StageIndex,
+		arg.BaseImage,
+		arg.BaseTag,
+		arg.RawImage,
+		arg.Registry,
+		arg.IsDigestPinned,
+		arg.Alias,
+		arg.BaseStage,
+		arg.UserName,
+		arg.RunsAsRoot,
+		arg.HasPackageInstalls,
+		arg.InstallsBuildTools,
+		arg.InstallsCurlOrWget,
+		arg.CopyFrom,
+		arg.ExposedPorts,
+		arg.Entrypoint,
+		arg.Cmd,
+		arg.IsFinal,
+		arg.ImageFamily,
+		arg.ImageVersion,
+		arg.EolDate,
+		arg.EolStatus,
+	)
+	return err
+}
@@ -9,6 +9,34 @@ import (
 	"time"
 )
 
+type BaseImageDigest struct {
+	ID            int32
+	RepoID        int64
+	HentetDato    time.Time
+	Reference     string
+	Registry      string
+	PinnedDigest  sql.NullString
+	CurrentDigest sql.NullString
+	ImageCreated  sql.NullTime
+	DigestDiffers bool
+	Error         sql.NullString
+}
+
+type BaseImageEolReport struct {
+	RepoID       int64
+	HentetDato   time.Time
+	FullName     string
+	Path         string
+	StageIndex   int32
+	IsFinal      bool
+	BaseImage    string
+	BaseTag      string
+	ImageFamily  sql.NullString
+	ImageVersion sql.NullString
+	EolDate      sql.NullTime
+	EolStatus    string
+}
+
 type CiConfig struct {
 	ID         int32
 	RepoID     int64
@@ -17,66 +45,336 @@ type CiConfig struct {
 	Content    string
 }
 
+type CodeownersCoverage struct {
+	ID               int32
+	RepoID           int64
+	HentetDato       time.Time
+	CodeownersPath   sql.NullString
+	RuleCount        int32
+	InvalidRuleCount int32
+	OwnedPercent     float32
+}
+
+type CodeownersPath struct {
+	ID         int32
+	RepoID     int64
+	HentetDato time.Time
+	Path       string
+	IsDir      bool
+	Owners     string
+	RuleLine   sql.NullInt32
+}
+
+type CodeownersRule struct {
+	ID            int32
+	RepoID        int64
+	HentetDato    time.Time
+	Path          string
+	Line          int32
+	Pattern       string
+	Owners        string
+	UnknownOwners sql.NullString
+	Error         sql.NullString
+}
+
+type ComposeService struct {
+	ID             int32
+	RepoID         int64
+	HentetDato     time.Time
+	Path           string
+	Service        string
+	Image          sql.NullString
+	ImageTag       sql.NullString
+	RawImage       sql.NullString
+	Registry       sql.NullString
+	IsDigestPinned bool
+	Digest         sql.NullString
+	BuildContext   sql.NullString
+	Dockerfile     sql.NullString
+	Ports          sql.NullString
+	Privileged     bool
+	NetworkMode    sql.NullString
+	EnvFiles       sql.NullString
+}
+
+type ComposeVolume struct {
+	ID         int32
+	RepoID     int64
+	HentetDato time.Time
+	Path       string
+	Service    string
+	MountType  string
+	Source     sql.NullString
+	Target     string
+	ReadOnly   bool
+}
+
+type DeployManifest struct {
+	ID                     int32
+	RepoID                 int64
+	HentetDato             time.Time
+	Path                   string
+	Kind                   string
+	Name                   string
+	Namespace              sql.NullString
+	Images                 sql.NullString
+	ReplicasMin            sql.NullInt32
+	ReplicasMax            sql.NullInt32
+	CpuRequest             sql.NullString
+	CpuLimit               sql.NullString
+	MemoryRequest          sql.NullString
+	MemoryLimit            sql.NullString
+	IngressHosts           sql.NullString
+	InboundAccess          sql.NullString
+	OutboundAccess         sql.NullString
+	LivenessProbe          sql.NullString
+	ReadinessProbe         sql.NullString
+	RunAsNonRoot           sql.NullBool
+	ReadOnlyRootFilesystem sql.NullBool
+}
+
+type DirectDependency struct {
+	ID                int32
+	RepoID            int64
+	HentetDato        time.Time
+	Ecosystem         string
+	Name              string
+	VersionConstraint sql.NullString
+	Scope             string
+	Kind              string
+	SourcePath        string
+}
+
 type Dockerfile struct {
-	ID                   int32
-	RepoID               int64
-	HentetDato           time.Time
-	FullName             string
-	Path                 string
-	Content              string
-	BaseImage            sql.NullString
-	BaseTag              sql.NullString
-	UsesLatestTag        sql.NullBool
-	HasUserInstruction   sql.NullBool
-	HasCopySensitive     sql.NullBool
-	HasPackageInstalls   sql.NullBool
-	UsesMultistage       sql.NullBool
-	HasHealthcheck       sql.NullBool
-	UsesAddInstruction   sql.NullBool
-	HasLabelMetadata     sql.NullBool
-	HasExpose            sql.NullBool
-	HasEntrypointOrCmd   sql.NullBool
-	InstallsCurlOrWget   sql.NullBool
-	InstallsBuildTools   sql.NullBool
-	HasAptGetClean       sql.NullBool
-	WorldWritable        sql.NullBool
-	HasSecretsInEnvOrArg sql.NullBool
+	ID                      int32
+	RepoID                  int64
+	HentetDato              time.Time
+	FullName                string
+	Path                    string
+	Content                 string
+	BaseImage               sql.NullString
+	BaseTag                 sql.NullString
+	RawImage                sql.NullString
+	Registry                sql.NullString
+	IsDigestPinned          sql.NullBool
+	UsesLatestTag           sql.NullBool
+	HasUserInstruction      sql.NullBool
+	HasCopySensitive        sql.NullBool
+	HasPackageInstalls      sql.NullBool
+	UsesMultistage          sql.NullBool
+	HasHealthcheck          sql.NullBool
+	UsesAddInstruction      sql.NullBool
+	HasLabelMetadata        sql.NullBool
+	HasExpose               sql.NullBool
+	HasEntrypointOrCmd      sql.NullBool
+	InstallsCurlOrWget      sql.NullBool
+	InstallsBuildTools      sql.NullBool
+	HasAptGetClean          sql.NullBool
+	WorldWritable           sql.NullBool
+	HasSecretsInEnvOrArg    sql.NullBool
+	FinalStageIndex         sql.NullInt32
+	FinalRunsAsRoot         sql.NullBool
+	FinalInstallsBuildTools sql.NullBool
+}
+
+type DockerfileFinding struct {
+	ID          int32
+	RepoID      int64
+	HentetDato  time.Time
+	Path        string
+	RuleID      string
+	Severity    string
+	Description string
+	StartLine   int32
+	EndLine     int32
+}
+
+type DockerfileStage struct {
+	ID                 int32
+	RepoID             int64
+	HentetDato         time.Time
+	Path               string
+	StageIndex         int32
+	BaseImage          string
+	BaseTag            string
+	RawImage           sql.NullString
+	Registry           string
+	IsDigestPinned     bool
+	Alias              sql.NullString
+	BaseStage          sql.NullString
+	UserName           sql.NullString
+	RunsAsRoot         sql.NullBool
+	HasPackageInstalls bool
+	InstallsBuildTools bool
+	InstallsCurlOrWget bool
+	CopyFrom           string
+	ExposedPorts       string
+	Entrypoint         sql.NullString
+	Cmd                sql.NullString
+	IsFinal            bool
+	ImageFamily        sql.NullString
+	ImageVersion       sql.NullString
+	EolDate            sql.NullTime
+	EolStatus          string
+}
+
+type EcosystemCoverage struct {
+	ID         int32
+	RepoID     int64
+	HentetDato time.Time
+	Ecosystem  string
+	Manifests  string
+	CoveredBy  string
+	IsCovered  bool
+}
+
+type FileFingerprint struct {
+	ID          int32
+	RepoID      int64
+	HentetDato  time.Time
+	Path        string
+	FileType    string
+	ContentHash string
+	Simhash     string
+	LineCount   int32
+}
+
+type FileLineage struct {
+	ID              int32
+	RepoID          int64
+	HentetDato      time.Time
+	Path            string
+	FileType        string
+	ContentHash     string
+	ClusterID       string
+	ClusterSize     int32
+	ClusterRepos    int32
+	VariantCount    int32
+	IsCanonical     bool
+	SimhashDistance int32
+	Divergence      float32
+}
+
+type ForkLagReport struct {
+	RepoID         int64
+	HentetDato     time.Time
+	FullName       string
+	ParentFullName sql.NullString
+	ParentInOrg    sql.NullBool
+	ForkAheadBy    sql.NullInt32
+	ForkBehindBy   sql.NullInt32
+	PushedAt       string
+}
+
+type IacModule struct {
+	ID         int32
+	RepoID     int64
+	HentetDato time.Time
+	Path       string
+	Tool       string
+	Kind       string
+	Name       string
+	Source     sql.NullString
+	SourceType sql.NullString
+	Version    sql.NullString
+	Pinned     sql.NullBool
+}
+
+type ReadmeMetric struct {
+	ID              int32
+	RepoID          int64
+	HentetDato      time.Time
+	Length          int32
+	WordCount       int32
+	HeadingCount    int32
+	Headings        sql.NullString
+	HasInstallation bool
+	HasUsage        bool
+	HasContact      bool
+	HasOwnership    bool
+	BadgeCount      int32
+	Badges          sql.NullString
+	InternalLinks   int32
+	ExternalLinks   int32
+	CodeBlocks      int32
+	Language        string
+	IsTemplate      bool
+	TemplateMarkers sql.NullString
 }
 
 type Repo struct {
-	ID            int64
-	HentetDato    time.Time
-	Name          string
-	FullName      string
-	Description   string
-	Stars         int64
-	Forks         int64
-	Archived      bool
-	Private       bool
-	IsFork        bool
-	Language      string
-	SizeMb        float32
-	UpdatedAt     string
-	PushedAt      string
-	CreatedAt     string
-	HtmlUrl       string
-	Topics        string
-	Visibility    string
-	License       string
-	OpenIssues    int64
-	LanguagesUrl  string
-	ReadmeContent sql.NullString
-	HasSecurityMd bool
-	HasDependabot bool
-	HasCodeql     bool
+	ID               int64
+	HentetDato       time.Time
+	Name             string
+	FullName         string
+	Description      string
+	Stars            int64
+	Forks            int64
+	Archived         bool
+	Private          bool
+	IsFork           bool
+	Language         string
+	SizeMb           float32
+	UpdatedAt        string
+	PushedAt         string
+	CreatedAt        string
+	HtmlUrl          string
+	Topics           string
+	Visibility       string
+	License          string
+	OpenIssues       int64
+	LanguagesUrl     string
+	ReadmeContent    sql.NullString
+	HasSecurityMd    bool
+	HasDependabot    bool
+	HasCodeql        bool
+	ParentFullName   sql.NullString
+	ParentInOrg      sql.NullBool
+	TemplateFullName sql.NullString
+	TemplateInOrg    sql.NullBool
+	ForkAheadBy      sql.NullInt32
+	ForkBehindBy     sql.NullInt32
+}
+
+type RepoFramework struct {
+	ID         int32
+	RepoID     int64
+	HentetDato time.Time
+	Framework  string
+	Kind       string
+	Version    sql.NullString
+	Confidence float32
+	Evidence   sql.NullString
 }
 
 type RepoLanguage struct {
+	ID           int32
+	RepoID       int64
+	HentetDato   time.Time
+	Language     string
+	Bytes        int64
+	Share        float32
+	LanguageType string
+}
+
+type RepoLifecycleEvent struct {
+	ID          int32
+	RepoID      int64
+	HentetDato  time.Time
+	EventType   string
+	OldFullName sql.NullString
+	NewFullName sql.NullString
+}
+
+type RuntimeVersion struct {
 	ID         int32
 	RepoID     int64
 	HentetDato time.Time
-	Language   string
-	Bytes      int64
+	Runtime    string
+	Version    sql.NullString
+	RawValue   string
+	SourcePath string
+	Setting    string
 }
 
 type SbomGithubPackage struct {
@@ -87,4 +385,97 @@ type SbomGithubPackage struct {
 	Version    sql.NullString
 	License    sql.NullString
 	Purl       sql.NullString
+	Source     string
+}
+
+type SecretFinding struct {
+	ID          int32
+	RepoID      int64
+	HentetDato  time.Time
+	Path        string
+	RuleID      string
+	Description string
+	Line        int32
+	SecretHash  string
+}
+
+type SkippedArchivedRepo struct {
+	ID         int32
+	RepoID     int64
+	HentetDato time.Time
+	FullName   string
+}
+
+type SkippedFile struct {
+	ID         int32
+	RepoID     int64
+	HentetDato time.Time
+	Path       string
+	Reason     string
+	ByteSize   int64
+}
+
+type UpdateBotConfig struct {
+	ID         int32
+	RepoID     int64
+	HentetDato time.Time
+	Tool       string
+	Path       string
+	Ecosystem  string
+	Directory  string
+	Schedule   sql.NullString
+	Groups     string
+	AutoMerge  bool
+}
+
+type Workflow struct {
+	ID          int32
+	RepoID      int64
+	HentetDato  time.Time
+	Path        string
+	Name        sql.NullString
+	Triggers    string
+	Permissions sql.NullString
+	Secrets     string
+}
+
+type WorkflowAction struct {
+	ID         int32
+	RepoID     int64
+	HentetDato time.Time
+	Path       string
+	JobID      string
+	StepIndex  int32
+	Uses       string
+	Kind       string
+	Action     string
+	Ref        sql.NullString
+	RefType    sql.NullString
+	Line       int32
+}
+
+type WorkflowFinding struct {
+	ID          int32
+	RepoID      int64
+	HentetDato  time.Time
+	Path        string
+	RuleID      string
+	Severity    string
+	Description string
+	JobID       string
+	Line        int32
+}
+
+type WorkflowJob struct {
+	ID             int32
+	RepoID         int64
+	HentetDato     time.Time
+	Path           string
+	JobID          string
+	Name           sql.NullString
+	Line           int32
+	RunsOn         string
+	Permissions    sql.NullString
+	Uses           sql.NullString
+	SecretsInherit bool
 }
@@ -12,19 +12,23 @@ import (
 
 const insertOrUpdateRepoLanguage = `-- name: InsertOrUpdateRepoLanguage :exec
 INSERT INTO repo_languages (
-  repo_id, hentet_dato, language, bytes
+  repo_id, hentet_dato, language, bytes, share, language_type
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6
 )
 ON CONFLICT (repo_id, hentet_dato, language) DO UPDATE SET
-  bytes = EXCLUDED.bytes
+  bytes = EXCLUDED.bytes,
+  share = EXCLUDED.share,
+  language_type = EXCLUDED.language_type
 `
 
 type InsertOrUpdateRepoLanguageParams struct {
-	RepoID     int64
-	HentetDato time.Time
-	Language   string
-	Bytes      int64
+	RepoID       int64
+	HentetDato   time.Time
+	Language     string
+	Bytes        int64
+	Share        float32
+	LanguageType string
 }
 
 func (q *Queries) InsertOrUpdateRepoLanguage(ctx context.Context, arg InsertOrUpdateRepoLanguageParams) error {
@@ -33,6 +37,8 @@ func (q *Queries) InsertOrUpdateRepoLanguage(ctx context.Context, arg InsertOrUp
 		arg.HentetDato,
 		arg.Language,
 		arg.Bytes,
+		arg.Share,
+		arg.LanguageType,
 	)
 	return err
 }
@@ -17,13 +17,17 @@ INSERT INTO repos (
   name, full_name, description, stars, forks, archived, private, is_fork,
   language, size_mb, updated_at, pushed_at, created_at, html_url, topics,
   visibility, license, open_issues, languages_url,
-  has_security_md, has_dependabot, has_codeql, readme_content
+  has_security_md, has_dependabot, has_codeql, readme_content,
+  parent_full_name, parent_in_org, template_full_name, template_in_org,
+  fork_ahead_by, fork_behind_by
 ) VALUES (
   $1, $2,
   $3, $4, $5, $6, $7, $8, $9, $10,
   $11, $12, $13, $14, $15, $16, $17,
   $18, $19, $20, $21,
-  $22, $23, $24, $25
+  $22, $23, $24, $25,
+  $26, $27, $28, $29,
+  $30, $31
 )
 ON CONFLICT (id, hentet_dato) DO UPDATE SET
   name = EXCLUDED.name,
@@ -48,35 +52,47 @@ ON CONFLICT (id, hentet_dato) DO UPDATE SET
   has_security_md = EXCLUDED.has_security_md,
   has_dependabot = EXCLUDED.has_dependabot,
   has_codeql = EXCLUDED.has_codeql,
-  readme_content = EXCLUDED.readme_content
+  readme_content = EXCLUDED.readme_content,
+  parent_full_name = EXCLUDED.parent_full_name,
+  parent_in_org = EXCLUDED.parent_in_org,
+  template_full_name = EXCLUDED.template_full_name,
+  template_in_org = EXCLUDED.template_in_org,
+  fork_ahead_by = EXCLUDED.fork_ahead_by,
+  fork_behind_by = EXCLUDED.fork_behind_by
 `
 
 type InsertOrUpdateRepoParams struct {
-	ID            int64
-	HentetDato    time.Time
-	Name          string
-	FullName      string
-	Description   string
-	Stars         int64
-	Forks         int64
-	Archived      bool
-	Private       bool
-	IsFork        bool
-	Language      string
-	SizeMb        float32
-	UpdatedAt     string
-	PushedAt      string
-	CreatedAt     string
-	HtmlUrl       string
-	Topics        string
-	Visibility    string
-	License       string
-	OpenIssues    int64
-	LanguagesUrl  string
-	HasSecurityMd bool
-	HasDependabot bool
-	HasCodeql     bool
-	ReadmeContent sql.NullString
+	ID               int64
+	HentetDato       time.Time
+	Name             string
+	FullName         string
+	Description      string
+	Stars            int64
+	Forks            int64
+	Archived         bool
+	Private          bool
+	IsFork           bool
+	Language         string
+	SizeMb           float32
+	UpdatedAt        string
+	PushedAt         string
+	CreatedAt        string
+	HtmlUrl          string
+	Topics           string
+	Visibility       string
+	License          string
+	OpenIssues       int64
+	LanguagesUrl     string
+	HasSecurityMd    bool
+	HasDependabot    bool
+	HasCodeql        bool
+	ReadmeContent    sql.NullString
+	ParentFullName   sql.NullString
+	ParentInOrg      sql.NullBool
+	TemplateFullName sql.NullString
+	TemplateInOrg    sql.NullBool
+	ForkAheadBy      sql.NullInt32
+	ForkBehindBy     sql.NullInt32
 }
 
 func (q *Queries) InsertOrUpdateRepo(ctx context.Context, arg InsertOrUpdateRepoParams) error {
@@ -106,6 +122,53 @@ func (q *Queries) InsertOrUpdateRepo(ctx context.Context, arg InsertOrUpdateRepo
 		arg.HasDependabot,
 		arg.HasCodeql,
 		arg.ReadmeContent,
+		arg.ParentFullName,
+		arg.ParentInOrg,
+		arg.TemplateFullName,
+		arg.TemplateInOrg,
+		arg.ForkAheadBy,
+		arg.ForkBehindBy,
 	)
 	return err
 }
+
+const listReposInPreviousSnapshot = `-- name: ListReposInPreviousSnapshot :many
+SELECT id, full_name, archived
+FROM repos
+WHERE hentet_dato = (
+  SELECT MAX(hentet_dato) FROM repos WHERE hentet_dato < $1
+)
+`
+
+type ListReposInPreviousSnapshotRow struct {
+	ID       int64
+	FullName string
+	Archived bool
+}
+
+func (q *Queries) ListReposInPreviousSnapshot(ctx context.Context, hentetDato time.Time) ([]ListReposInPreviousSnapshotRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReposInPreviousSnapshot, hentetDato)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReposInPreviousSnapshotRow
+	for rows.Next() {
+		var i ListReposInPreviousSnapshotRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FullName,
+			&i.Archived,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -13,13 +13,14 @@ import (
 
 const insertOrUpdateGithubSBOM = `-- name: InsertOrUpdateGithubSBOM :exec
 INSERT INTO sbom_github_packages (
-  repo_id, hentet_dato, name, version, license, purl
+  repo_id, hentet_dato, name, version, license, purl, source
 ) VALUES (
-  $1, $2, $3, $4, $5, $6
+  $1, $2, $3, $4, $5, $6, $7
 )
 ON CONFLICT (repo_id, hentet_dato, name, version) DO UPDATE SET
   license = EXCLUDED.license,
-  purl = EXCLUDED.purl
+  purl = EXCLUDED.purl,
+  source = EXCLUDED.source
 `
 
 type InsertOrUpdateGithubSBOMParams struct {
@@ -29,6 +30,7 @@ type InsertOrUpdateGithubSBOMParams struct {
 	Version    sql.NullString
 	License    sql.NullString
 	Purl       sql.NullString
+	Source     string
 }
 
 func (q *Queries) InsertOrUpdateGithubSBOM(ctx context.Context, arg InsertOrUpdateGithubSBOMParams) error {
@@ -39,6 +41,7 @@ func (q *Queries) InsertOrUpdateGithubSBOM(ctx context.Context, arg InsertOrUpda
 		arg.Version,
 		arg.License,
 		arg.Purl,
+		arg.Source,
 	)
 	return err
 }
@@ -0,0 +1,327 @@
+package toolchain
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/parser"
+	"github.com/jonmartinstorm/reposnusern/internal/workflow"
+)
+
+const (
+	RuntimeGo     = "go"
+	RuntimeNode   = "node"
+	RuntimeJava   = "java"
+	RuntimePython = "python"
+)
+
+// Filene på toppnivå vi leser versjoner fra, i små bokstaver.
+// package.json og pom.xml hentes allerede for Renovate og SBOM, men står her for oversiktens skyld.
+var versionFiles = map[string]bool{
+	"go.mod":           true,
+	"package.json":     true,
+	".nvmrc":           true,
+	".node-version":    true,
+	"build.gradle":     true,
+	"build.gradle.kts": true,
+	"pom.xml":          true,
+	".python-version":  true,
+	"pyproject.toml":   true,
+}
+
+// IsVersionFile sier om et filnavn på toppnivå kan inneholde en runtime-versjon.
+func IsVersionFile(lowerName string) bool {
+	return versionFiles[lowerName]
+}
+
+var (
+	versionNumberPattern = regexp.MustCompile(`\d+(?:[._]\d+)*`)
+
+	goDirectivePattern  = regexp.MustCompile(`(?m)^go\s+(\S+)`)
+	goToolchainPattern  = regexp.MustCompile(`(?m)^toolchain\s+(\S+)`)
+	requiresPython      = regexp.MustCompile(`(?m)^requires-python\s*=\s*["']([^"']+)["']`)
+	poetryPython        = regexp.MustCompile(`(?m)^python\s*=\s*["']([^"']+)["']`)
+	gradleLanguageLevel = regexp.MustCompile(`JavaLanguageVersion\.of\(\s*["']?(\d+)["']?\s*\)`)
+	gradleJvmToolchain  = regexp.MustCompile(`jvmToolchain\(\s*(\d+)\s*\)`)
+	gradleCompatibility = regexp.MustCompile(`(sourceCompatibility|targetCompatibility)\s*=\s*(?:JavaVersion\.VERSION_([\d_]+)|["']?([\d.]+)["']?)`)
+	gradleJvmTarget     = regexp.MustCompile(`jvmTarget(?:\.set\(|\s*=)\s*(?:JvmTarget\.JVM_([\d_]+)|["']([\d.]+)["'])`)
+	mavenJavaSetting    = regexp.MustCompile(`<(maven\.compiler\.release|maven\.compiler\.source|maven\.compiler\.target|java\.version|release|jvmTarget)>\s*([^<\s]+)\s*</`)
+	mavenPropertyRef    = regexp.MustCompile(`^\$\{([^}]+)\}$`)
+
+	// Distroless og lignende har versjonen i navnet, f.eks. java21-debian12
+	versionedImageName     = regexp.MustCompile(`^(java|nodejs|python)(\d+)`)
+	versionedImageRuntimes = map[string]string{"java": RuntimeJava, "nodejs": RuntimeNode, "python": RuntimePython}
+)
+
+// Kodenavn for Node LTS-versjoner, brukt i .nvmrc og image-tagger
+var nodeCodenames = map[string]string{
+	"argon": "4", "boron": "6", "carbon": "8", "dubnium": "10", "erbium": "12",
+	"fermium": "14", "gallium": "16", "hydrogen": "18", "iron": "20", "jod": "22", "krypton": "24",
+}
+
+// Images der taggen er runtime-versjonen, nøklet på siste del av navnet
+var runtimeImages = map[string]string{
+	"golang":          RuntimeGo,
+	"node":            RuntimeNode,
+	"python":          RuntimePython,
+	"eclipse-temurin": RuntimeJava,
+	"temurin":         RuntimeJava,
+	"openjdk":         RuntimeJava,
+	"amazoncorretto":  RuntimeJava,
+	"zulu-openjdk":    RuntimeJava,
+	"sapmachine":      RuntimeJava,
+}
+
+// setup-actions og inputen som setter versjonen
+var setupActions = map[string]struct{ runtime, input string }{
+	"actions/setup-go":     {RuntimeGo, "go-version"},
+	"actions/setup-node":   {RuntimeNode, "node-version"},
+	"actions/setup-java":   {RuntimeJava, "java-version"},
+	"actions/setup-python": {RuntimePython, "python-version"},
+}
+
+// Versions samler alle deklarerte runtime-versjoner i repoet, med filen og innstillingen
+// de står i. Like rader fra samme fil slås sammen.
+func Versions(entry *models.RepoEntry) []models.RuntimeVersion {
+	c := &collector{seen: map[string]bool{}}
+
+	keys := make([]string, 0, len(entry.Files))
+	for k := range entry.Files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, f := range entry.Files[key] {
+			switch {
+			case key == "go.mod":
+				c.goMod(f)
+			case key == "package.json":
+				c.packageJSON(f)
+			case key == ".nvmrc", key == ".node-version":
+				c.lines(RuntimeNode, f, key)
+			case key == ".python-version":
+				c.lines(RuntimePython, f, key)
+			case key == "pyproject.toml":
+				c.pyproject(f)
+			case key == "build.gradle", key == "build.gradle.kts":
+				c.gradle(f)
+			case key == "pom.xml":
+				c.maven(f)
+			case strings.HasPrefix(key, "dockerfile"):
+				c.dockerfile(f)
+			}
+		}
+	}
+	for _, f := range entry.CIConfig {
+		c.workflow(f)
+	}
+
+	sort.SliceStable(c.result, func(i, j int) bool {
+		a, b := c.result[i], c.result[j]
+		if a.Runtime != b.Runtime {
+			return a.Runtime < b.Runtime
+		}
+		return a.Source < b.Source
+	})
+	return c.result
+}
+
+type collector struct {
+	result []models.RuntimeVersion
+	seen   map[string]bool
+}
+
+func (c *collector) add(runtime, raw, source, setting string) {
+	c.addVersion(runtime, Normalize(runtime, raw), raw, source, setting)
+}
+
+func (c *collector) addVersion(runtime, version, raw, source, setting string) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return
+	}
+	key := strings.Join([]string{runtime, source, setting, raw}, "|")
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.result = append(c.result, models.RuntimeVersion{
+		Runtime: runtime,
+		Version: version,
+		Raw:     raw,
+		Source:  source,
+		Setting: setting,
+	})
+}
+
+func (c *collector) goMod(f models.FileEntry) {
+	if m := goDirectivePattern.FindStringSubmatch(f.Content); m != nil {
+		c.add(RuntimeGo, m[1], f.Path, "go")
+	}
+	if m := goToolchainPattern.FindStringSubmatch(f.Content); m != nil {
+		c.add(RuntimeGo, m[1], f.Path, "toolchain")
+	}
+}
+
+func (c *collector) packageJSON(f models.FileEntry) {
+	var pkg struct {
+		Engines map[string]interface{} `json:"engines"`
+	}
+	if err := json.Unmarshal([]byte(f.Content), &pkg); err != nil {
+		return
+	}
+	if node, ok := pkg.Engines["node"].(string); ok {
+		c.add(RuntimeNode, node, f.Path, "engines.node")
+	}
+}
+
+// lines leser filer som .nvmrc og .python-version, der hver linje er en versjon.
+func (c *collector) lines(runtime string, f models.FileEntry, setting string) {
+	for _, line := range strings.Split(f.Content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		c.add(runtime, line, f.Path, setting)
+	}
+}
+
+func (c *collector) pyproject(f models.FileEntry) {
+	if m := requiresPython.FindStringSubmatch(f.Content); m != nil {
+		c.add(RuntimePython, m[1], f.Path, "requires-python")
+	}
+	// Poetry setter python som en avhengighet under [tool.poetry.dependencies]
+	if _, section, ok := strings.Cut(f.Content, "[tool.poetry.dependencies]"); ok {
+		section, _, _ = strings.Cut(section, "\n[")
+		if m := poetryPython.FindStringSubmatch(section); m != nil {
+			c.add(RuntimePython, m[1], f.Path, "tool.poetry.dependencies.python")
+		}
+	}
+}
+
+func (c *collector) gradle(f models.FileEntry) {
+	for _, m := range gradleLanguageLevel.FindAllStringSubmatch(f.Content, -1) {
+		c.add(RuntimeJava, m[1], f.Path, "java.toolchain.languageVersion")
+	}
+	for _, m := range gradleJvmToolchain.FindAllStringSubmatch(f.Content, -1) {
+		c.add(RuntimeJava, m[1], f.Path, "jvmToolchain")
+	}
+	for _, m := range gradleCompatibility.FindAllStringSubmatch(f.Content, -1) {
+		c.add(RuntimeJava, firstNonEmpty(m[2], m[3]), f.Path, m[1])
+	}
+	for _, m := range gradleJvmTarget.FindAllStringSubmatch(f.Content, -1) {
+		c.add(RuntimeJava, firstNonEmpty(m[1], m[2]), f.Path, "jvmTarget")
+	}
+}
+
+func (c *collector) maven(f models.FileEntry) {
+	for _, m := range mavenJavaSetting.FindAllStringSubmatch(f.Content, -1) {
+		value := m[2]
+		// <release>${java.version}</release> peker på en property i samme fil
+		if ref := mavenPropertyRef.FindStringSubmatch(value); ref != nil {
+			prop := regexp.MustCompile(`<` + regexp.QuoteMeta(ref[1]) + `>\s*([^<\s]+)\s*</`).FindStringSubmatch(f.Content)
+			if prop == nil {
+				continue
+			}
+			value = prop[1]
+		}
+		c.add(RuntimeJava, value, f.Path, m[1])
+	}
+}
+
+func (c *collector) dockerfile(f models.FileEntry) {
+	_, stages := parser.ParseDockerfile(f.Content)
+	for _, stage := range stages {
+		if stage.BaseStage != "" || stage.BaseImage == parser.UnresolvedImage {
+			continue
+		}
+		name := path.Base(stage.BaseImage)
+		image := stage.BaseImage
+		if stage.BaseTag != "" {
+			image += ":" + stage.BaseTag
+		}
+		if m := versionedImageName.FindStringSubmatch(name); m != nil {
+			runtime := versionedImageRuntimes[m[1]]
+			c.addVersion(runtime, Normalize(runtime, m[2]), image, f.Path, "FROM")
+			continue
+		}
+		if runtime, ok := runtimeImages[name]; ok {
+			// Taggen kan ha suffiks som -alpine eller -jre; latest gir ingen versjon
+			c.addVersion(runtime, Normalize(runtime, strings.SplitN(stage.BaseTag, "-", 2)[0]), image, f.Path, "FROM")
+		}
+	}
+}
+
+func (c *collector) workflow(f models.FileEntry) {
+	wf, err := workflow.Parse(f.Content)
+	if err != nil {
+		return
+	}
+	for _, job := range wf.Jobs {
+		for _, step := range job.Steps {
+			if step.Uses == nil {
+				continue
+			}
+			action := step.Uses.Action()
+			setup, ok := setupActions[action]
+			if !ok {
+				continue
+			}
+			value := step.With[setup.input]
+			// Matrise-uttrykk kan ikke løses opp uten å kjøre workflowen
+			if strings.Contains(value, "${{") {
+				continue
+			}
+			for _, v := range strings.Fields(value) {
+				c.add(setup.runtime, v, f.Path, action)
+			}
+		}
+	}
+}
+
+// Normalize gjør en deklarert versjon om til formen vi sammenligner på:
+// major for Java og Node, major.minor for Go og Python. For versjonsområder som ">=18 <21"
+// brukes den første versjonen. Returnerer tom streng når det ikke står noen versjon, f.eks. "lts/*".
+func Normalize(runtime, raw string) string {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	if runtime == RuntimeNode {
+		if v, ok := nodeCodenames[strings.TrimPrefix(value, "lts/")]; ok {
+			return v
+		}
+	}
+	number := versionNumberPattern.FindString(value)
+	if number == "" {
+		return ""
+	}
+	parts := strings.FieldsFunc(number, func(r rune) bool { return r == '.' || r == '_' })
+
+	switch runtime {
+	case RuntimeJava:
+		// 1.8 og VERSION_1_8 er Java 8
+		if parts[0] == "1" && len(parts) > 1 {
+			return parts[1]
+		}
+		return parts[0]
+	case RuntimeNode:
+		return parts[0]
+	default:
+		if len(parts) > 1 {
+			return parts[0] + "." + parts[1]
+		}
+		return parts[0]
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package toolchain_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/toolchain"
+)
+
+func TestToolchain(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Runtime- og toolchain-versjoner")
+}
+
+func file(path, content string) []models.FileEntry {
+	return []models.FileEntry{{Path: path, Content: content}}
+}
+
+var _ = Describe("Versions", func() {
+	It("henter versjoner fra manifester, versjonsfiler, Dockerfiles og workflows", func() {
+		entry := &models.RepoEntry{
+			Files: map[string][]models.FileEntry{
+				"go.mod":          file("go.mod", "module x\n\ngo 1.21\n\ntoolchain go1.22.3\n"),
+				"package.json":    file("package.json", `{"engines": {"node": ">=18.0.0"}}`),
+				".nvmrc":          file(".nvmrc", "lts/iron\n"),
+				".python-version": file(".python-version", "3.11.4\n"),
+				"pyproject.toml":  file("pyproject.toml", "[project]\nrequires-python = \">=3.9\"\n"),
+				"build.gradle.kts": file("build.gradle.kts", `kotlin {
+    jvmToolchain(21)
+}
+java {
+    sourceCompatibility = JavaVersion.VERSION_1_8
+}`),
+				"pom.xml": file("pom.xml", "<project><properties><java.version>17</java.version></properties>"+
+					"<build><plugins><plugin><configuration><release>${java.version}</release></configuration></plugin></plugins></build></project>"),
+				"dockerfile": file("Dockerfile", "FROM golang:1.22.3-alpine AS build\nFROM gcr.io/distroless/java21-debian12\n"),
+			},
+			CIConfig: file(".github/workflows/ci.yml", `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/setup-node@v4
+        with:
+          node-version: 16
+      - uses: actions/setup-python@v5
+        with:
+          python-version: ${{ matrix.python }}
+`),
+		}
+
+		got := map[string]string{}
+		for _, v := range toolchain.Versions(entry) {
+			got[v.Source+" "+v.Setting+" "+v.Raw] = v.Runtime + " " + v.Version
+		}
+		Expect(got).To(Equal(map[string]string{
+			"go.mod go 1.21":                                           "go 1.21",
+			"go.mod toolchain go1.22.3":                                "go 1.22",
+			"package.json engines.node >=18.0.0":                       "node 18",
+			".nvmrc .nvmrc lts/iron":                                   "node 20",
+			".python-version .python-version 3.11.4":                   "python 3.11",
+			"pyproject.toml requires-python >=3.9":                     "python 3.9",
+			"build.gradle.kts jvmToolchain 21":                         "java 21",
+			"build.gradle.kts sourceCompatibility 1_8":                 "java 8",
+			"pom.xml java.version 17":                                  "java 17",
+			"pom.xml release 17":                                       "java 17",
+			"Dockerfile FROM golang:1.22.3-alpine":                     "go 1.22",
+			"Dockerfile FROM gcr.io/distroless/java21-debian12:latest": "java 21",
+			".github/workflows/ci.yml actions/setup-node 16":           "node 16",
+		}))
+	})
+})
+
+var _ = Describe("Normalize", func() {
+	DescribeTable("normaliserer til major eller major.minor",
+		func(runtime, raw, expected string) {
+			Expect(toolchain.Normalize(runtime, raw)).To(Equal(expected))
+		},
+		Entry("Java 1.8", toolchain.RuntimeJava, "1.8", "8"),
+		Entry("Java med build", toolchain.RuntimeJava, "17.0.9+9", "17"),
+		Entry("Node med v", toolchain.RuntimeNode, "v20.11.0", "20"),
+		Entry("Node-område", toolchain.RuntimeNode, "^16 || ^18", "16"),
+		Entry("Node LTS-kodenavn", toolchain.RuntimeNode, "lts/hydrogen", "18"),
+		Entry("Node uten versjon", toolchain.RuntimeNode, "lts/*", ""),
+		Entry("Python poetry", toolchain.RuntimePython, "^3.12", "3.12"),
+		Entry("Go toolchain", toolchain.RuntimeGo, "go1.23.0", "1.23"),
+	)
+})
@@ -0,0 +1,375 @@
+package updatebot
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+)
+
+const (
+	ToolDependabot = "dependabot"
+	ToolRenovate   = "renovate"
+)
+
+// AllEcosystems betyr at Renovate ikke er begrenset med enabledManagers,
+// og dermed dekker alt den kjenner igjen.
+const AllEcosystems = "*"
+
+// Stiene vi leter etter konfig på, i små bokstaver slik de ligger i RepoEntry.Files
+var (
+	dependabotPaths = []string{".github/dependabot.yml", ".github/dependabot.yaml"}
+	renovatePaths   = []string{
+		"renovate.json", "renovate.json5", ".renovaterc", ".renovaterc.json",
+		".github/renovate.json", ".github/renovate.json5",
+		"package.json",
+	}
+)
+
+// IsConfigFile sier om et filnavn på toppnivå er en Renovate-konfig vi vil hente.
+// package.json hentes også, siden Renovate kan konfigureres under nøkkelen "renovate".
+func IsConfigFile(lowerName string) bool {
+	for _, p := range renovatePaths {
+		if p == lowerName {
+			return true
+		}
+	}
+	return false
+}
+
+// Renovate-managere oversatt til Dependabots navn på økosystemer, så begge verktøy
+// kan sammenlignes med manifestene vi finner.
+var renovateManagers = map[string]string{
+	"gomod":            "gomod",
+	"npm":              "npm",
+	"maven":            "maven",
+	"gradle":           "gradle",
+	"gradle-wrapper":   "gradle",
+	"pip_requirements": "pip",
+	"pip_setup":        "pip",
+	"pipenv":           "pip",
+	"poetry":           "pip",
+	"pep621":           "pip",
+	"dockerfile":       "docker",
+	"docker-compose":   "docker",
+	"github-actions":   "github-actions",
+	"bundler":          "bundler",
+	"cargo":            "cargo",
+	"nuget":            "nuget",
+	"composer":         "composer",
+	"terraform":        "terraform",
+	"helmv3":           "helm",
+}
+
+// Manifester på toppnivå og økosystemet de hører til
+var manifestEcosystems = map[string]string{
+	"go.mod":           "gomod",
+	"package.json":     "npm",
+	"pom.xml":          "maven",
+	"build.gradle":     "gradle",
+	"build.gradle.kts": "gradle",
+	"requirements.txt": "pip",
+	"pyproject.toml":   "pip",
+	"pipfile":          "pip",
+	"setup.py":         "pip",
+	"gemfile":          "bundler",
+	"cargo.toml":       "cargo",
+	"composer.json":    "composer",
+	"chart.yaml":       "helm",
+}
+
+// ParseDependabot leser .github/dependabot.yml. Dependabot har ikke innebygd auto-merge,
+// så det settes av Analyze ut fra workflows.
+func ParseDependabot(filePath, content string) ([]models.UpdateBotConfig, error) {
+	var raw struct {
+		Updates []struct {
+			Ecosystem   string   `yaml:"package-ecosystem"`
+			Directory   string   `yaml:"directory"`
+			Directories []string `yaml:"directories"`
+			Schedule    struct {
+				Interval string `yaml:"interval"`
+			} `yaml:"schedule"`
+			Groups map[string]interface{} `yaml:"groups"`
+		} `yaml:"updates"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &raw); err != nil {
+		return nil, fmt.Errorf("ugyldig dependabot-konfig: %w", err)
+	}
+
+	var result []models.UpdateBotConfig
+	for _, u := range raw.Updates {
+		dirs := u.Directories
+		if u.Directory != "" {
+			dirs = append([]string{u.Directory}, dirs...)
+		}
+		if len(dirs) == 0 {
+			dirs = []string{"/"}
+		}
+		var groups []string
+		for name := range u.Groups {
+			groups = append(groups, name)
+		}
+		sort.Strings(groups)
+
+		for _, dir := range dirs {
+			result = append(result, models.UpdateBotConfig{
+				Tool:      ToolDependabot,
+				Path:      filePath,
+				Ecosystem: u.Ecosystem,
+				Directory: dir,
+				Schedule:  u.Schedule.Interval,
+				Groups:    groups,
+			})
+		}
+	}
+	return result, nil
+}
+
+// ParseRenovate leser en Renovate-konfig i JSON eller JSON5. For package.json
+// brukes bare nøkkelen "renovate"; uten den returneres ingen konfig.
+// Presets i "extends" slås ikke opp, bare group:-presets tas med som gruppering.
+func ParseRenovate(filePath, content string) ([]models.UpdateBotConfig, error) {
+	var raw map[string]interface{}
+	// JSON5 tillater kommentarer; uten dem er JSON5 gyldig YAML
+	if err := yaml.Unmarshal([]byte(stripComments(content)), &raw); err != nil {
+		return nil, fmt.Errorf("ugyldig renovate-konfig: %w", err)
+	}
+	if strings.EqualFold(path.Base(filePath), "package.json") {
+		nested, ok := raw["renovate"].(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		raw = nested
+	}
+	if enabled, ok := raw["enabled"].(bool); ok && !enabled {
+		return nil, nil
+	}
+
+	schedule := strings.Join(stringList(raw["schedule"]), "; ")
+	automerge, _ := raw["automerge"].(bool)
+
+	groups := map[string]bool{}
+	for _, preset := range stringList(raw["extends"]) {
+		if strings.HasPrefix(preset, "group:") {
+			groups[preset] = true
+		}
+	}
+	automergeManagers := map[string]bool{}
+	rules, _ := raw["packageRules"].([]interface{})
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, ok := rule["groupName"].(string); ok && name != "" {
+			groups[name] = true
+		}
+		if am, _ := rule["automerge"].(bool); am {
+			managers := stringList(rule["matchManagers"])
+			if len(managers) == 0 {
+				managers = []string{AllEcosystems}
+			}
+			for _, m := range managers {
+				automergeManagers[m] = true
+			}
+		}
+	}
+	var groupNames []string
+	for g := range groups {
+		groupNames = append(groupNames, g)
+	}
+	sort.Strings(groupNames)
+
+	dirs := stringList(raw["includePaths"])
+	if len(dirs) == 0 {
+		dirs = []string{"/"}
+	}
+
+	managers := stringList(raw["enabledManagers"])
+	if len(managers) == 0 {
+		managers = []string{AllEcosystems}
+	}
+
+	var result []models.UpdateBotConfig
+	seen := map[string]bool{}
+	for _, m := range managers {
+		ecosystem := m
+		if e, ok := renovateManagers[m]; ok {
+			ecosystem = e
+		}
+		for _, dir := range dirs {
+			key := ecosystem + "|" + dir
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, models.UpdateBotConfig{
+				Tool:      ToolRenovate,
+				Path:      filePath,
+				Ecosystem: ecosystem,
+				Directory: dir,
+				Schedule:  schedule,
+				Groups:    groupNames,
+				AutoMerge: automerge || automergeManagers[m] || automergeManagers[AllEcosystems],
+			})
+		}
+	}
+	return result, nil
+}
+
+// Analyze finner konfigen til Dependabot og Renovate blant filene i repoet, og sjekker
+// hvilke økosystemer på toppnivå som dekkes. Dekning sjekkes per økosystem, ikke per katalog.
+func Analyze(files map[string][]models.FileEntry, rootFiles []string, ci []models.FileEntry) ([]models.UpdateBotConfig, []models.EcosystemCoverage) {
+	var configs []models.UpdateBotConfig
+
+	dependabotAutoMerge := hasDependabotAutoMerge(ci)
+	for _, p := range dependabotPaths {
+		for _, f := range files[p] {
+			parsed, err := ParseDependabot(f.Path, f.Content)
+			if err != nil {
+				continue
+			}
+			for i := range parsed {
+				parsed[i].AutoMerge = dependabotAutoMerge
+			}
+			configs = append(configs, parsed...)
+		}
+	}
+	for _, p := range renovatePaths {
+		for _, f := range files[p] {
+			parsed, err := ParseRenovate(f.Path, f.Content)
+			if err != nil {
+				continue
+			}
+			configs = append(configs, parsed...)
+		}
+	}
+
+	return configs, Coverage(DetectEcosystems(rootFiles, len(ci) > 0), configs)
+}
+
+// DetectEcosystems finner økosystemer ut fra filnavn på toppnivå, med manifestene som viser det.
+// Workflows gir github-actions.
+func DetectEcosystems(rootFiles []string, hasWorkflows bool) map[string][]string {
+	result := map[string][]string{}
+	for _, name := range rootFiles {
+		lower := strings.ToLower(name)
+		ecosystem, ok := manifestEcosystems[lower]
+		switch {
+		case ok:
+		case strings.Contains(lower, "dockerfile"):
+			ecosystem = "docker"
+		case strings.HasSuffix(lower, ".csproj"), strings.HasSuffix(lower, ".sln"):
+			ecosystem = "nuget"
+		case strings.HasSuffix(lower, ".tf"):
+			ecosystem = "terraform"
+		default:
+			continue
+		}
+		result[ecosystem] = append(result[ecosystem], name)
+	}
+	if hasWorkflows {
+		result["github-actions"] = append(result["github-actions"], ".github/workflows")
+	}
+	return result
+}
+
+// Coverage sier hvilke verktøy som dekker hvert økosystem. CoveredBy er tom for
+// økosystemer ingen oppdateringsbot passer på.
+func Coverage(ecosystems map[string][]string, configs []models.UpdateBotConfig) []models.EcosystemCoverage {
+	var result []models.EcosystemCoverage
+	for ecosystem, manifests := range ecosystems {
+		tools := map[string]bool{}
+		for _, c := range configs {
+			if c.Ecosystem == ecosystem || c.Ecosystem == AllEcosystems {
+				tools[c.Tool] = true
+			}
+		}
+		coveredBy := make([]string, 0, len(tools))
+		for t := range tools {
+			coveredBy = append(coveredBy, t)
+		}
+		sort.Strings(coveredBy)
+		sort.Strings(manifests)
+		result = append(result, models.EcosystemCoverage{
+			Ecosystem: ecosystem,
+			Manifests: manifests,
+			CoveredBy: coveredBy,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Ecosystem < result[j].Ecosystem })
+	return result
+}
+
+// hasDependabotAutoMerge ser etter den vanlige oppskriften: en workflow som
+// reagerer på Dependabot-PR-er og slår på auto-merge.
+func hasDependabotAutoMerge(ci []models.FileEntry) bool {
+	for _, f := range ci {
+		content := strings.ToLower(f.Content)
+		if strings.Contains(content, "dependabot") &&
+			(strings.Contains(content, "gh pr merge") || strings.Contains(content, "enable-pull-request-automerge")) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringList(v interface{}) []string {
+	switch val := v.(type) {
+	case string:
+		return []string{val}
+	case []interface{}:
+		var result []string
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// stripComments fjerner // og /* */ utenfor strenger.
+func stripComments(s string) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			b.WriteByte(c)
+			if c == '\\' && i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			b.WriteByte(c)
+		case c == '/' && i+1 < len(s) && s[i+1] == '/':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			if i < len(s) {
+				b.WriteByte('\n')
+			}
+		case c == '/' && i+1 < len(s) && s[i+1] == '*':
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return b.String()
+			}
+			// Behold linjeskift så linjenumre i feilmeldinger stemmer
+			b.WriteString(strings.Repeat("\n", strings.Count(s[i:i+2+end], "\n")))
+			i += end + 3
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package updatebot_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/models"
+	"github.com/jonmartinstorm/reposnusern/internal/updatebot"
+)
+
+func TestUpdatebot(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Dependabot og Renovate")
+}
+
+var _ = Describe("ParseDependabot", func() {
+	It("gir én rad per økosystem og katalog", func() {
+		configs, err := updatebot.ParseDependabot(".github/dependabot.yml", `version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /
+    schedule:
+      interval: weekly
+    groups:
+      alle:
+        patterns: ["*"]
+  - package-ecosystem: docker
+    directories: ["/app", "/worker"]
+    schedule:
+      interval: daily
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configs).To(Equal([]models.UpdateBotConfig{
+			{Tool: "dependabot", Path: ".github/dependabot.yml", Ecosystem: "gomod", Directory: "/", Schedule: "weekly", Groups: []string{"alle"}},
+			{Tool: "dependabot", Path: ".github/dependabot.yml", Ecosystem: "docker", Directory: "/app", Schedule: "daily"},
+			{Tool: "dependabot", Path: ".github/dependabot.yml", Ecosystem: "docker", Directory: "/worker", Schedule: "daily"},
+		}))
+	})
+})
+
+var _ = Describe("ParseRenovate", func() {
+	It("leser JSON5 med kommentarer og oversetter managere", func() {
+		configs, err := updatebot.ParseRenovate(".github/renovate.json5", `{
+  // Felles oppsett
+  extends: ['config:recommended', 'group:allNonMajor'],
+  enabledManagers: ['gomod', 'github-actions'],
+  schedule: ['before 6am on monday'],
+  packageRules: [
+    { matchManagers: ['github-actions'], automerge: true, groupName: 'actions' }, /* trygge */
+  ],
+}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configs).To(HaveLen(2))
+		Expect(configs[0].Ecosystem).To(Equal("gomod"))
+		Expect(configs[0].AutoMerge).To(BeFalse())
+		Expect(configs[0].Schedule).To(Equal("before 6am on monday"))
+		Expect(configs[0].Groups).To(Equal([]string{"actions", "group:allNonMajor"}))
+		Expect(configs[1].Ecosystem).To(Equal("github-actions"))
+		Expect(configs[1].AutoMerge).To(BeTrue())
+	})
+
+	It("dekker alt uten enabledManagers, og leser nøkkelen renovate i package.json", func() {
+		configs, err := updatebot.ParseRenovate("package.json", `{"name": "app", "renovate": {"automerge": true}}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configs).To(Equal([]models.UpdateBotConfig{
+			{Tool: "renovate", Path: "package.json", Ecosystem: "*", Directory: "/", AutoMerge: true},
+		}))
+
+		configs, err = updatebot.ParseRenovate("package.json", `{"name": "app"}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configs).To(BeEmpty())
+
+		configs, err = updatebot.ParseRenovate("renovate.json", `{"enabled": false}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configs).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Analyze", func() {
+	It("flagger økosystemer i koden som ingen bot dekker", func() {
+		files := map[string][]models.FileEntry{
+			".github/dependabot.yml": {{Path: ".github/dependabot.yml", Content: "version: 2\nupdates:\n  - package-ecosystem: gomod\n    directory: /\n    schedule:\n      interval: weekly\n"}},
+		}
+		ci := []models.FileEntry{{
+			Path:    ".github/workflows/automerge.yml",
+			Content: "on: pull_request\njobs:\n  merge:\n    if: github.actor == 'dependabot[bot]'\n    steps:\n      - run: gh pr merge --auto --squash \"$PR_URL\"\n",
+		}}
+
+		configs, coverage := updatebot.Analyze(files, []string{"go.mod", "go.sum", "Dockerfile", "README.md"}, ci)
+		Expect(configs).To(HaveLen(1))
+		Expect(configs[0].AutoMerge).To(BeTrue())
+		Expect(coverage).To(Equal([]models.EcosystemCoverage{
+			{Ecosystem: "docker", Manifests: []string{"Dockerfile"}, CoveredBy: []string{}},
+			{Ecosystem: "github-actions", Manifests: []string{".github/workflows"}, CoveredBy: []string{}},
+			{Ecosystem: "gomod", Manifests: []string{"go.mod"}, CoveredBy: []string{"dependabot"}},
+		}))
+	})
+})
@@ -0,0 +1,170 @@
+package workflow
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Alvorlighet på samme skala som Dockerfile-linteren
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+var (
+	// Felter i github-konteksten som angriperen styrer, f.eks. tittelen på en PR fra en fork
+	untrustedExpressionPattern = regexp.MustCompile(`\$\{\{[^}]*\b(github\.event\.(` +
+		`issue\.(title|body)|` +
+		`pull_request\.(title|body|head\.ref|head\.label|head\.repo\.default_branch)|` +
+		`comment\.body|review\.body|review_comment\.body|discussion\.(title|body)|` +
+		`pages\.[^.\s}]+\.page_name|` +
+		`(commits\.[^.\s}]+|head_commit)\.(message|author\.(email|name))|` +
+		`workflow_run\.(head_branch|head_commit\.message|head_commit\.author\.(email|name)|display_title))|` +
+		`github\.head_ref)\b`)
+	prHeadRefPattern = regexp.MustCompile(`github\.event\.(pull_request\.head\.(sha|ref)|workflow_run\.head_(sha|branch))|github\.head_ref|refs/pull/`)
+)
+
+// Context er det sjekkene trenger å vite om repoet workflowen ligger i.
+type Context struct {
+	Owner  string // org eller bruker som eier repoet
+	Public bool
+}
+
+// Finding er ett funn i en workflow. Job er tom for funn på workflow-nivå.
+type Finding struct {
+	RuleID      string
+	Severity    string
+	Description string
+	Job         string
+	Line        int
+}
+
+type check struct {
+	id          string
+	severity    string
+	description string
+	run         func(wf *Workflow, ctx Context) []Finding
+}
+
+var checks = []check{
+	{
+		id:          "GHA001",
+		severity:    SeverityError,
+		description: "pull_request_target eller workflow_run sjekker ut koden fra PR-en",
+		run: func(wf *Workflow, _ Context) []Finding {
+			if !hasTrigger(wf, "pull_request_target", "workflow_run") {
+				return nil
+			}
+			var findings []Finding
+			for _, job := range wf.Jobs {
+				for _, step := range job.Steps {
+					if step.Uses == nil || step.Uses.Action() != "actions/checkout" {
+						continue
+					}
+					if prHeadRefPattern.MatchString(step.With["ref"]) || prHeadRefPattern.MatchString(step.With["repository"]) {
+						findings = append(findings, Finding{Job: job.ID, Line: step.Line})
+					}
+				}
+			}
+			return findings
+		},
+	},
+	{
+		id:          "GHA002",
+		severity:    SeverityError,
+		description: "Utrygt ${{ github.event.* }}-uttrykk rett i run-skript (script injection)",
+		run: func(wf *Workflow, _ Context) []Finding {
+			var findings []Finding
+			for _, job := range wf.Jobs {
+				for _, step := range job.Steps {
+					for _, idx := range untrustedExpressionPattern.FindAllStringIndex(step.Run, -1) {
+						line := step.RunLine + strings.Count(step.Run[:idx[0]], "\n")
+						findings = append(findings, Finding{Job: job.ID, Line: line})
+					}
+				}
+			}
+			return findings
+		},
+	},
+	{
+		id:          "GHA003",
+		severity:    SeverityWarning,
+		description: "Mangler permissions, så GITHUB_TOKEN får standardrettighetene (ofte write-all)",
+		run: func(wf *Workflow, _ Context) []Finding {
+			if wf.Permissions != nil {
+				return nil
+			}
+			var findings []Finding
+			for _, job := range wf.Jobs {
+				// Jobber som kaller gjenbrukbare workflows får rettighetene sine derfra
+				if job.Permissions == nil && job.Uses == nil {
+					findings = append(findings, Finding{Job: job.ID, Line: job.Line})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		id:          "GHA004",
+		severity:    SeverityWarning,
+		description: "secrets: inherit sendt til gjenbrukbar workflow utenfor egen org",
+		run: func(wf *Workflow, ctx Context) []Finding {
+			var findings []Finding
+			for _, job := range wf.Jobs {
+				if !job.SecretsInherit || job.Uses == nil || job.Uses.Kind != KindReusable {
+					continue
+				}
+				if !strings.EqualFold(job.Uses.Owner, ctx.Owner) {
+					findings = append(findings, Finding{Job: job.ID, Line: job.Uses.Line})
+				}
+			}
+			return findings
+		},
+	},
+	{
+		id:          "GHA005",
+		severity:    SeverityWarning,
+		description: "Self-hosted runner i offentlig repo",
+		run: func(wf *Workflow, ctx Context) []Finding {
+			if !ctx.Public {
+				return nil
+			}
+			var findings []Finding
+			for _, job := range wf.Jobs {
+				for _, label := range job.RunsOn {
+					if strings.EqualFold(label, "self-hosted") {
+						findings = append(findings, Finding{Job: job.ID, Line: job.Line})
+						break
+					}
+				}
+			}
+			return findings
+		},
+	},
+}
+
+// Check kjører alle sikkerhetssjekkene på en workflow, i regelrekkefølge.
+func Check(wf *Workflow, ctx Context) []Finding {
+	var result []Finding
+	for _, c := range checks {
+		for _, f := range c.run(wf, ctx) {
+			f.RuleID = c.id
+			f.Severity = c.severity
+			f.Description = c.description
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+func hasTrigger(wf *Workflow, triggers ...string) bool {
+	for _, t := range wf.Triggers {
+		for _, want := range triggers {
+			if t == want {
+				return true
+			}
+		}
+	}
+	return false
+}
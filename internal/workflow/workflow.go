@@ -0,0 +1,327 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hva en uses:-referanse peker på
+const (
+	KindAction   = "action"   // owner/repo[/path]@ref
+	KindReusable = "reusable" // owner/repo/.github/workflows/x.yml@ref
+	KindDocker   = "docker"   // docker://image
+	KindLocal    = "local"    // ./path
+)
+
+// Hvordan en referanse er pinnet. Tag og branch skilles på formen,
+// siden vi ikke spør GitHub om hva ref-en faktisk er.
+const (
+	RefSHA    = "sha"
+	RefTag    = "tag"
+	RefBranch = "branch"
+)
+
+var (
+	fullSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	tagPattern     = regexp.MustCompile(`^v?\d+(\.\d+)*([-+.][0-9A-Za-z.-]+)?$`)
+	secretPattern  = regexp.MustCompile(`secrets\.([A-Za-z_][A-Za-z0-9_]*)|secrets\[\s*['"]([A-Za-z_][A-Za-z0-9_]*)['"]\s*\]`)
+)
+
+// Workflow er det vi henter ut av en GitHub Actions-workflow.
+type Workflow struct {
+	Name     string
+	Triggers []string
+	// Permissions er nil når workflowen ikke setter permissions,
+	// ellers normalisert som "write-all", "read-all" eller "contents:read,id-token:write".
+	Permissions *string
+	Secrets     []string // navn på alle secrets.X som refereres
+	Jobs        []Job
+}
+
+type Job struct {
+	ID          string
+	Name        string
+	Line        int
+	RunsOn      []string
+	Permissions *string
+	// Uses er satt når jobben kaller en gjenbrukbar workflow
+	Uses           *ActionRef
+	SecretsInherit bool
+	Steps          []Step
+}
+
+type Step struct {
+	Index   int
+	Name    string
+	Line    int
+	Uses    *ActionRef
+	With    map[string]string
+	Run     string
+	RunLine int // linjen der run-skriptet starter
+}
+
+// ActionRef er en uses:-referanse delt opp i sine deler.
+type ActionRef struct {
+	Raw     string
+	Kind    string
+	Owner   string
+	Repo    string
+	Path    string
+	Ref     string
+	RefType string
+	Line    int
+}
+
+// Action returnerer owner/repo[/path], f.eks. "actions/checkout".
+func (a ActionRef) Action() string {
+	switch a.Kind {
+	case KindAction, KindReusable:
+		name := a.Owner + "/" + a.Repo
+		if a.Path != "" {
+			name += "/" + a.Path
+		}
+		return name
+	default:
+		return strings.TrimPrefix(strings.SplitN(a.Raw, "@", 2)[0], "docker://")
+	}
+}
+
+// Usage er en uses:-referanse med jobben og steget den står i.
+// StepIndex er -1 for gjenbrukbare workflows som kalles fra jobben selv.
+type Usage struct {
+	Job       string
+	StepIndex int
+	ActionRef
+}
+
+// Usages returnerer alle uses:-referanser i workflowen i rekkefølge.
+func (w *Workflow) Usages() []Usage {
+	var result []Usage
+	for _, job := range w.Jobs {
+		if job.Uses != nil {
+			result = append(result, Usage{Job: job.ID, StepIndex: -1, ActionRef: *job.Uses})
+		}
+		for _, step := range job.Steps {
+			if step.Uses != nil {
+				result = append(result, Usage{Job: job.ID, StepIndex: step.Index, ActionRef: *step.Uses})
+			}
+		}
+	}
+	return result
+}
+
+// Parse leser en workflow-fil. Linjenumre er 1-basert og peker inn i filen.
+func Parse(content string) (*Workflow, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("ugyldig workflow-YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("tom workflow")
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("workflow er ikke et YAML-objekt")
+	}
+
+	wf := &Workflow{
+		Name:        scalar(lookup(root, "name")),
+		Triggers:    keysOrValues(lookup(root, "on")),
+		Permissions: permissions(lookup(root, "permissions")),
+		Secrets:     secretNames(content),
+	}
+
+	if jobs := lookup(root, "jobs"); jobs != nil && jobs.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(jobs.Content); i += 2 {
+			wf.Jobs = append(wf.Jobs, parseJob(jobs.Content[i], jobs.Content[i+1]))
+		}
+	}
+	return wf, nil
+}
+
+func parseJob(key, node *yaml.Node) Job {
+	job := Job{
+		ID:          key.Value,
+		Line:        key.Line,
+		Name:        scalar(lookup(node, "name")),
+		RunsOn:      runsOn(lookup(node, "runs-on")),
+		Permissions: permissions(lookup(node, "permissions")),
+	}
+	if uses := lookup(node, "uses"); uses != nil && uses.Value != "" {
+		ref := ParseUses(uses.Value)
+		ref.Line = uses.Line
+		job.Uses = &ref
+	}
+	if secrets := lookup(node, "secrets"); secrets != nil && secrets.Kind == yaml.ScalarNode {
+		job.SecretsInherit = secrets.Value == "inherit"
+	}
+
+	if steps := lookup(node, "steps"); steps != nil && steps.Kind == yaml.SequenceNode {
+		for i, s := range steps.Content {
+			step := Step{Index: i, Line: s.Line, Name: scalar(lookup(s, "name"))}
+			if uses := lookup(s, "uses"); uses != nil && uses.Value != "" {
+				ref := ParseUses(uses.Value)
+				ref.Line = uses.Line
+				step.Uses = &ref
+			}
+			if with := lookup(s, "with"); with != nil && with.Kind == yaml.MappingNode {
+				step.With = map[string]string{}
+				for j := 0; j+1 < len(with.Content); j += 2 {
+					step.With[with.Content[j].Value] = with.Content[j+1].Value
+				}
+			}
+			if run := lookup(s, "run"); run != nil {
+				step.Run = run.Value
+				step.RunLine = run.Line
+				// Blokk-skalarer (| og >) starter på linjen etter nøkkelen
+				if run.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+					step.RunLine++
+				}
+			}
+			job.Steps = append(job.Steps, step)
+		}
+	}
+	return job
+}
+
+// ParseUses deler en uses:-verdi i owner, repo, path og ref, og avgjør hvordan den er pinnet.
+func ParseUses(uses string) ActionRef {
+	uses = strings.TrimSpace(uses)
+	ref := ActionRef{Raw: uses}
+
+	switch {
+	case strings.HasPrefix(uses, "docker://"):
+		ref.Kind = KindDocker
+		return ref
+	case strings.HasPrefix(uses, "./"):
+		ref.Kind = KindLocal
+		ref.Path = strings.TrimPrefix(uses, "./")
+		return ref
+	}
+
+	name, version, _ := strings.Cut(uses, "@")
+	parts := strings.SplitN(name, "/", 3)
+	ref.Kind = KindAction
+	ref.Owner = parts[0]
+	if len(parts) > 1 {
+		ref.Repo = parts[1]
+	}
+	if len(parts) > 2 {
+		ref.Path = parts[2]
+		if strings.HasPrefix(ref.Path, ".github/workflows/") {
+			ref.Kind = KindReusable
+		}
+	}
+	ref.Ref = version
+	ref.RefType = RefType(version)
+	return ref
+}
+
+// RefType klassifiserer en git-ref som full sha, tag eller branch. Tom ref gir tom streng.
+func RefType(ref string) string {
+	switch {
+	case ref == "":
+		return ""
+	case fullSHAPattern.MatchString(strings.ToLower(ref)):
+		return RefSHA
+	case tagPattern.MatchString(ref):
+		return RefTag
+	default:
+		return RefBranch
+	}
+}
+
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// keysOrValues håndterer de tre formene "on" kan ha: streng, liste eller objekt.
+func keysOrValues(node *yaml.Node) []string {
+	if node == nil {
+		return nil
+	}
+	var result []string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		result = append(result, node.Value)
+	case yaml.SequenceNode:
+		for _, n := range node.Content {
+			result = append(result, n.Value)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			result = append(result, node.Content[i].Value)
+		}
+	}
+	return result
+}
+
+// runsOn håndterer streng, liste og {group, labels}.
+func runsOn(node *yaml.Node) []string {
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.MappingNode {
+		var result []string
+		if group := scalar(lookup(node, "group")); group != "" {
+			result = append(result, "group:"+group)
+		}
+		return append(result, keysOrValues(lookup(node, "labels"))...)
+	}
+	return keysOrValues(node)
+}
+
+func permissions(node *yaml.Node) *string {
+	if node == nil {
+		return nil
+	}
+	var value string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		value = node.Value
+	case yaml.MappingNode:
+		var scopes []string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			scopes = append(scopes, node.Content[i].Value+":"+node.Content[i+1].Value)
+		}
+		sort.Strings(scopes)
+		value = strings.Join(scopes, ",")
+	}
+	return &value
+}
+
+func secretNames(content string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, m := range secretPattern.FindAllStringSubmatch(content, -1) {
+		name := m[1]
+		if name == "" {
+			name = m[2]
+		}
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
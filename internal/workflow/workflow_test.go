@@ -0,0 +1,192 @@
+package workflow_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/jonmartinstorm/reposnusern/internal/workflow"
+)
+
+func TestWorkflow(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GitHub Actions-workflows")
+}
+
+const buildWorkflow = `name: Bygg
+on:
+  push:
+    branches: [main]
+  pull_request:
+permissions:
+  id-token: write
+  contents: read
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v2
+      - name: Bygg
+        run: |
+          echo ${{ secrets.NAIS_TOKEN }}
+          make
+      - uses: docker/build-push-action@8c0edbc76e98fa90f69d9a2c020dcb50019dc325
+  deploy:
+    needs: build
+    permissions: write-all
+    uses: navikt/felles/.github/workflows/deploy.yml@main
+    secrets: inherit
+  selfhosted:
+    runs-on: [self-hosted, linux]
+    steps:
+      - uses: ./.github/actions/lokal
+      - uses: docker://alpine:3.19
+      - run: echo ${{ secrets['SLACK_WEBHOOK'] }}
+`
+
+var _ = Describe("Parse", func() {
+	It("henter ut triggere, permissions, secrets og jobber", func() {
+		wf, err := workflow.Parse(buildWorkflow)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(wf.Name).To(Equal("Bygg"))
+		Expect(wf.Triggers).To(Equal([]string{"push", "pull_request"}))
+		Expect(*wf.Permissions).To(Equal("contents:read,id-token:write"))
+		Expect(wf.Secrets).To(Equal([]string{"NAIS_TOKEN", "SLACK_WEBHOOK"}))
+
+		Expect(wf.Jobs).To(HaveLen(3))
+		build := wf.Jobs[0]
+		Expect(build.ID).To(Equal("build"))
+		Expect(build.Line).To(Equal(10))
+		Expect(build.RunsOn).To(Equal([]string{"ubuntu-latest"}))
+		Expect(build.Permissions).To(BeNil())
+		Expect(build.Steps[1].RunLine).To(Equal(16))
+
+		deploy := wf.Jobs[1]
+		Expect(*deploy.Permissions).To(Equal("write-all"))
+		Expect(deploy.SecretsInherit).To(BeTrue())
+		Expect(deploy.Uses.Kind).To(Equal(workflow.KindReusable))
+
+		Expect(wf.Jobs[2].RunsOn).To(Equal([]string{"self-hosted", "linux"}))
+	})
+
+	It("lister alle uses:-referanser med jobb, steg og linje", func() {
+		wf, err := workflow.Parse(buildWorkflow)
+		Expect(err).NotTo(HaveOccurred())
+
+		usages := wf.Usages()
+		Expect(usages).To(HaveLen(5))
+		Expect(usages[0].Job).To(Equal("build"))
+		Expect(usages[0].StepIndex).To(Equal(0))
+		Expect(usages[0].Action()).To(Equal("actions/checkout"))
+		Expect(usages[0].Ref).To(Equal("v2"))
+		Expect(usages[0].RefType).To(Equal(workflow.RefTag))
+		Expect(usages[0].Line).To(Equal(13))
+
+		Expect(usages[1].RefType).To(Equal(workflow.RefSHA))
+		Expect(usages[2].StepIndex).To(Equal(-1))
+		Expect(usages[2].Action()).To(Equal("navikt/felles/.github/workflows/deploy.yml"))
+		Expect(usages[2].RefType).To(Equal(workflow.RefBranch))
+		Expect(usages[3].Kind).To(Equal(workflow.KindLocal))
+		Expect(usages[4].Kind).To(Equal(workflow.KindDocker))
+		Expect(usages[4].Action()).To(Equal("alpine:3.19"))
+	})
+
+	It("støtter on som streng og liste, og runs-on som group", func() {
+		wf, err := workflow.Parse("on: push\njobs:\n  a:\n    runs-on:\n      group: large\n      labels: [x64]\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wf.Triggers).To(Equal([]string{"push"}))
+		Expect(wf.Permissions).To(BeNil())
+		Expect(wf.Jobs[0].RunsOn).To(Equal([]string{"group:large", "x64"}))
+
+		wf, err = workflow.Parse("on: [push, workflow_dispatch]\npermissions: {}\n")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(wf.Triggers).To(Equal([]string{"push", "workflow_dispatch"}))
+		Expect(*wf.Permissions).To(BeEmpty())
+	})
+
+	It("feiler på ugyldig YAML", func() {
+		_, err := workflow.Parse("on: [push\n")
+		Expect(err).To(HaveOccurred())
+		_, err = workflow.Parse("- bare en liste")
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("klassifiserer ref-er",
+		func(uses, refType string) {
+			Expect(workflow.ParseUses(uses).RefType).To(Equal(refType))
+		},
+		Entry("full SHA", "actions/checkout@b4ffde65f46336ab88eb53be808477a3936bae11", workflow.RefSHA),
+		Entry("major-tag", "actions/checkout@v4", workflow.RefTag),
+		Entry("semver", "actions/setup-go@v5.0.1", workflow.RefTag),
+		Entry("branch", "actions/checkout@main", workflow.RefBranch),
+		Entry("kort SHA regnes som branch", "actions/checkout@b4ffde6", workflow.RefBranch),
+	)
+})
+
+func ruleIDs(findings []workflow.Finding) []string {
+	var ids []string
+	for _, f := range findings {
+		ids = append(ids, f.RuleID)
+	}
+	return ids
+}
+
+var _ = Describe("Check", func() {
+	const risky = `on: pull_request_target
+jobs:
+  test:
+    runs-on: [self-hosted]
+    steps:
+      - uses: actions/checkout@v4
+        with:
+          ref: ${{ github.event.pull_request.head.sha }}
+      - run: |
+          echo start
+          echo "${{ github.event.pull_request.title }}"
+  deploy:
+    uses: andre/felles/.github/workflows/deploy.yml@v1
+    secrets: inherit
+  intern:
+    uses: navikt/felles/.github/workflows/deploy.yml@v1
+    secrets: inherit
+`
+
+	It("finner kjente risikoer med jobb og linje", func() {
+		wf, err := workflow.Parse(risky)
+		Expect(err).NotTo(HaveOccurred())
+
+		findings := workflow.Check(wf, workflow.Context{Owner: "navikt", Public: true})
+		Expect(ruleIDs(findings)).To(Equal([]string{"GHA001", "GHA002", "GHA003", "GHA004", "GHA005"}))
+		Expect(findings[0]).To(Equal(workflow.Finding{
+			RuleID:      "GHA001",
+			Severity:    workflow.SeverityError,
+			Description: "pull_request_target eller workflow_run sjekker ut koden fra PR-en",
+			Job:         "test",
+			Line:        6,
+		}))
+		Expect(findings[1].Line).To(Equal(11))
+		Expect(findings[2].Job).To(Equal("test"))
+		Expect(findings[3].Job).To(Equal("deploy"))
+		Expect(findings[3].Line).To(Equal(13))
+		Expect(findings[4].Line).To(Equal(3))
+	})
+
+	It("gir ingen funn for en ryddig workflow i et privat repo", func() {
+		wf, err := workflow.Parse(`on: pull_request
+permissions:
+  contents: read
+jobs:
+  test:
+    runs-on: self-hosted
+    steps:
+      - uses: actions/checkout@v4
+      - run: echo "${{ github.sha }}"
+        env:
+          TITLE: ${{ github.event.pull_request.title }}
+`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(workflow.Check(wf, workflow.Context{Owner: "navikt"})).To(BeEmpty())
+	})
+})